@@ -6,18 +6,55 @@ import { createBotNetServer } from "./src/http-server.js";
 import { initializeDatabase } from "./src/database.js";
 import { BotNetService } from "./src/service.js";
 import { TokenService } from "./src/auth/token-service.js";
+import { GossipService } from "./src/gossip/gossip-service.js";
+import type { FeatureFlagName } from "./src/feature-flags.js";
+import { CAPABILITY_PATTERN } from "./src/capabilities.js";
+import { LOCALES } from "./src/i18n.js";
 
 // Configuration schema
 const BotNetConfigSchema = z.object({
   botName: z.string().default("Khaar"),
   botDomain: z.string().default("botnet.airon.games"),
   botDescription: z.string().default("A Dragon BotNet node"),
-  capabilities: z.array(z.string()).default(["conversation", "collaboration", "federation"]),
+  sharePeerList: z.boolean().default(false), // Answer botnet.peers with our federated friends, so friends can discover them
+  requireSignedFederation: z.boolean().default(false), // Reject federation requests not signed with the sender's node key
+  operatorContact: z.string().optional(), // Operator contact (email or URL) published in botnet.profile for abuse reports
+  capabilities: z.array(z.string().regex(CAPABILITY_PATTERN, "Capabilities are name or name@major.minor (x- prefix for custom)")).default(["conversation", "collaboration", "federation"]),
   tier: z.enum(["bootstrap", "standard", "pro", "enterprise"]).default("standard"),
   databasePath: z.string().default("./data/botnet.db"),
   httpPort: z.number().default(8080),
+  httpHost: z.string().optional(), // Listen address, e.g. "0.0.0.0" (IPv4 only) or "::" (dual-stack); default is Node's dual-stack behavior
+  trustedProxies: z.array(z.string()).default([]), // Reverse proxy addresses whose X-Forwarded-For names the real client for per-IP stream limits
+  brandTitle: z.string().optional(), // Landing page title (default: "BotNet")
+  brandLogoUrl: z.string().url().optional(), // Landing page logo image (default: 🦞)
+  brandAccentColor: z.string().regex(/^#[0-9a-fA-F]{3,8}$/).optional(), // Landing page accent color, e.g. "#2563eb"
+  brandFooterLinks: z.array(z.object({ label: z.string(), url: z.string().url() })).default([]), // Extra landing page footer links
+  defaultLocale: z.enum(LOCALES).default("en"), // Landing page and HTTP error language when Accept-Language matches no supported locale
+  publishUsageStats: z.boolean().default(false), // Show coarse usage buckets (active peers, messages/day) on /status
+  publicFeedEnabled: z.boolean().default(false), // Serve our own gossip as an Atom feed at /feeds/node.atom
+  allowIndexing: z.boolean().default(true), // Let search engines index the landing page and docs (false adds noindex + Disallow: /)
+  robotsTxt: z.string().optional(), // Custom robots.txt body (overrides the generated one)
   logLevel: z.enum(["debug", "info", "warn", "error"]).default("info"),
   tokenCleanupIntervalMinutes: z.number().default(30), // Token cleanup frequency
+  friendRequestLimitPerDomain: z.number().default(3), // Incoming friend requests per domain per hour
+  friendRequestLimitPerIP: z.number().default(10), // Incoming friend requests per IP per hour
+  friendRequestProofOfWorkBits: z.number().min(0).max(20).default(0), // Required proof-of-work difficulty (0 = disabled)
+  anomalyRequestsPerMinute: z.number().default(120), // MCP requests per neighbor per minute before alerting (0 = disabled)
+  anomalyAuthFailuresPerMinute: z.number().default(20), // Auth failures per client IP per minute before alerting (0 = disabled)
+  anomalySignatureFailuresPerMinute: z.number().default(10), // Rejected node signatures per sender per minute before alerting (0 = disabled)
+  anomalyFlapsPerHour: z.number().default(6), // Times a peer may switch between reachable and unreachable per hour before alerting (0 = disabled)
+  alertWebhookUrl: z.string().url().optional(), // Receives anomaly alerts as JSON POSTs
+  requestLogSampleRate: z.number().min(0).max(1).default(1), // Fraction of successful requests logged (errors are always logged)
+  slowRequestThresholdMs: z.number().default(1000), // Requests slower than this are logged with a timing breakdown
+  featureFlags: z.record(z.boolean()).default({}), // Feature flag defaults, e.g. { "gossip_reference_fetch": false }
+  peerDailyBandwidthMB: z.number().default(0), // Per-peer daily federation transfer cap in MB (0 = unlimited)
+  integrityCheckIntervalHours: z.number().default(24), // How often to verify database integrity (0 = disabled)
+  auditRetentionDays: z.number().min(30).default(90), // Audit events older than this are rolled up into daily counts and deleted
+  clockSkewWarnSeconds: z.number().default(30), // Warn when our clock differs from federation peers by more than this
+  loadSheddingMaxHeapMB: z.number().default(0), // Shed low-priority work above this heap usage (0 = disabled)
+  loadSheddingMaxEventLoopDelayMs: z.number().default(500), // Shed low-priority work above this p99 event loop delay (0 = disabled)
+  errorSinkUrl: z.string().url().optional(), // Sentry DSN or generic webhook for handler/background errors
+  federationRecordPath: z.string().optional(), // Append scrubbed federation exchanges to this JSONL file (for replay-federation.mjs)
 });
 
 export type BotNetConfig = z.infer<typeof BotNetConfigSchema>;
@@ -36,6 +73,10 @@ const plugin = {
     let botnetService: BotNetService | null = null;
     let tokenService: TokenService | null = null;
     let cleanupInterval: NodeJS.Timeout | null = null;
+    let reputationInterval: NodeJS.Timeout | null = null;
+    let integrityInterval: NodeJS.Timeout | null = null;
+    let outboxInterval: NodeJS.Timeout | null = null;
+    let antiEntropyInterval: NodeJS.Timeout | null = null;
     
     const config = BotNetConfigSchema.parse(api.pluginConfig || {});
     
@@ -88,10 +129,56 @@ const plugin = {
               }
             } catch (error) {
               loggerAdapter.error("Token cleanup failed", { error });
+              botnetService?.getErrorReporter().report(error, { source: 'job:token-cleanup' });
             }
           }, config.tokenCleanupIntervalMinutes * 60 * 1000);
           console.log(`✅ Token cleanup scheduled every ${config.tokenCleanupIntervalMinutes} minutes`);
 
+          // Daily reputation maintenance (inactivity penalties, decay toward baseline)
+          reputationInterval = setInterval(() => {
+            try {
+              botnetService!.getReputationService().runMaintenance();
+              botnetService!.getBandwidthMeter().cleanup();
+              botnetService!.getAnomalyDetector().cleanup();
+              botnetService!.getAuditService().prune(config.auditRetentionDays);
+            } catch (error) {
+              loggerAdapter.error("Reputation maintenance failed", { error });
+              botnetService?.getErrorReporter().report(error, { source: 'job:reputation-maintenance' });
+            }
+          }, 24 * 60 * 60 * 1000);
+
+          // Verify database integrity periodically (corruption or audit-log tampering alerts the operator)
+          if (config.integrityCheckIntervalHours > 0) {
+            integrityInterval = setInterval(() => {
+              try {
+                botnetService!.verifyIntegrity();
+              } catch (error) {
+                loggerAdapter.error("Integrity check failed to run", { error });
+                botnetService?.getErrorReporter().report(error, { source: 'job:integrity-check' });
+              }
+            }, config.integrityCheckIntervalHours * 60 * 60 * 1000);
+          }
+
+          // Retry undelivered federation calls (the outbox applies its own per-entry backoff)
+          outboxInterval = setInterval(async () => {
+            try {
+              await botnetService!.processOutbox();
+            } catch (error) {
+              loggerAdapter.error("Federation outbox retry failed", { error });
+              botnetService?.getErrorReporter().report(error, { source: 'job:federation-outbox' });
+            }
+          }, 60 * 1000);
+
+          // Compare gossip digests with federated friends and backfill anything an exchange missed
+          antiEntropyInterval = setInterval(async () => {
+            try {
+              await botnetService!.runAntiEntropy();
+            } catch (error) {
+              loggerAdapter.error("Gossip anti-entropy round failed", { error });
+              botnetService?.getErrorReporter().report(error, { source: 'job:anti-entropy' });
+            }
+          }, 10 * 60 * 1000);
+
           // 🔐 SECURE: Register Internal Plugin API via Tools
           // These methods are only accessible to OpenClaw internally as tools, not via HTTP
           
@@ -199,6 +286,817 @@ const plugin = {
             }
           });
 
+          api.registerTool({
+            name: "botnet_revoke_sessions",
+            label: "BotNet Revoke Sessions",
+            description: "Revoke all session tokens held by a friend's node (e.g. after its tokens leaked), optionally also its permanent password, so it must authenticate again",
+            parameters: Type.Object({
+              friendDomain: Type.String({ description: "Domain whose sessions to revoke" }),
+              revokePassword: Type.Optional(Type.Boolean({ description: "Also revoke the friend's permanent password (default: false)" }))
+            }),
+            execute: async (toolCallId: string, params: { friendDomain: string; revokePassword?: boolean }, signal?: AbortSignal) => {
+              try {
+                const revoked = await tokenService!.revokeSessionTokensForDomain(params.friendDomain);
+                if (params.revokePassword) {
+                  await tokenService!.revokeFriendshipCredential(params.friendDomain, config.botDomain);
+                }
+                botnetService!.getAuditService().record('admin.action', {
+                  actor: 'local',
+                  target: params.friendDomain,
+                  details: { action: 'revoke_sessions', revoked, revokePassword: !!params.revokePassword }
+                });
+                return formatToolResult(
+                  `Revoked ${revoked} session token(s) for ${params.friendDomain}${params.revokePassword ? ' and its permanent password' : ''}`,
+                  { friendDomain: params.friendDomain, revoked, revokePassword: !!params.revokePassword }
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error revoking sessions: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 📜 Audit Log Tool
+          api.registerTool({
+            name: "botnet_audit_log",
+            label: "BotNet Audit Log",
+            description: "Query or export the security audit log (friendship changes, logins, auth failures, admin actions)",
+            parameters: Type.Object({
+              eventType: Type.Optional(Type.String({ description: "Event type, or prefix ending in '.' (e.g. 'friendship.')" })),
+              actor: Type.Optional(Type.String({ description: "Filter by actor (domain, IP or 'local')" })),
+              outcome: Type.Optional(Type.Union([Type.Literal("success"), Type.Literal("failure")], { description: "Filter by outcome" })),
+              since: Type.Optional(Type.String({ description: "ISO timestamp lower bound" })),
+              limit: Type.Optional(Type.Number({ description: "Maximum events to return (default: 50, max: 500)" })),
+              format: Type.Optional(Type.Union([Type.Literal("json"), Type.Literal("csv")], { description: "Export format (default: summary only)" }))
+            }),
+            execute: async (toolCallId: string, params: { eventType?: string; actor?: string; outcome?: 'success' | 'failure'; since?: string; limit?: number; format?: 'json' | 'csv' }, signal?: AbortSignal) => {
+              try {
+                const auditService = botnetService!.getAuditService();
+                const { format, ...filters } = params;
+                if (format) {
+                  return formatToolResult(auditService.export(filters, format), { format });
+                }
+                const events = auditService.query(filters);
+                const failures = events.filter(e => e.outcome === 'failure').length;
+                return formatToolResult(
+                  `Found ${events.length} audit events (${failures} failures).`,
+                  { events }
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error querying audit log: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // ⚖️ Reputation Tool
+          api.registerTool({
+            name: "botnet_get_reputation",
+            label: "BotNet Get Reputation",
+            description: "Show a friend's trust score and adjustment history, optionally applying an adjustment (e.g. for a validated abuse report)",
+            parameters: Type.Object({
+              friendDomain: Type.String({ description: "Friend domain to inspect" }),
+              adjustBy: Type.Optional(Type.Number({ description: "Score change to apply (negative to penalize)" })),
+              reason: Type.Optional(Type.Union([Type.Literal("abuse_report"), Type.Literal("manual")], { description: "Reason for the adjustment (default: manual)" })),
+              note: Type.Optional(Type.String({ description: "Free-form note stored with the adjustment" }))
+            }),
+            execute: async (toolCallId: string, params: { friendDomain: string; adjustBy?: number; reason?: 'abuse_report' | 'manual'; note?: string }, signal?: AbortSignal) => {
+              try {
+                const reputationService = botnetService!.getReputationService();
+                if (params.adjustBy) {
+                  reputationService.adjust(params.friendDomain, Math.round(params.adjustBy), params.reason || 'manual', params.note);
+                  botnetService!.getAuditService().record('admin.action', {
+                    actor: 'local',
+                    target: params.friendDomain,
+                    details: { action: 'adjust_reputation', adjustBy: params.adjustBy, reason: params.reason || 'manual' }
+                  });
+                }
+                const reputation = reputationService.getReputation(params.friendDomain);
+                if (!reputation) {
+                  return formatToolResult(
+                    `No friendship found for ${params.friendDomain}`,
+                    { error: 'Friendship not found' }
+                  );
+                }
+                return formatToolResult(
+                  `${params.friendDomain} trust score: ${reputation.score} (baseline ${reputation.baseline}, ${reputation.history.length} recorded changes)`,
+                  reputation
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error getting reputation: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 📶 Bandwidth Tool
+          api.registerTool({
+            name: "botnet_bandwidth",
+            label: "BotNet Bandwidth",
+            description: "Show bytes exchanged with federation peers per day, for one friend or totals for all peers",
+            parameters: Type.Object({
+              friendDomain: Type.Optional(Type.String({ description: "Friend domain to show daily usage for (default: all peers)" })),
+              days: Type.Optional(Type.Number({ description: "Number of days to include (default: 7)", minimum: 1, maximum: 30 }))
+            }),
+            execute: async (toolCallId: string, params: { friendDomain?: string; days?: number }, signal?: AbortSignal) => {
+              try {
+                const bandwidthMeter = botnetService!.getBandwidthMeter();
+                const usage = bandwidthMeter.getUsage(params.friendDomain, params.days || 7);
+                const capBytes = bandwidthMeter.getDailyCapBytes();
+                const totalBytes = usage.reduce((sum, row) => sum + row.bytes_in + row.bytes_out, 0);
+                return formatToolResult(
+                  `${params.friendDomain || 'All peers'}: ${(totalBytes / (1024 * 1024)).toFixed(2)} MB over ${params.days || 7} days` +
+                    (capBytes > 0 ? ` (daily cap ${Math.round(capBytes / (1024 * 1024))} MB per peer)` : ''),
+                  {
+                    usage,
+                    dailyCapBytes: capBytes,
+                    overCap: params.friendDomain ? bandwidthMeter.isOverCap(params.friendDomain) : undefined
+                  }
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error getting bandwidth usage: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 📮 Federation Outbox Tool
+          api.registerTool({
+            name: "botnet_outbox",
+            label: "BotNet Outbox",
+            description: "Show federation calls waiting to be retried after a friend's node was unreachable, or retry them now",
+            parameters: Type.Object({
+              action: Type.Optional(Type.Union([Type.Literal("list"), Type.Literal("retry")], { description: "list queued calls (default) or make them due immediately" })),
+              friendDomain: Type.Optional(Type.String({ description: "Only this friend's queue (default: all peers)" }))
+            }),
+            execute: async (toolCallId: string, params: { action?: 'list' | 'retry'; friendDomain?: string }, signal?: AbortSignal) => {
+              try {
+                const outbox = botnetService!.getFederationOutbox();
+                if (params.action === 'retry') {
+                  const scheduled = outbox.retryNow(params.friendDomain);
+                  const result = await botnetService!.processOutbox();
+                  return formatToolResult(
+                    `Retried ${result.attempted} of ${scheduled} queued call(s), ${result.delivered} delivered`,
+                    { scheduled, ...result, depth: outbox.getDepth() }
+                  );
+                }
+
+                const depth = outbox.getDepth();
+                const entries = outbox.list(params.friendDomain);
+                return formatToolResult(
+                  depth.total
+                    ? `${depth.total} queued call(s): ` + depth.byPeer.map(peer => `${peer.domain} (${peer.pending})`).join(', ')
+                    : 'Outbox is empty - all federation calls delivered',
+                  { depth, entries }
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error reading federation outbox: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 📊 Usage Analytics Tool
+          api.registerTool({
+            name: "botnet_usage_stats",
+            label: "BotNet Usage Stats",
+            description: "Show local aggregate usage: daily active peers, message volume, gossip volume and federation latency percentiles",
+            parameters: Type.Object({
+              days: Type.Optional(Type.Number({ description: "Number of days to include (default: 7)", minimum: 1, maximum: 30 }))
+            }),
+            execute: async (toolCallId: string, params: { days?: number }, signal?: AbortSignal) => {
+              try {
+                const summary = botnetService!.getUsageAnalytics().getSummary(params.days || 7);
+                const today = summary.days[0];
+                const latency = summary.federationLatencyMs
+                  ? `p50 ${summary.federationLatencyMs.p50}ms / p95 ${summary.federationLatencyMs.p95}ms`
+                  : 'no samples yet';
+                return formatToolResult(
+                  `Today: ${today.activePeers} active peers, ${today.messagesSent} sent / ${today.messagesReceived} received messages, ${summary.gossipLast24h} gossips in 24h. Federation latency: ${latency}`,
+                  summary
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error getting usage stats: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 🩺 Doctor Tool
+          api.registerTool({
+            name: "botnet_doctor",
+            label: "BotNet Doctor",
+            description: "Run self-diagnostics: DNS records, TLS certificate, public reachability of this node, storage health, clock skew and load",
+            parameters: Type.Object({}),
+            execute: async (toolCallId: string, params: {}, signal?: AbortSignal) => {
+              try {
+                const checks = await botnetService!.runDiagnostics();
+                const icons = { ok: '🟢', warn: '🟡', fail: '🔴', skip: '⚪' };
+                const failed = checks.filter(check => check.status === 'fail').length;
+                const warned = checks.filter(check => check.status === 'warn').length;
+                return formatToolResult(
+                  [
+                    ...checks.map(check => `${icons[check.status]} ${check.name}: ${check.detail}`),
+                    '',
+                    failed ? `${failed} check(s) failed` : warned ? `${warned} warning(s)` : 'All checks passed'
+                  ].join('\n'),
+                  { checks }
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error running diagnostics: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 🚩 Feature Flags Tool
+          api.registerTool({
+            name: "botnet_feature_flags",
+            label: "BotNet Feature Flags",
+            description: "List feature flags for experimental subsystems, or override one at runtime (persisted across restarts)",
+            parameters: Type.Object({
+              action: Type.Union([Type.Literal("list"), Type.Literal("enable"), Type.Literal("disable"), Type.Literal("reset")], { description: "list flags, enable/disable one, or reset it to the config default" }),
+              flag: Type.Optional(Type.String({ description: "Flag name (for enable/disable/reset)" }))
+            }),
+            execute: async (toolCallId: string, params: { action: 'list' | 'enable' | 'disable' | 'reset'; flag?: string }, signal?: AbortSignal) => {
+              try {
+                const featureFlags = botnetService!.getFeatureFlags();
+                if (params.action !== 'list') {
+                  if (!params.flag) {
+                    return formatToolResult(`flag is required for ${params.action}`, { error: 'Missing flag' });
+                  }
+                  const flag = params.flag as FeatureFlagName;
+                  const state = params.action === 'reset'
+                    ? featureFlags.clear(flag)
+                    : featureFlags.set(flag, params.action === 'enable');
+                  botnetService!.getAuditService().record('admin.action', {
+                    actor: 'local',
+                    target: flag,
+                    details: { action: `${params.action}_feature_flag` }
+                  });
+                  return formatToolResult(`${state.name}: ${state.enabled ? 'enabled' : 'disabled'} (${state.source})`, state);
+                }
+
+                const flags = featureFlags.list();
+                return formatToolResult(
+                  flags.map(flag => `${flag.enabled ? '✅' : '⛔'} ${flag.name} (${flag.source}) - ${flag.description}`).join('\n'),
+                  { flags }
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error updating feature flags: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 🔎 Trace Tool
+          api.registerTool({
+            name: "botnet_trace",
+            label: "BotNet Trace",
+            description: "Show how a direct message or gossip reached this node: origin, delivering peer, timing, status and verification results",
+            parameters: Type.Object({
+              messageId: Type.String({ description: "Message or gossip ID to trace" })
+            }),
+            execute: async (toolCallId: string, params: { messageId: string }, signal?: AbortSignal) => {
+              try {
+                const result = botnetService!.traceMessage(params.messageId);
+                if (!result) {
+                  return formatToolResult(`No message or gossip ${params.messageId} on this node`, { error: 'Not found' });
+                }
+
+                const { kind, trace } = result;
+                const lines = kind === 'message'
+                  ? [
+                      `Direct message ${trace.messageId} (${trace.direction})`,
+                      `${trace.from} → ${trace.to}, status ${trace.status}`,
+                      `Created ${trace.createdAt}, last updated ${trace.updatedAt}`,
+                      `${trace.responses.length} response(s)${trace.blocked ? `, peer is ${trace.blocked === 'block' ? 'blocked' : 'muted'}` : ''}${trace.knownContact ? '' : ', sender not a known contact'}`
+                    ]
+                  : [
+                      `Gossip ${trace.messageId} from ${trace.origin}${trace.local ? ' (ours)' : ''}`,
+                      `Arrived via ${trace.via}${trace.deliveredBy ? ` from ${trace.deliveredBy}` : ''} at ${trace.receivedAt}` +
+                        (trace.propagationDelayMs !== undefined ? ` (${Math.round(trace.propagationDelayMs / 1000)}s after creation)` : ''),
+                      `Confidence ${trace.confidence}%${trace.blocked ? `, source is ${trace.blocked === 'block' ? 'blocked' : 'muted'}` : ''}`,
+                      ...(trace.quote ? [`Quotes ${trace.quote.messageId}: ${trace.quote.verified === true ? 'hash verified' : trace.quote.verified === false ? 'hash MISMATCH' : 'original not available'}`] : [])
+                    ];
+                return formatToolResult(lines.join('\n'), result);
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error tracing message: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 🔏 Node Identity Backup Tool
+          api.registerTool({
+            name: "botnet_identity",
+            label: "BotNet Node Identity",
+            description: "Back up the node signing key (with pinned peer keys) to a passphrase-encrypted file, or restore it on a new host after checking it matches the key in this node's _botnet-key DNS TXT record",
+            parameters: Type.Object({
+              action: Type.Union([Type.Literal("backup"), Type.Literal("restore")], { description: "backup to a file or restore from one" }),
+              path: Type.String({ description: "Backup file path" }),
+              passphrase: Type.String({ description: "Passphrase the backup is encrypted with", minLength: 12 }),
+              force: Type.Optional(Type.Boolean({ description: "Restore even if the DNS-published key can't be checked or differs (default: false)" }))
+            }),
+            execute: async (toolCallId: string, params: { action: 'backup' | 'restore'; path: string; passphrase: string; force?: boolean }, signal?: AbortSignal) => {
+              try {
+                if (params.action === 'backup') {
+                  const result = botnetService!.backupNodeIdentity(params.path, params.passphrase);
+                  return formatToolResult(
+                    `Node identity backed up to ${result.path} - keep the file and passphrase somewhere safe. ` +
+                    `Publish TXT ${result.dnsRecord.name} "${result.dnsRecord.value}" so a restore can be verified`,
+                    result
+                  );
+                }
+
+                const result = await botnetService!.restoreNodeIdentity(params.path, params.passphrase, params.force);
+                return formatToolResult(
+                  result.verified
+                    ? `Node identity restored; key matches the one published in DNS for ${config.botDomain}`
+                    : `Node identity restored WITHOUT verification (forced) - friends will reject signatures until ${config.botDomain} publishes this key`,
+                  result
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error handling node identity: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 🎥 Agent Capture Tool
+          api.registerTool({
+            name: "botnet_agent_capture",
+            label: "BotNet Agent Capture",
+            description: "Capture every request one agent sends to this node (and our responses, secrets redacted) for a limited time, then export them as a HAR file to debug a misbehaving agent implementation. Only with the consent of that agent's operator",
+            parameters: Type.Object({
+              action: Type.Union([
+                Type.Literal("start"), Type.Literal("stop"), Type.Literal("status"), Type.Literal("export"), Type.Literal("clear")
+              ], { description: "start/stop a capture, show captures, export one as .har, or discard it" }),
+              agentId: Type.Optional(Type.String({ description: "Agent node domain to capture (required except for status)" })),
+              minutes: Type.Optional(Type.Number({ description: "Capture window in minutes (default: 15, max: 60)", minimum: 1, maximum: 60 })),
+              reason: Type.Optional(Type.String({ description: "Why this agent is being captured (required for start, recorded in the audit log)" })),
+              consent: Type.Optional(Type.Boolean({ description: "Confirm the agent's operator agreed to the capture (required for start)" })),
+              path: Type.Optional(Type.String({ description: "File to write for export (default: captures/ next to the database)" }))
+            }),
+            execute: async (toolCallId: string, params: { action: 'start' | 'stop' | 'status' | 'export' | 'clear'; agentId?: string; minutes?: number; reason?: string; consent?: boolean; path?: string }, signal?: AbortSignal) => {
+              try {
+                const capture = botnetService!.getAgentCapture();
+                if (params.action === 'status') {
+                  const captures = capture.getStatus();
+                  return formatToolResult(
+                    captures.length
+                      ? captures.map(status => `${status.agentId}: ${status.captured} request(s), ${status.active ? `until ${status.expiresAt}` : 'stopped'}`).join('\n')
+                      : 'No agent captures',
+                    { captures }
+                  );
+                }
+
+                if (!params.agentId) {
+                  return formatToolResult(`agentId is required for ${params.action}`, { error: 'Missing agentId' });
+                }
+
+                if (params.action === 'start') {
+                  if (!params.consent || !params.reason) {
+                    return formatToolResult(
+                      "Capturing an agent's traffic needs its operator's consent: pass consent: true and a reason",
+                      { error: 'Missing consent or reason' }
+                    );
+                  }
+                  const status = botnetService!.startAgentCapture(params.agentId, params.minutes || 15, params.reason);
+                  return formatToolResult(`Capturing requests from ${params.agentId} until ${status.expiresAt}`, { status });
+                }
+
+                if (params.action === 'stop') {
+                  const status = capture.stop(params.agentId);
+                  return status
+                    ? formatToolResult(`Stopped capture of ${params.agentId} (${status.captured} request(s) captured)`, { status })
+                    : formatToolResult(`No capture for ${params.agentId}`, { error: 'Capture not found' });
+                }
+
+                if (params.action === 'clear') {
+                  return capture.clear(params.agentId)
+                    ? formatToolResult(`Discarded capture of ${params.agentId}`, { agentId: params.agentId })
+                    : formatToolResult(`No capture for ${params.agentId}`, { error: 'Capture not found' });
+                }
+
+                const exported = botnetService!.exportAgentCapture(params.agentId, params.path);
+                return exported
+                  ? formatToolResult(`Wrote ${exported.entries} request(s) from ${params.agentId} to ${exported.path}`, exported)
+                  : formatToolResult(`No capture for ${params.agentId}`, { error: 'Capture not found' });
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error handling agent capture: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 🚩 Abuse Reports Tool
+          api.registerTool({
+            name: "botnet_abuse_reports",
+            label: "BotNet Abuse Reports",
+            description: "Review abuse reports sent to this node, resolve or dismiss them (optionally penalizing the reported friend's trust score), or look up a peer node's operator contact",
+            parameters: Type.Object({
+              action: Type.Union([
+                Type.Literal("list"),
+                Type.Literal("resolve"),
+                Type.Literal("dismiss"),
+                Type.Literal("contact")
+              ], { description: "list reports, resolve/dismiss one, or fetch a peer's operator contact" }),
+              reportId: Type.Optional(Type.String({ description: "Report ID (for resolve/dismiss)" })),
+              status: Type.Optional(Type.Union([Type.Literal("open"), Type.Literal("resolved"), Type.Literal("dismissed")], { description: "Filter for list (default: open)" })),
+              penalty: Type.Optional(Type.Number({ description: "Trust score points to remove from the reported friend when resolving" })),
+              note: Type.Optional(Type.String({ description: "Resolution note" })),
+              friendDomain: Type.Optional(Type.String({ description: "Peer domain (for contact)" }))
+            }),
+            execute: async (toolCallId: string, params: { action: 'list' | 'resolve' | 'dismiss' | 'contact'; reportId?: string; status?: 'open' | 'resolved' | 'dismissed'; penalty?: number; note?: string; friendDomain?: string }, signal?: AbortSignal) => {
+              try {
+                if (params.action === 'contact') {
+                  if (!params.friendDomain) {
+                    return formatToolResult("friendDomain is required for contact", { error: 'Missing friendDomain' });
+                  }
+                  const operator = await botnetService!.getPeerOperatorContact(params.friendDomain);
+                  return formatToolResult(
+                    operator?.contact
+                      ? `${params.friendDomain} operator: ${operator.contact}`
+                      : `${params.friendDomain} does not publish an operator contact`,
+                    { friendDomain: params.friendDomain, operator }
+                  );
+                }
+
+                if (params.action === 'list') {
+                  const reports = botnetService!.getAbuseReportService().list(params.status || 'open');
+                  return formatToolResult(
+                    reports.length
+                      ? reports.map(report => `[${report.id}] ${report.reported_domain}: ${report.reason}${report.reporter ? ` (from ${report.reporter})` : ''}`).join('\n')
+                      : `No ${params.status || 'open'} abuse reports`,
+                    { reports }
+                  );
+                }
+
+                if (!params.reportId) {
+                  return formatToolResult(`reportId is required for ${params.action}`, { error: 'Missing reportId' });
+                }
+                const status = params.action === 'resolve' ? 'resolved' : 'dismissed';
+                const report = botnetService!.closeAbuseReport(params.reportId, status, params.note, params.penalty);
+                if (!report) {
+                  return formatToolResult(`No open abuse report ${params.reportId}`, { error: 'Report not found' });
+                }
+                botnetService!.getAuditService().record('admin.action', {
+                  actor: 'local',
+                  target: report.reported_domain,
+                  details: { action: `${params.action}_abuse_report`, reportId: report.id, penalty: params.penalty }
+                });
+                return formatToolResult(`Abuse report ${report.id} ${status}`, { report });
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error handling abuse reports: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 📢 Channels Tool
+          api.registerTool({
+            name: "botnet_channels",
+            label: "BotNet Channels",
+            description: "Named public channels (#research, #trading): create channels on this node, join or leave channels hosted by other nodes, post, and read a channel's feed",
+            parameters: Type.Object({
+              action: Type.Union([
+                Type.Literal("list"), Type.Literal("create"), Type.Literal("delete"), Type.Literal("invite"),
+                Type.Literal("join"), Type.Literal("leave"), Type.Literal("post"), Type.Literal("feed")
+              ], { description: "Channel operation (list shows hosted and followed channels)" }),
+              channel: Type.Optional(Type.String({ description: "Channel name, e.g. research or #research (required except for list)" })),
+              host: Type.Optional(Type.String({ description: "Node hosting the channel (default: this node)" })),
+              description: Type.Optional(Type.String({ description: "Channel description (for create)" })),
+              joinPolicy: Type.Optional(Type.Union([Type.Literal("open"), Type.Literal("invite")], { description: "Who can join (for create, default: open)" })),
+              postPolicy: Type.Optional(Type.Union([Type.Literal("members"), Type.Literal("owner")], { description: "Who can post (for create, default: members)" })),
+              friendDomain: Type.Optional(Type.String({ description: "Node to add to an invite-only channel (for invite)" })),
+              content: Type.Optional(Type.String({ description: "Message text (for post)" })),
+              limit: Type.Optional(Type.Number({ description: "Messages to show (for feed, default: 20)", minimum: 1, maximum: 100 }))
+            }),
+            execute: async (toolCallId: string, params: {
+              action: 'list' | 'create' | 'delete' | 'invite' | 'join' | 'leave' | 'post' | 'feed';
+              channel?: string; host?: string; description?: string;
+              joinPolicy?: 'open' | 'invite'; postPolicy?: 'members' | 'owner';
+              friendDomain?: string; content?: string; limit?: number;
+            }, signal?: AbortSignal) => {
+              try {
+                const channels = botnetService!.getChannelService();
+                const host = params.host || config.botDomain;
+                const label = (channel: { host: string; name: string }) =>
+                  channel.host === config.botDomain ? `#${channel.name}` : `#${channel.name}@${channel.host}`;
+
+                if (params.action === 'list') {
+                  const hosted = channels.list(true);
+                  const followed = channels.list(false);
+                  return formatToolResult(
+                    hosted.length || followed.length
+                      ? [...hosted, ...followed].map(channel => `${label(channel)}${channel.description ? ` - ${channel.description}` : ''}` +
+                          (channel.host === config.botDomain ? ` (${channel.members} members, ${channel.join_policy})` : '')).join('\n')
+                      : 'No channels yet - create one or join a channel on another node',
+                    { hosted, followed }
+                  );
+                }
+
+                if (!params.channel) {
+                  return formatToolResult(`channel is required for ${params.action}`, { error: 'Missing channel' });
+                }
+
+                switch (params.action) {
+                  case 'create': {
+                    const channel = channels.create(params.channel, {
+                      description: params.description,
+                      joinPolicy: params.joinPolicy,
+                      postPolicy: params.postPolicy
+                    });
+                    return formatToolResult(`Created ${label(channel)} (${channel.join_policy}, ${channel.post_policy} can post)`, { channel });
+                  }
+                  case 'delete': {
+                    const deleted = host === config.botDomain
+                      ? channels.delete(host, params.channel)
+                      : await botnetService!.leaveRemoteChannel(host, params.channel);
+                    return formatToolResult(deleted ? `Deleted #${params.channel}` : `No such channel: #${params.channel}`, { deleted });
+                  }
+                  case 'invite': {
+                    if (!params.friendDomain) {
+                      return formatToolResult('friendDomain is required for invite', { error: 'Missing friendDomain' });
+                    }
+                    const channel = channels.addMember(params.channel, params.friendDomain, true);
+                    return formatToolResult(`Added ${params.friendDomain} to ${label(channel)} - their node can now join it`, { channel });
+                  }
+                  case 'join': {
+                    if (host === config.botDomain) {
+                      return formatToolResult('join is for channels on other nodes - set host', { error: 'Missing host' });
+                    }
+                    const channel = await botnetService!.joinRemoteChannel(host, params.channel);
+                    return formatToolResult(`Joined ${label(channel)}`, { channel });
+                  }
+                  case 'leave': {
+                    const left = await botnetService!.leaveRemoteChannel(host, params.channel);
+                    return formatToolResult(left ? `Left #${params.channel}@${host}` : `Not following #${params.channel}@${host}`, { left });
+                  }
+                  case 'post': {
+                    if (!params.content) {
+                      return formatToolResult('content is required for post', { error: 'Missing content' });
+                    }
+                    const message = await botnetService!.postToChannel(host, params.channel, params.content);
+                    return formatToolResult(`Posted to ${label({ host: message.host, name: message.channel })}`, { message });
+                  }
+                  default: {
+                    const messages = channels.getFeed(host, params.channel, params.limit || 20);
+                    return formatToolResult(
+                      messages.length
+                        ? messages.map(message => `[${message.created_at}] ${message.author}: ${message.content}`).join('\n')
+                        : `No messages in #${params.channel} yet`,
+                      { messages }
+                    );
+                  }
+                }
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error handling channels: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 🛡️ Channel Moderation Tool
+          api.registerTool({
+            name: "botnet_channel_moderation",
+            label: "BotNet Channel Moderation",
+            description: "Moderate a channel: pin, unpin or remove messages, mute or unmute members, appoint moderators, and read the channel's moderation log",
+            parameters: Type.Object({
+              action: Type.Union([
+                Type.Literal("pin"), Type.Literal("unpin"), Type.Literal("remove"), Type.Literal("mute"), Type.Literal("unmute"),
+                Type.Literal("promote"), Type.Literal("demote"), Type.Literal("members"), Type.Literal("log")
+              ], { description: "Moderator action; promote/demote appoint moderators in your own channels" }),
+              channel: Type.String({ description: "Channel name, e.g. research or #research" }),
+              host: Type.Optional(Type.String({ description: "Node hosting the channel (default: this node; you must be a moderator there)" })),
+              target: Type.Optional(Type.String({ description: "Message ID (pin/unpin/remove) or member domain (mute/unmute/promote/demote)" })),
+              reason: Type.Optional(Type.String({ description: "Reason, recorded in the moderation log" }))
+            }),
+            execute: async (toolCallId: string, params: {
+              action: 'pin' | 'unpin' | 'remove' | 'mute' | 'unmute' | 'promote' | 'demote' | 'members' | 'log';
+              channel: string; host?: string; target?: string; reason?: string;
+            }, signal?: AbortSignal) => {
+              try {
+                const channels = botnetService!.getChannelService();
+                const host = params.host || config.botDomain;
+
+                if (params.action === 'log') {
+                  const entries = channels.getModerationLog(host, params.channel);
+                  return formatToolResult(
+                    entries.length
+                      ? entries.map(entry => `[${entry.created_at}] ${entry.actor} ${entry.action} ${entry.target}${entry.reason ? ` - ${entry.reason}` : ''}`).join('\n')
+                      : `No moderation actions in #${params.channel}`,
+                    { entries }
+                  );
+                }
+                if (params.action === 'members') {
+                  const members = channels.listMembers(params.channel);
+                  return formatToolResult(
+                    members.length
+                      ? members.map(member => `${member.domain} (${member.role}${member.muted ? ', muted' : ''})`).join('\n')
+                      : `#${params.channel} has no members yet`,
+                    { members }
+                  );
+                }
+
+                if (!params.target) {
+                  return formatToolResult(`target is required for ${params.action}`, { error: 'Missing target' });
+                }
+                if (params.action === 'promote' || params.action === 'demote') {
+                  const updated = channels.setRole(params.channel, params.target, params.action === 'promote' ? 'moderator' : 'member');
+                  return formatToolResult(
+                    updated
+                      ? `${params.target} is now a ${params.action === 'promote' ? 'moderator' : 'member'} of #${params.channel}`
+                      : `${params.target} is not a member of #${params.channel}`,
+                    { updated }
+                  );
+                }
+
+                const entry = await botnetService!.moderateChannel(host, params.channel, params.action, params.target, params.reason);
+                return formatToolResult(`${entry.action} ${entry.target} in #${entry.channel} - pushed to member nodes`, { entry });
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error moderating channel: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 📋 Friend Lists Tool
+          api.registerTool({
+            name: "botnet_lists",
+            label: "BotNet Lists",
+            description: "Manage named lists of bots (local or federated) and read a merged timeline of their messages and gossip",
+            parameters: Type.Object({
+              action: Type.Union([
+                Type.Literal("show"), Type.Literal("create"), Type.Literal("delete"),
+                Type.Literal("add"), Type.Literal("remove"), Type.Literal("timeline")
+              ], { description: "List operation (show lists all lists)" }),
+              name: Type.Optional(Type.String({ description: "List name (required except for show)" })),
+              domain: Type.Optional(Type.String({ description: "Bot name or domain (for add/remove)" })),
+              description: Type.Optional(Type.String({ description: "List description (for create)" })),
+              limit: Type.Optional(Type.Number({ description: "Timeline entries to return (default: 50, max: 100)" }))
+            }),
+            execute: async (toolCallId: string, params: { action: 'show' | 'create' | 'delete' | 'add' | 'remove' | 'timeline'; name?: string; domain?: string; description?: string; limit?: number }, signal?: AbortSignal) => {
+              try {
+                const friendLists = botnetService!.getFriendListService();
+                if (params.action === 'show') {
+                  const lists = friendLists.getLists();
+                  return formatToolResult(`Found ${lists.length} lists.`, { lists });
+                }
+                if (!params.name) {
+                  throw new Error(`name is required to ${params.action} a list`);
+                }
+                if ((params.action === 'add' || params.action === 'remove') && !params.domain) {
+                  throw new Error(`domain is required to ${params.action} a list member`);
+                }
+
+                switch (params.action) {
+                  case 'create': {
+                    const list = friendLists.createList(params.name, params.description);
+                    return formatToolResult(`List "${list.name}" created.`, list);
+                  }
+                  case 'delete': {
+                    const deleted = friendLists.deleteList(params.name);
+                    return formatToolResult(deleted ? `List "${params.name}" deleted.` : `List "${params.name}" not found.`, { deleted });
+                  }
+                  case 'add': {
+                    const list = friendLists.addMember(params.name, params.domain!);
+                    return formatToolResult(`Added ${params.domain} to "${list.name}" (${list.members.length} members).`, list);
+                  }
+                  case 'remove': {
+                    const list = friendLists.removeMember(params.name, params.domain!);
+                    return formatToolResult(`Removed ${params.domain} from "${list.name}" (${list.members.length} members).`, list);
+                  }
+                  case 'timeline': {
+                    const timeline = friendLists.getTimeline(params.name, params.limit || 50);
+                    const combinedText = timeline
+                      .map(entry => `[${new Date(entry.created_at).toLocaleString()}] ${entry.source_domain} (${entry.kind}): ${entry.content}`)
+                      .join('\n\n');
+                    return formatToolResult(
+                      `Timeline for "${params.name}": ${timeline.length} entries.${combinedText ? `\n\n${combinedText}` : ''}`,
+                      { timeline }
+                    );
+                  }
+                }
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error handling list: ${errorMsg}`,
+                  { error: errorMsg, action: params.action }
+                );
+              }
+            }
+          });
+
+          // 🚫 Block & Mute Tool
+          api.registerTool({
+            name: "botnet_block_list",
+            label: "BotNet Block List",
+            description: "Block or mute bots and mute threads: blocked bots' messages and gossip are dropped, muted bots' gossip is hidden from feeds, and muted threads stop surfacing replies and responses",
+            parameters: Type.Object({
+              action: Type.Union([
+                Type.Literal("block"), Type.Literal("mute"), Type.Literal("unblock"),
+                Type.Literal("mute_thread"), Type.Literal("unmute_thread"), Type.Literal("list")
+              ], { description: "Operation (unblock also removes mutes)" }),
+              domain: Type.Optional(Type.String({ description: "Bot name or domain (block, mute, unblock)" })),
+              thread: Type.Optional(Type.String({ description: "Root gossip or direct message ID (mute_thread, unmute_thread)" })),
+              reason: Type.Optional(Type.String({ description: "Optional note on why" }))
+            }),
+            execute: async (toolCallId: string, params: { action: 'block' | 'mute' | 'unblock' | 'mute_thread' | 'unmute_thread' | 'list'; domain?: string; thread?: string; reason?: string }, signal?: AbortSignal) => {
+              try {
+                const blockList = botnetService!.getBlockListService();
+                if (params.action === 'list') {
+                  const entries = blockList.list();
+                  const threads = blockList.listThreadMutes();
+                  return formatToolResult(
+                    `${entries.filter(e => e.kind === 'block').length} blocked, ${entries.filter(e => e.kind === 'mute').length} muted, ${threads.length} thread(s) muted.`,
+                    { entries, threads }
+                  );
+                }
+                if (params.action === 'mute_thread' || params.action === 'unmute_thread') {
+                  if (!params.thread) {
+                    throw new Error(`thread is required to ${params.action}`);
+                  }
+                  if (params.action === 'unmute_thread') {
+                    const removed = blockList.unmuteThread(params.thread);
+                    return formatToolResult(
+                      removed ? `Thread ${params.thread} unmuted.` : `Thread ${params.thread} was not muted.`,
+                      { removed }
+                    );
+                  }
+                  const mute = blockList.muteThread(params.thread, params.reason);
+                  return formatToolResult(`Thread ${mute.thread_id} muted.`, mute);
+                }
+                if (!params.domain) {
+                  throw new Error(`domain is required to ${params.action}`);
+                }
+                if (params.action === 'unblock') {
+                  const removed = blockList.remove(params.domain);
+                  return formatToolResult(
+                    removed ? `${params.domain} unblocked.` : `${params.domain} was not blocked or muted.`,
+                    { removed }
+                  );
+                }
+                const entry = blockList.set(params.domain, params.action, params.reason);
+                botnetService!.getAuditService().record('admin.action', {
+                  actor: 'local',
+                  target: entry.domain,
+                  details: { action: params.action, reason: params.reason }
+                });
+                return formatToolResult(`${entry.domain} ${params.action === 'block' ? 'blocked' : 'muted'}.`, entry);
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error updating block list: ${errorMsg}`,
+                  { error: errorMsg, action: params.action }
+                );
+              }
+            }
+          });
+
           // 🤝 UPDATED Friendship Request Tool (Three-Tier Auth)
           api.registerTool({
             name: "botnet_send_friend_request",
@@ -307,6 +1205,144 @@ const plugin = {
             }
           });
 
+          // 📥 Message Requests Tool
+          api.registerTool({
+            name: "botnet_message_requests",
+            label: "BotNet Message Requests",
+            description: "Review first-contact messages from bots you've never interacted with, and accept or decline them",
+            parameters: Type.Object({
+              action: Type.Union([Type.Literal("list"), Type.Literal("accept"), Type.Literal("decline")], { description: "Operation" }),
+              fromDomain: Type.Optional(Type.String({ description: "Sender to accept or decline (required except for list)" })),
+              block: Type.Optional(Type.Boolean({ description: "Also block the sender when declining (default: false)" }))
+            }),
+            execute: async (toolCallId: string, params: { action: 'list' | 'accept' | 'decline'; fromDomain?: string; block?: boolean }, signal?: AbortSignal) => {
+              try {
+                if (params.action === 'list') {
+                  const requests = botnetService!.listMessageRequests();
+                  return formatToolResult(`${requests.length} senders waiting for acceptance.`, { requests });
+                }
+                if (!params.fromDomain) {
+                  throw new Error(`fromDomain is required to ${params.action} message requests`);
+                }
+                if (params.action === 'accept') {
+                  const result = botnetService!.acceptMessageRequests(params.fromDomain);
+                  return formatToolResult(`Accepted ${params.fromDomain}: ${result.delivered} messages moved to inbox.`, result);
+                }
+                const result = botnetService!.declineMessageRequests(params.fromDomain, params.block || false);
+                return formatToolResult(
+                  `Declined ${params.fromDomain}: ${result.deleted} messages discarded${result.blocked ? ', sender blocked' : ''}.`,
+                  result
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error handling message requests: ${errorMsg}`,
+                  { error: errorMsg, action: params.action }
+                );
+              }
+            }
+          });
+
+          // 📝 Drafts Tool
+          api.registerTool({
+            name: "botnet_drafts",
+            label: "BotNet Drafts",
+            description: "Save, list, delete or publish drafts of messages and gossip so long compositions survive restarts",
+            parameters: Type.Object({
+              action: Type.Union([Type.Literal("save"), Type.Literal("list"), Type.Literal("delete"), Type.Literal("publish")], { description: "Draft operation" }),
+              draftId: Type.Optional(Type.String({ description: "Draft ID (required for delete/publish, updates an existing draft on save)" })),
+              kind: Type.Optional(Type.Union([Type.Literal("message"), Type.Literal("gossip")], { description: "Draft type (default: message)" })),
+              targetBot: Type.Optional(Type.String({ description: "Target bot name or domain (message drafts)" })),
+              content: Type.Optional(Type.String({ description: "Draft content (required for save)" })),
+              category: Type.Optional(Type.String({ description: "Message type or gossip category (default: 'general')" }))
+            }),
+            execute: async (toolCallId: string, params: { action: 'save' | 'list' | 'delete' | 'publish'; draftId?: string; kind?: 'message' | 'gossip'; targetBot?: string; content?: string; category?: string }, signal?: AbortSignal) => {
+              try {
+                switch (params.action) {
+                  case 'save': {
+                    if (!params.content) {
+                      throw new Error('content is required to save a draft');
+                    }
+                    const draft = botnetService!.saveDraft({
+                      id: params.draftId,
+                      kind: params.kind || 'message',
+                      targetDomain: params.targetBot,
+                      content: params.content,
+                      category: params.category
+                    });
+                    return formatToolResult(`Draft ${draft.id} saved.`, draft);
+                  }
+                  case 'list': {
+                    const drafts = botnetService!.listDrafts(params.kind);
+                    return formatToolResult(`Found ${drafts.length} drafts.`, { drafts });
+                  }
+                  case 'delete': {
+                    if (!params.draftId) {
+                      throw new Error('draftId is required to delete a draft');
+                    }
+                    const deleted = botnetService!.deleteDraft(params.draftId);
+                    return formatToolResult(
+                      deleted ? `Draft ${params.draftId} deleted.` : `Draft ${params.draftId} not found.`,
+                      { deleted }
+                    );
+                  }
+                  case 'publish': {
+                    if (!params.draftId) {
+                      throw new Error('draftId is required to publish a draft');
+                    }
+                    const published = await botnetService!.publishDraft(params.draftId);
+                    return formatToolResult(`Draft ${params.draftId} published as ${published.draft.kind}.`, published);
+                  }
+                }
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error handling draft: ${errorMsg}`,
+                  { error: errorMsg, action: params.action }
+                );
+              }
+            }
+          });
+
+          // 🔖 Bookmarks Tool
+          api.registerTool({
+            name: "botnet_bookmarks",
+            label: "BotNet Bookmarks",
+            description: "Bookmark messages or gossip for later reference (stored locally, never shared)",
+            parameters: Type.Object({
+              action: Type.Union([Type.Literal("add"), Type.Literal("remove"), Type.Literal("list")], { description: "Bookmark operation" }),
+              messageId: Type.Optional(Type.String({ description: "Message or gossip ID (required for add/remove)" })),
+              note: Type.Optional(Type.String({ description: "Optional note stored with the bookmark" })),
+              kind: Type.Optional(Type.Union([Type.Literal("message"), Type.Literal("gossip")], { description: "Filter list by type" }))
+            }),
+            execute: async (toolCallId: string, params: { action: 'add' | 'remove' | 'list'; messageId?: string; note?: string; kind?: 'message' | 'gossip' }, signal?: AbortSignal) => {
+              try {
+                if (params.action === 'list') {
+                  const bookmarks = botnetService!.listBookmarks(params.kind);
+                  return formatToolResult(`Found ${bookmarks.length} bookmarks.`, { bookmarks });
+                }
+                if (!params.messageId) {
+                  throw new Error(`messageId is required to ${params.action} a bookmark`);
+                }
+                if (params.action === 'add') {
+                  const bookmark = botnetService!.addBookmark(params.messageId, params.note);
+                  return formatToolResult(`Bookmarked ${bookmark.kind} ${params.messageId}.`, bookmark);
+                }
+                const removed = botnetService!.removeBookmark(params.messageId);
+                return formatToolResult(
+                  removed ? `Bookmark ${params.messageId} removed.` : `No bookmark for ${params.messageId}.`,
+                  { removed }
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error handling bookmark: ${errorMsg}`,
+                  { error: errorMsg, action: params.action }
+                );
+              }
+            }
+          });
+
           // 📡 Updated Gossip Tools
           api.registerTool({
             name: "botnet_review_gossips",
@@ -333,21 +1369,90 @@ const plugin = {
             }
           });
 
+          api.registerTool({
+            name: "botnet_gossip_thread",
+            label: "BotNet Gossip Thread",
+            description: "Show the reply tree under a gossip: gossips quoting it, and gossips quoting those",
+            parameters: Type.Object({
+              messageId: Type.String({ description: "Gossip (or quoted message) ID at the top of the thread" }),
+              depth: Type.Optional(Type.Number({ description: "Reply levels to include (default: 3, max: 5)" })),
+              limit: Type.Optional(Type.Number({ description: "Replies per page and per nested level (default: 20, max: 50)" })),
+              cursor: Type.Optional(Type.Number({ description: "nextCursor from the previous page" }))
+            }),
+            execute: async (toolCallId: string, params: { messageId: string; depth?: number; limit?: number; cursor?: number }, signal?: AbortSignal) => {
+              try {
+                const thread = botnetService!.getGossipThread(params.messageId, params.depth, params.limit, params.cursor);
+                if (!thread) {
+                  return formatToolResult(`No gossip or message ${params.messageId} on this node`, { error: 'Not found' });
+                }
+
+                const lines: string[] = [`${thread.root.source || 'unknown'}: ${thread.root.content ?? '(original not available)'} [${thread.root.reply_count} replies]`];
+                const walk = (nodes: any[], indent: string) => {
+                  for (const node of nodes) {
+                    lines.push(`${indent}↳ ${node.source}: ${node.content}${node.truncated ? ` (+${node.reply_count - node.replies.length} more, thread ${node.message_id})` : ''}`);
+                    walk(node.replies, indent + '  ');
+                  }
+                };
+                walk(thread.replies, '  ');
+                if (thread.nextCursor !== undefined) {
+                  lines.push(`More replies: pass cursor ${thread.nextCursor}`);
+                }
+                return formatToolResult(lines.join('\n'), thread);
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error loading gossip thread: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          api.registerTool({
+            name: "botnet_react",
+            label: "BotNet React",
+            description: "React to a gossip with an emoji, or take a reaction back. Reactions are shared with your federated friends",
+            parameters: Type.Object({
+              messageId: Type.String({ description: "Gossip ID to react to" }),
+              reaction: Type.Union(GossipService.REACTIONS.map(reaction => Type.Literal(reaction)), { description: "Reaction emoji" }),
+              remove: Type.Optional(Type.Boolean({ description: "Take the reaction back instead (default: false)" }))
+            }),
+            execute: async (toolCallId: string, params: { messageId: string; reaction: string; remove?: boolean }, signal?: AbortSignal) => {
+              try {
+                const result = botnetService!.reactToGossip(params.messageId, params.reaction, params.remove === true);
+                const counts = Object.entries(result.reactions).map(([reaction, count]) => `${reaction} ${count}`).join('  ') || 'no reactions';
+                const summary = !result.changed
+                  ? `Nothing to change - you had ${params.remove ? 'not' : 'already'} reacted ${params.reaction}`
+                  : params.remove ? `Removed ${params.reaction}` : `Reacted ${params.reaction}`;
+                return formatToolResult(`${summary} on ${params.messageId} (${counts})`, result);
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error reacting to gossip: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
           api.registerTool({
             name: "botnet_share_gossip",
             label: "BotNet Share Gossip",
             description: "Share gossip with friends - category and tags support",
             parameters: Type.Object({
               content: Type.String({ description: "Gossip content to share" }),
-              category: Type.Optional(Type.String({ description: "Gossip category (default: 'general')" })),
-              tags: Type.Optional(Type.Array(Type.String(), { description: "Tags for the gossip" }))
+              category: Type.Optional(Type.String({ description: "Gossip category (default: 'general'; 'announcement' is pinned at the top of friends' feeds - use it for operator notices)" })),
+              tags: Type.Optional(Type.Array(Type.String(), { description: "Tags for the gossip" })),
+              quoteOf: Type.Optional(Type.String({ description: "ID of a gossip or message to quote (embedded with a content hash)" }))
             }),
-            execute: async (toolCallId: string, params: { content: string; category?: string; tags?: string[] }, signal?: AbortSignal) => {
+            execute: async (toolCallId: string, params: { content: string; category?: string; tags?: string[]; quoteOf?: string }, signal?: AbortSignal) => {
               try {
                 const result = await botnetService!.shareGossip(
                   params.content,
                   params.category || 'general',
-                  params.tags || []
+                  params.tags || [],
+                  undefined,
+                  params.quoteOf
                 );
                 
                 return formatToolResult(
@@ -444,6 +1549,68 @@ const plugin = {
             }
           });
 
+          // 🧭 Peer Discovery Tool
+          api.registerTool({
+            name: "botnet_discover_peers",
+            label: "BotNet Discover Peers",
+            description: "Ask federated friends which nodes they know (peer exchange) and suggest ones you're not connected to yet, ranked by how many friends know them",
+            parameters: Type.Object({
+              limit: Type.Optional(Type.Number({ description: "Maximum suggestions (default: 20)", minimum: 1, maximum: 100 }))
+            }),
+            execute: async (toolCallId: string, params: { limit?: number }, signal?: AbortSignal) => {
+              try {
+                const result = await botnetService!.discoverPeers(params.limit || 20);
+                return formatToolResult(
+                  result.suggestions.length
+                    ? result.suggestions.map(peer => `${peer.domain} (known by ${peer.knownBy.join(', ')})`).join('\n') +
+                        `\n\nSend a friend request with botnet_send_friend_request to connect.`
+                    : `No new peers found (${result.answered} of ${result.asked} friends share their peer list)`,
+                  result
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error discovering peers: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
+          // 👻 Shadow Friend Tool
+          api.registerTool({
+            name: "botnet_shadow_friend",
+            label: "BotNet Shadow Friend",
+            description: "Put an active friend in shadow (dry-run) mode: gossip exchanges with them are computed and recorded in the audit log, but nothing is sent to them or stored from them",
+            parameters: Type.Object({
+              friendDomain: Type.String({ description: "Active friend domain" }),
+              enabled: Type.Boolean({ description: "true to enable shadow mode, false to federate normally again" })
+            }),
+            execute: async (toolCallId: string, params: { friendDomain: string; enabled: boolean }, signal?: AbortSignal) => {
+              try {
+                const updated = botnetService!.setFriendShadow(params.friendDomain, params.enabled);
+                if (!updated) {
+                  return formatToolResult(
+                    `No active friendship found with ${params.friendDomain}`,
+                    { error: 'Friendship not found' }
+                  );
+                }
+                return formatToolResult(
+                  params.enabled
+                    ? `${params.friendDomain} is now a shadow peer - review what would have been exchanged with botnet_audit_log (eventType federation.shadow)`
+                    : `${params.friendDomain} federates normally again`,
+                  { friendDomain: params.friendDomain, shadow: params.enabled }
+                );
+              } catch (error) {
+                const errorMsg = error instanceof Error ? error.message : String(error);
+                return formatToolResult(
+                  `Error updating shadow mode: ${errorMsg}`,
+                  { error: errorMsg }
+                );
+              }
+            }
+          });
+
           // 💬 Review Messages Tool
           api.registerTool({
             name: "botnet_review_messages",
@@ -458,7 +1625,7 @@ const plugin = {
               try {
                 const result = await botnetService!.reviewMessages(params.fromDomain, true);
                 return formatToolResult(
-                  `Reviewed messages from ${params.fromDomain || 'all domains'}`,
+                  `Reviewed messages from ${params.fromDomain || 'all domains'}${result.pendingRequests ? ` (${result.pendingRequests} message requests waiting - see botnet_message_requests)` : ''}`,
                   result
                 );
               } catch (error) {
@@ -537,7 +1704,10 @@ const plugin = {
             }),
             execute: async (toolCallId: string, params: { includeDetailedStats?: boolean }, signal?: AbortSignal) => {
               try {
-                const health = await botnetService!.getHealthStatus();
+                const health: any = await botnetService!.getHealthStatus();
+                if (params.includeDetailedStats) {
+                  health.recentAlerts = botnetService!.getAnomalyDetector().getRecentAlerts();
+                }
                 const statusEmoji = health.status === 'healthy' ? '🟢' : health.status === 'warning' ? '🟡' : '🔴';
                 const summary = health.status === 'healthy' ? 'All systems operational' : 'System issues detected';
                 return formatToolResult(
@@ -563,8 +1733,8 @@ const plugin = {
           });
           
           // Start HTTP server
-          httpServer.listen(config.httpPort, () => {
-            loggerAdapter.info(`🐉 BotNet HTTP server started on port ${config.httpPort}`);
+          httpServer.listen(config.httpPort, config.httpHost, () => {
+            loggerAdapter.info(`🐉 BotNet HTTP server started on ${config.httpHost ? `${config.httpHost} ` : ''}port ${config.httpPort}`);
             loggerAdapter.info(`🔐 Three-tier authentication system active`);
             loggerAdapter.info(`🌐 Public API: http://localhost:${config.httpPort}/`);
             loggerAdapter.info(`🤖 MCP Endpoint: http://localhost:${config.httpPort}/mcp`);
@@ -584,6 +1754,25 @@ const plugin = {
           clearInterval(cleanupInterval);
           cleanupInterval = null;
         }
+        if (reputationInterval) {
+          clearInterval(reputationInterval);
+          reputationInterval = null;
+        }
+        if (integrityInterval) {
+          clearInterval(integrityInterval);
+          integrityInterval = null;
+        }
+        if (outboxInterval) {
+          clearInterval(outboxInterval);
+          outboxInterval = null;
+        }
+        if (antiEntropyInterval) {
+          clearInterval(antiEntropyInterval);
+          antiEntropyInterval = null;
+        }
+        botnetService?.getLoadMonitor().stop();
+        // Storage probe queries the database, so it stops before the database closes
+        botnetService?.getStorageMonitor().stop();
         
         // Close HTTP server
         if (httpServer) {
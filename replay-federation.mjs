@@ -0,0 +1,73 @@
+#!/usr/bin/env node
+
+// Replay recorded federation traffic against a node under test
+// Record with the `federationRecordPath` plugin option, then:
+//   node replay-federation.mjs recording.jsonl [--url http://localhost:8080/mcp] [--token <bearer>]
+// Compares each response's shape (error code, result keys) with the recording.
+
+import { readFileSync } from 'fs';
+
+const args = process.argv.slice(2);
+const file = args.find(arg => !arg.startsWith('--'));
+const option = (name, fallback) => {
+  const index = args.indexOf(`--${name}`);
+  return index >= 0 ? args[index + 1] : fallback;
+};
+
+if (!file) {
+  console.error('Usage: node replay-federation.mjs <recording.jsonl> [--url <mcp-url>] [--token <bearer>]');
+  process.exit(2);
+}
+
+const url = option('url', 'http://localhost:8080/mcp');
+const token = option('token');
+
+// Shape of a JSON-RPC response, ignoring values that differ run to run
+function shapeOf(response) {
+  if (!response) return 'no response';
+  if (response.error) return `error ${response.error.code}`;
+  return `result {${Object.keys(response.result || {}).sort().join(',')}}`;
+}
+
+const exchanges = readFileSync(file, 'utf8')
+  .split('\n')
+  .filter(line => line.trim())
+  .map(line => JSON.parse(line))
+  .filter(exchange => exchange.direction === 'inbound');
+
+console.log(`📼 Replaying ${exchanges.length} inbound exchanges against ${url}\n`);
+
+let matched = 0;
+let differed = 0;
+
+for (const exchange of exchanges) {
+  const method = exchange.request?.method || '(none)';
+  try {
+    const response = await fetch(url, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        ...(token ? { 'Authorization': `Bearer ${token}` } : {})
+      },
+      body: JSON.stringify(exchange.request)
+    });
+    const body = await response.json();
+
+    const expected = shapeOf(exchange.response);
+    const actual = shapeOf(body);
+    if (expected === actual && response.status === (exchange.statusCode || response.status)) {
+      matched++;
+      console.log(`✅ ${method}: ${actual}`);
+    } else {
+      differed++;
+      console.log(`❌ ${method}: expected ${expected} (HTTP ${exchange.statusCode}), got ${actual} (HTTP ${response.status})`);
+    }
+  } catch (error) {
+    differed++;
+    console.log(`🔥 ${method}: ${error.message}`);
+  }
+}
+
+console.log(`\n📊 ${matched} matched, ${differed} differed`);
+console.log('ℹ️  Credentials are scrubbed in recordings - pass --token to replay authenticated methods');
+process.exit(differed > 0 ? 1 : 0);
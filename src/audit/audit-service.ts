@@ -0,0 +1,255 @@
+// BotNet Audit Service
+// Append-only record of security-relevant actions (friendships, credentials, auth failures, admin tools)
+// Noisy events are throttled per actor, and events past the retention period are rolled up into daily counts
+
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+
+export type AuditEventType =
+  | 'friendship.request_received'
+  | 'friendship.accepted'
+  | 'friendship.removed'
+  | 'friendship.blocked'
+  | 'friendship.challenge_failed'
+  | 'session.login'
+  | 'session.login_failed'
+  | 'auth.failed'
+  | 'admin.action'
+  | 'anomaly.detected'
+  | 'abuse.reported'
+  | 'federation.shadow'
+  | 'integrity.check'
+  | 'channel.moderation';
+
+export interface AuditEvent {
+  id: number;
+  eventType: AuditEventType;
+  actor: string;
+  target?: string;
+  outcome: 'success' | 'failure';
+  details?: any;
+  createdAt: string;
+}
+
+export interface AuditQuery {
+  eventType?: string;
+  actor?: string;
+  target?: string;
+  outcome?: 'success' | 'failure';
+  since?: string;
+  until?: string;
+  limit?: number;
+}
+
+// Deletes of newer rows are refused by the audit_events_no_delete trigger
+export const AUDIT_RETENTION_MIN_DAYS = 30;
+
+export class AuditService {
+  private readonly MAX_QUERY_LIMIT = 500;
+
+  // Events anyone can trigger at will (unauthenticated requests) or that repeat every exchange
+  private readonly THROTTLED_EVENTS = new Set<AuditEventType>(['auth.failed', 'federation.shadow']);
+  private readonly THROTTLE_WINDOW_MS = 60 * 1000;
+  private readonly MAX_THROTTLE_KEYS = 10000;
+  private throttle = new Map<string, { until: number; suppressed: number }>();
+
+  constructor(
+    private database: Database.Database,
+    private logger: Logger
+  ) {}
+
+  /**
+   * Append an audit event - never throws, auditing must not break the audited action
+   */
+  record(
+    eventType: AuditEventType,
+    entry: { actor: string; target?: string; outcome?: 'success' | 'failure'; details?: any }
+  ): void {
+    let details = entry.details;
+    if (this.THROTTLED_EVENTS.has(eventType)) {
+      const suppressed = this.checkThrottle(`${eventType} ${entry.actor} ${entry.target || ''}`);
+      if (suppressed === null) {
+        return;
+      }
+      if (suppressed > 0) {
+        details = { ...details, suppressed };
+      }
+    }
+
+    try {
+      this.database.prepare(`
+        INSERT INTO audit_events (event_type, actor, target, outcome, details)
+        VALUES (?, ?, ?, ?, ?)
+      `).run(
+        eventType,
+        entry.actor,
+        entry.target || null,
+        entry.outcome || 'success',
+        details ? JSON.stringify(details) : null
+      );
+    } catch (error) {
+      this.logger.error("Failed to record audit event", { eventType, actor: entry.actor, error });
+    }
+  }
+
+  /**
+   * Roll events older than the retention period up into daily counts, then delete them
+   */
+  prune(retentionDays: number): { pruned: number } {
+    const days = Math.max(Math.floor(retentionDays), AUDIT_RETENTION_MIN_DAYS);
+    const cutoff = `-${days} days`;
+
+    const pruned = this.database.transaction(() => {
+      this.database.prepare(`
+        INSERT INTO audit_rollups (day, event_type, outcome, count)
+        SELECT date(created_at), event_type, outcome, COUNT(*)
+        FROM audit_events
+        WHERE created_at < datetime('now', ?)
+        GROUP BY date(created_at), event_type, outcome
+        ON CONFLICT(day, event_type, outcome) DO UPDATE SET count = count + excluded.count
+      `).run(cutoff);
+
+      return this.database.prepare(`
+        DELETE FROM audit_events WHERE created_at < datetime('now', ?)
+      `).run(cutoff).changes;
+    })();
+
+    if (pruned > 0) {
+      this.logger.info("Audit log pruned", { pruned, retentionDays: days });
+    }
+    return { pruned };
+  }
+
+  /**
+   * Daily event counts for pruned audit events (newest first)
+   */
+  getRollups(since?: string): Array<{ day: string; eventType: string; outcome: string; count: number }> {
+    const rows = this.database.prepare(`
+      SELECT * FROM audit_rollups
+      WHERE (? IS NULL OR day >= date(?))
+      ORDER BY day DESC, event_type
+    `).all(since || null, since || null) as any[];
+
+    return rows.map(row => ({ day: row.day, eventType: row.event_type, outcome: row.outcome, count: row.count }));
+  }
+
+  /**
+   * Query audit events with optional filters (newest first)
+   */
+  query(filters: AuditQuery = {}): AuditEvent[] {
+    let whereClause = '1=1';
+    const params: any[] = [];
+
+    if (filters.eventType) {
+      // Allow prefix filters like "friendship." to match a whole category
+      if (filters.eventType.endsWith('.')) {
+        whereClause += ' AND event_type LIKE ?';
+        params.push(`${filters.eventType}%`);
+      } else {
+        whereClause += ' AND event_type = ?';
+        params.push(filters.eventType);
+      }
+    }
+
+    if (filters.actor) {
+      whereClause += ' AND actor = ?';
+      params.push(filters.actor);
+    }
+
+    if (filters.target) {
+      whereClause += ' AND target = ?';
+      params.push(filters.target);
+    }
+
+    if (filters.outcome) {
+      whereClause += ' AND outcome = ?';
+      params.push(filters.outcome);
+    }
+
+    if (filters.since) {
+      whereClause += ' AND created_at >= datetime(?)';
+      params.push(filters.since);
+    }
+
+    if (filters.until) {
+      whereClause += ' AND created_at <= datetime(?)';
+      params.push(filters.until);
+    }
+
+    const limit = Math.min(filters.limit || 50, this.MAX_QUERY_LIMIT);
+
+    const rows = this.database.prepare(`
+      SELECT * FROM audit_events
+      WHERE ${whereClause}
+      ORDER BY id DESC
+      LIMIT ?
+    `).all(...params, limit) as any[];
+
+    return rows.map(row => ({
+      id: row.id,
+      eventType: row.event_type,
+      actor: row.actor,
+      target: row.target || undefined,
+      outcome: row.outcome,
+      details: row.details ? JSON.parse(row.details) : undefined,
+      createdAt: row.created_at
+    }));
+  }
+
+  /**
+   * Export matching audit events as JSON or CSV
+   */
+  export(filters: AuditQuery = {}, format: 'json' | 'csv' = 'json'): string {
+    const events = this.query({ ...filters, limit: filters.limit || this.MAX_QUERY_LIMIT });
+
+    if (format === 'json') {
+      return JSON.stringify(events, null, 2);
+    }
+
+    const escape = (value: any) => {
+      const text = value === undefined || value === null ? '' : String(value);
+      return /[",\n]/.test(text) ? `"${text.replace(/"/g, '""')}"` : text;
+    };
+
+    const header = 'id,created_at,event_type,actor,target,outcome,details';
+    const lines = events.map(event => [
+      event.id,
+      event.createdAt,
+      event.eventType,
+      event.actor,
+      event.target,
+      event.outcome,
+      event.details ? JSON.stringify(event.details) : ''
+    ].map(escape).join(','));
+
+    return [header, ...lines].join('\n');
+  }
+
+  /**
+   * One row per key per window: null if this event should be dropped, otherwise how many were dropped since the last row
+   */
+  private checkThrottle(key: string): number | null {
+    const now = Date.now();
+    const window = this.throttle.get(key);
+    if (window && window.until > now) {
+      window.suppressed++;
+      return null;
+    }
+
+    if (this.throttle.size >= this.MAX_THROTTLE_KEYS) {
+      for (const [existing, { until }] of this.throttle) {
+        if (until <= now) {
+          this.throttle.delete(existing);
+        }
+      }
+      if (this.throttle.size >= this.MAX_THROTTLE_KEYS) {
+        // Still full of live windows: drop the oldest rather than grow without bound
+        this.throttle.delete(this.throttle.keys().next().value!);
+      }
+    }
+
+    this.throttle.delete(key);
+    this.throttle.set(key, { until: now + this.THROTTLE_WINDOW_MS, suppressed: 0 });
+    return window ? window.suppressed : 0;
+  }
+}
@@ -20,7 +20,10 @@ export const methodAuthLevels: Record<string, AuthLevel> = {
   // ===== TIER 1: Public methods (no authentication) =====
   'botnet.health': AuthLevel.NONE,
   'botnet.profile': AuthLevel.NONE,
+  'botnet.capabilities': AuthLevel.NONE,
   'botnet.friendship.request': AuthLevel.NONE,
+  'botnet.abuse.report': AuthLevel.NONE,
+  'botnet.channel.list': AuthLevel.NONE,
 
   // ===== TIER 2: Negotiation phase methods (require negotiation token) =====
   'botnet.friendship.status': AuthLevel.NEGOTIATION,
@@ -31,7 +34,19 @@ export const methodAuthLevels: Record<string, AuthLevel> = {
   'botnet.message.send': AuthLevel.SESSION,
   'botnet.message.check': AuthLevel.SESSION,
   'botnet.gossip.exchange': AuthLevel.SESSION,
+  'botnet.gossip.fetch': AuthLevel.SESSION,
+  'botnet.gossip.digest': AuthLevel.SESSION,
+  'botnet.gossip.backfill': AuthLevel.SESSION,
+  'botnet.gossip.thread': AuthLevel.SESSION,
+  'botnet.gossip.react': AuthLevel.SESSION,
   'botnet.friendship.list': AuthLevel.SESSION,
+  'botnet.peers': AuthLevel.SESSION,
+  'botnet.channel.join': AuthLevel.SESSION,
+  'botnet.channel.leave': AuthLevel.SESSION,
+  'botnet.channel.post': AuthLevel.SESSION,
+  'botnet.channel.message': AuthLevel.SESSION,
+  'botnet.channel.moderate': AuthLevel.SESSION,
+  'botnet.channel.moderation': AuthLevel.SESSION,
 
   // ===== SPECIAL: Password-based authentication =====
   'botnet.login': AuthLevel.SPECIAL
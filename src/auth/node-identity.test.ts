@@ -0,0 +1,159 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { NodeIdentity } from './node-identity.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+// Header names arrive lowercased from node's http module
+const asReceived = (headers: Record<string, string>): Record<string, string> =>
+  Object.fromEntries(Object.entries(headers).map(([name, value]) => [name.toLowerCase(), value]));
+
+describe('NodeIdentity', () => {
+  let aliceDb: Database.Database;
+  let bobDb: Database.Database;
+  let alice: NodeIdentity;
+  let bob: NodeIdentity;
+  let fetchAliceKey: (domain: string) => Promise<string | undefined>;
+
+  beforeEach(async () => {
+    aliceDb = await initializeDatabase(':memory:', mockLogger);
+    bobDb = await initializeDatabase(':memory:', mockLogger);
+    alice = new NodeIdentity(aliceDb, mockLogger, 'botnet.alice.com');
+    bob = new NodeIdentity(bobDb, mockLogger, 'botnet.bob.com');
+    fetchAliceKey = jest.fn(async (domain: string) => domain === 'botnet.alice.com' ? alice.getPublicKey() : undefined);
+  });
+
+  it('keeps its key across restarts', () => {
+    expect(new NodeIdentity(aliceDb, mockLogger, 'botnet.alice.com').getPublicKey()).toBe(alice.getPublicKey());
+  });
+
+  it('verifies a request signed for this node', async () => {
+    const body = JSON.stringify({ jsonrpc: '2.0', method: 'botnet.peers', id: 1 });
+    const headers = asReceived(alice.signRequest(body, 'botnet.bob.com'));
+
+    expect(await bob.verifyRequest(headers, body, fetchAliceKey)).toEqual({ signed: true, valid: true, domain: 'botnet.alice.com' });
+  });
+
+  it('reports unsigned requests as unsigned', async () => {
+    const result = await bob.verifyRequest({}, '{}', fetchAliceKey);
+    expect(result.signed).toBe(false);
+    expect(result.valid).toBe(false);
+  });
+
+  it('rejects a tampered body', async () => {
+    const headers = asReceived(alice.signRequest('{"amount":1}', 'botnet.bob.com'));
+
+    const result = await bob.verifyRequest(headers, '{"amount":100}', fetchAliceKey);
+    expect(result.valid).toBe(false);
+    expect(result.error).toBe('Invalid signature');
+  });
+
+  it('rejects a request signed for another node', async () => {
+    const headers = asReceived(alice.signRequest('{}', 'botnet.carol.com'));
+
+    const result = await bob.verifyRequest(headers, '{}', fetchAliceKey);
+    expect(result.valid).toBe(false);
+    expect(result.error).toBe('Invalid signature');
+  });
+
+  it('rejects a replayed signature', async () => {
+    const headers = asReceived(alice.signRequest('{}', 'botnet.bob.com'));
+
+    expect((await bob.verifyRequest(headers, '{}', fetchAliceKey)).valid).toBe(true);
+    const replay = await bob.verifyRequest(headers, '{}', fetchAliceKey);
+    expect(replay.valid).toBe(false);
+    expect(replay.error).toBe('Replayed signature');
+  });
+
+  it('rejects a stale date', async () => {
+    const headers = asReceived(alice.signRequest('{}', 'botnet.bob.com'));
+    headers['x-botnet-date'] = new Date(Date.now() - 10 * 60 * 1000).toUTCString();
+
+    const result = await bob.verifyRequest(headers, '{}', fetchAliceKey);
+    expect(result.valid).toBe(false);
+    expect(result.error).toBe('Signature date missing or outside the allowed window');
+  });
+
+  it('fails when the sender publishes no key', async () => {
+    const headers = asReceived(alice.signRequest('{}', 'botnet.bob.com'));
+
+    const result = await bob.verifyRequest(headers, '{}', async () => undefined);
+    expect(result.valid).toBe(false);
+    expect(result.error).toBe('No node key published by botnet.alice.com');
+  });
+
+  it('shares one key lookup between concurrent requests and caches failures', async () => {
+    let release!: (key: string | undefined) => void;
+    const fetchSlowly = jest.fn(() => new Promise<string | undefined>(resolve => { release = resolve; }));
+    const first = asReceived(alice.signRequest('{}', 'botnet.bob.com'));
+    const second = asReceived(alice.signRequest('{}', 'botnet.bob.com'));
+
+    const results = Promise.all([bob.verifyRequest(first, '{}', fetchSlowly), bob.verifyRequest(second, '{}', fetchSlowly)]);
+    await new Promise(resolve => setImmediate(resolve));
+    release(undefined);
+
+    expect((await results).map(result => result.valid)).toEqual([false, false]);
+    expect(fetchSlowly).toHaveBeenCalledTimes(1);
+
+    await bob.verifyRequest(asReceived(alice.signRequest('{}', 'botnet.bob.com')), '{}', fetchSlowly);
+    expect(fetchSlowly).toHaveBeenCalledTimes(1);
+  });
+
+  it('accepts a pinned key without fetching the published one', async () => {
+    bobDb.prepare('INSERT INTO peer_key_pins (domain, public_key) VALUES (?, ?)').run('botnet.alice.com', alice.getPublicKey());
+    const fetchNothing = jest.fn(async () => undefined);
+    const headers = asReceived(alice.signRequest('{}', 'botnet.bob.com'));
+
+    expect((await bob.verifyRequest(headers, '{}', fetchNothing)).valid).toBe(true);
+    expect(fetchNothing).not.toHaveBeenCalled();
+  });
+
+  it('moves to a new host with its key and pins from a backup', async () => {
+    aliceDb.prepare('INSERT INTO peer_key_pins (domain, public_key) VALUES (?, ?)').run('botnet.bob.com', bob.getPublicKey());
+    const bundle = NodeIdentity.openBundle(alice.exportBundle('correct horse'), 'correct horse');
+    expect(() => NodeIdentity.openBundle(alice.exportBundle('correct horse'), 'wrong')).toThrow('Wrong passphrase');
+
+    const restored = new NodeIdentity(await initializeDatabase(':memory:', mockLogger), mockLogger, 'botnet.alice.com');
+    restored.restoreBundle(bundle);
+    expect(restored.getPublicKey()).toBe(alice.getPublicKey());
+    expect(restored.getKeyRecord()).toEqual({ name: '_botnet-key.botnet.alice.com', value: `v=botnet1; k=${alice.getPublicKey()}` });
+    expect(() => bob.restoreBundle(bundle)).toThrow('Bundle is for botnet.alice.com');
+  });
+
+  it('parses published key records', () => {
+    const key = alice.getPublicKey();
+    expect(NodeIdentity.parseKeyRecords([
+      [`v=botnet1; k=${key.slice(0, 20)}`, key.slice(20)],
+      ['v=spf1 -all']
+    ])).toEqual([key]);
+  });
+
+  it('refuses a pinned domain signing with another key, even one its profile serves', async () => {
+    bobDb.prepare('INSERT INTO peer_key_pins (domain, public_key) VALUES (?, ?)').run('botnet.alice.com', alice.getPublicKey());
+    const impostor = new NodeIdentity(await initializeDatabase(':memory:', mockLogger), mockLogger, 'botnet.alice.com');
+    const fetchImpostorKey = jest.fn(async () => impostor.getPublicKey());
+    const headers = asReceived(impostor.signRequest('{}', 'botnet.bob.com'));
+
+    const result = await bob.verifyRequest(headers, '{}', fetchImpostorKey);
+    expect(result.valid).toBe(false);
+    expect(result.error).toBe('Signature does not match the pinned key');
+    expect(fetchImpostorKey).not.toHaveBeenCalled();
+  });
+
+  it('backs up pinned keys only, not keys cached from profiles', async () => {
+    const carol = new NodeIdentity(await initializeDatabase(':memory:', mockLogger), mockLogger, 'botnet.carol.com');
+    bobDb.prepare('INSERT INTO peer_key_pins (domain, public_key) VALUES (?, ?)').run('botnet.carol.com', carol.getPublicKey());
+    expect((await bob.verifyRequest(asReceived(alice.signRequest('{}', 'botnet.bob.com')), '{}', fetchAliceKey)).valid).toBe(true);
+
+    const bundle = NodeIdentity.openBundle(bob.exportBundle('correct horse'), 'correct horse');
+    expect(bundle.pins).toEqual({ 'botnet.carol.com': carol.getPublicKey() });
+  });
+});
@@ -0,0 +1,334 @@
+// BotNet Node Identity
+// Ed25519 node key used to sign outbound federation requests and verify inbound ones
+
+import { createCipheriv, createDecipheriv, createHash, createPrivateKey, createPublicKey, generateKeyPairSync, randomBytes, scryptSync, sign, verify, type KeyObject } from "crypto";
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+
+// DNS TXT record where a node publishes its key out of band: _botnet-key.<domain> "v=botnet1; k=<base64 SPKI>"
+export const KEY_RECORD_PREFIX = '_botnet-key';
+
+export const SIGNATURE_HEADERS = {
+  node: 'x-botnet-node',
+  date: 'x-botnet-date',
+  nonce: 'x-botnet-nonce',
+  signature: 'x-botnet-signature'
+} as const;
+
+export interface SignatureVerification {
+  signed: boolean;
+  valid: boolean;
+  domain?: string;
+  error?: string;
+}
+
+// Everything needed to move a node to a new host: its key, who it is, and the peer keys it has pinned
+export interface IdentityBundle {
+  version: 1;
+  domain: string;
+  publicKey: string;
+  privateKey: string; // base64 PKCS8 DER
+  pins: Record<string, string>; // Peer domain → pinned node key
+  exportedAt: string;
+}
+
+export class NodeIdentity {
+  private privateKey: KeyObject;
+  private publicKey: string; // base64 SPKI DER, published in botnet.profile
+  private peerKeys: Map<string, { key: KeyObject; fetchedAt: number }> = new Map();
+  private readonly KEY_CACHE_TTL_MS = 60 * 60 * 1000;
+  private readonly MAX_DATE_SKEW_MS = 5 * 60 * 1000; // Replay window for signed requests
+  private readonly KEY_REFRESH_MIN_MS = 60 * 1000; // A bad signature re-fetches a cached key at most this often
+  private readonly FAILED_LOOKUP_TTL_MS = 5 * 60 * 1000; // Domains with no reachable key aren't asked again meanwhile
+  private readonly MAX_FAILED_LOOKUPS = 1000;
+  private readonly MAX_PENDING_LOOKUPS = 20; // Key fetches in flight at once; requests beyond this fail the lookup
+  private pendingLookups: Map<string, Promise<KeyObject | undefined>> = new Map(); // Concurrent requests share a fetch
+  private failedLookups: Map<string, number> = new Map(); // Domain → retry-after timestamp
+  private seenNonces: Map<string, number> = new Map(); // "domain nonce" → expiry, in insertion (and so expiry) order
+
+  constructor(
+    private database: Database.Database,
+    private logger: Logger,
+    private nodeDomain: string
+  ) {
+    const row = this.database.prepare(`
+      SELECT public_key, private_key FROM node_identity WHERE id = 1
+    `).get() as { public_key: string; private_key: string } | undefined;
+
+    if (row) {
+      this.privateKey = createPrivateKey({ key: Buffer.from(row.private_key, 'base64'), format: 'der', type: 'pkcs8' });
+      this.publicKey = row.public_key;
+    } else {
+      const pair = generateKeyPairSync('ed25519');
+      this.privateKey = pair.privateKey;
+      this.publicKey = pair.publicKey.export({ format: 'der', type: 'spki' }).toString('base64');
+      this.database.prepare(`
+        INSERT INTO node_identity (id, public_key, private_key) VALUES (1, ?, ?)
+      `).run(this.publicKey, pair.privateKey.export({ format: 'der', type: 'pkcs8' }).toString('base64'));
+      this.logger.info('🔏 Generated node signing key');
+    }
+  }
+
+  getPublicKey(): string {
+    return this.publicKey;
+  }
+
+  /**
+   * Node key, domain and pinned peer keys, encrypted with a passphrase (scrypt + AES-256-GCM)
+   */
+  exportBundle(passphrase: string): string {
+    const bundle: IdentityBundle = {
+      version: 1,
+      domain: this.nodeDomain,
+      publicKey: this.publicKey,
+      privateKey: this.privateKey.export({ format: 'der', type: 'pkcs8' }).toString('base64'),
+      pins: this.getPins(),
+      exportedAt: new Date().toISOString()
+    };
+
+    const salt = randomBytes(16);
+    const iv = randomBytes(12);
+    const cipher = createCipheriv('aes-256-gcm', scryptSync(passphrase, salt, 32), iv);
+    const ciphertext = Buffer.concat([cipher.update(JSON.stringify(bundle)), cipher.final()]);
+    return JSON.stringify({
+      format: 'botnet-identity',
+      version: 1,
+      kdf: 'scrypt',
+      salt: salt.toString('base64'),
+      iv: iv.toString('base64'),
+      tag: cipher.getAuthTag().toString('base64'),
+      ciphertext: ciphertext.toString('base64')
+    }, null, 2);
+  }
+
+  /**
+   * Decrypt a bundle written by exportBundle; throws on a wrong passphrase or a tampered file
+   */
+  static openBundle(contents: string, passphrase: string): IdentityBundle {
+    const sealed = JSON.parse(contents);
+    if (sealed?.format !== 'botnet-identity' || sealed.version !== 1) {
+      throw new Error('Not a BotNet identity bundle');
+    }
+    const decipher = createDecipheriv(
+      'aes-256-gcm',
+      scryptSync(passphrase, Buffer.from(sealed.salt, 'base64'), 32),
+      Buffer.from(sealed.iv, 'base64')
+    );
+    decipher.setAuthTag(Buffer.from(sealed.tag, 'base64'));
+    try {
+      const plaintext = Buffer.concat([decipher.update(Buffer.from(sealed.ciphertext, 'base64')), decipher.final()]);
+      return JSON.parse(plaintext.toString('utf8')) as IdentityBundle;
+    } catch {
+      throw new Error('Wrong passphrase or corrupted identity bundle');
+    }
+  }
+
+  /**
+   * Replace this node's key with a restored one and re-pin the bundled peer keys
+   */
+  restoreBundle(bundle: IdentityBundle): void {
+    if (bundle.domain !== this.nodeDomain) {
+      throw new Error(`Bundle is for ${bundle.domain}, this node is ${this.nodeDomain}`);
+    }
+    const privateKey = createPrivateKey({ key: Buffer.from(bundle.privateKey, 'base64'), format: 'der', type: 'pkcs8' });
+    // The public key is derived again, so a bundle can't pair someone else's public key with our private key
+    const publicKey = createPublicKey(privateKey).export({ format: 'der', type: 'spki' }).toString('base64');
+    if (publicKey !== bundle.publicKey) {
+      throw new Error('Bundle public key does not match its private key');
+    }
+
+    this.database.prepare(`
+      INSERT INTO node_identity (id, public_key, private_key) VALUES (1, ?, ?)
+      ON CONFLICT(id) DO UPDATE SET public_key = excluded.public_key, private_key = excluded.private_key
+    `).run(publicKey, bundle.privateKey);
+    this.privateKey = privateKey;
+    this.publicKey = publicKey;
+
+    // Pins live outside the key cache, so a refresh from the peer's profile can't replace them
+    const pin = this.database.prepare(`
+      INSERT INTO peer_key_pins (domain, public_key) VALUES (?, ?)
+      ON CONFLICT(domain) DO UPDATE SET public_key = excluded.public_key, pinned_at = CURRENT_TIMESTAMP
+    `);
+    for (const [domain, key] of Object.entries(bundle.pins || {})) {
+      createPublicKey({ key: Buffer.from(key, 'base64'), format: 'der', type: 'spki' });
+      pin.run(domain, key);
+    }
+    this.logger.warn('🔏 Restored node signing key from backup', { pins: Object.keys(bundle.pins || {}).length });
+  }
+
+  /**
+   * Headers to attach to an outbound federation request with this body, for the node at targetDomain
+   */
+  signRequest(body: string, targetDomain: string): Record<string, string> {
+    const date = new Date().toUTCString();
+    const nonce = randomBytes(16).toString('hex');
+    const signature = sign(null, Buffer.from(NodeIdentity.signingString(this.nodeDomain, targetDomain, date, nonce, body)), this.privateKey);
+    return {
+      'X-BotNet-Node': this.nodeDomain,
+      'X-BotNet-Date': date,
+      'X-BotNet-Nonce': nonce,
+      'X-BotNet-Signature': signature.toString('base64')
+    };
+  }
+
+  /**
+   * Check an inbound request's signature against the sender's published node key
+   */
+  async verifyRequest(
+    headers: Record<string, string | string[] | undefined>,
+    body: string,
+    fetchPublicKey: (domain: string) => Promise<string | undefined>
+  ): Promise<SignatureVerification> {
+    const domain = headers[SIGNATURE_HEADERS.node] as string | undefined;
+    const date = headers[SIGNATURE_HEADERS.date] as string | undefined;
+    const nonce = headers[SIGNATURE_HEADERS.nonce] as string | undefined;
+    const signature = headers[SIGNATURE_HEADERS.signature] as string | undefined;
+
+    if (!domain && !signature) {
+      return { signed: false, valid: false, error: 'Request is not signed' };
+    }
+    if (!domain || !date || !nonce || !signature) {
+      return { signed: true, valid: false, domain, error: 'Incomplete signature headers' };
+    }
+    const sentAt = Date.parse(date);
+    if (isNaN(sentAt) || Math.abs(Date.now() - sentAt) > this.MAX_DATE_SKEW_MS) {
+      return { signed: true, valid: false, domain, error: 'Signature date missing or outside the allowed window' };
+    }
+
+    // Signed for us specifically, so a request to another node can't be replayed here
+    const message = Buffer.from(NodeIdentity.signingString(domain, this.nodeDomain, date, nonce, body));
+    const signatureBytes = Buffer.from(signature, 'base64');
+
+    // A pinned domain is checked against its pin only, so whoever serves the peer's profile can't override it
+    const pinned = this.getPinnedKey(domain);
+    if (pinned) {
+      if (!verify(null, message, pinned, signatureBytes)) {
+        return { signed: true, valid: false, domain, error: 'Signature does not match the pinned key' };
+      }
+    } else {
+      let key = await this.getPeerKey(domain, fetchPublicKey, false);
+      if (key && !verify(null, message, key, signatureBytes)) {
+        // The peer may have rotated its key since we cached it
+        key = await this.getPeerKey(domain, fetchPublicKey, true);
+        if (key && !verify(null, message, key, signatureBytes)) {
+          return { signed: true, valid: false, domain, error: 'Invalid signature' };
+        }
+      }
+      if (!key) {
+        return { signed: true, valid: false, domain, error: `No node key published by ${domain}` };
+      }
+    }
+
+    // Each nonce is accepted once inside the date window; older ones fail the date check
+    const now = Date.now();
+    for (const [seen, expiresAt] of this.seenNonces) {
+      if (expiresAt > now) break;
+      this.seenNonces.delete(seen);
+    }
+    if (this.seenNonces.has(`${domain} ${nonce}`)) {
+      return { signed: true, valid: false, domain, error: 'Replayed signature' };
+    }
+    this.seenNonces.set(`${domain} ${nonce}`, now + 2 * this.MAX_DATE_SKEW_MS);
+
+    return { signed: true, valid: true, domain };
+  }
+
+  /**
+   * TXT record to publish so a restore on a new host can check its key against DNS
+   */
+  getKeyRecord(): { name: string; value: string } {
+    return { name: `${KEY_RECORD_PREFIX}.${this.nodeDomain}`, value: `v=botnet1; k=${this.publicKey}` };
+  }
+
+  /**
+   * Keys in a _botnet-key TXT lookup result (each record arrives as string chunks)
+   */
+  static parseKeyRecords(records: string[][]): string[] {
+    return records
+      .map(chunks => chunks.join(''))
+      .map(record => /^v=botnet1;\s*k=([A-Za-z0-9+/=]+)\s*$/.exec(record)?.[1])
+      .filter((key): key is string => !!key);
+  }
+
+  private getPins(): Record<string, string> {
+    const rows = this.database.prepare(`
+      SELECT domain, public_key FROM peer_key_pins
+    `).all() as Array<{ domain: string; public_key: string }>;
+    return Object.fromEntries(rows.map(row => [row.domain, row.public_key]));
+  }
+
+  private getPinnedKey(domain: string): KeyObject | undefined {
+    const row = this.database.prepare(`
+      SELECT public_key FROM peer_key_pins WHERE domain = ?
+    `).get(domain) as { public_key: string } | undefined;
+    return row ? createPublicKey({ key: Buffer.from(row.public_key, 'base64'), format: 'der', type: 'spki' }) : undefined;
+  }
+
+  private async getPeerKey(
+    domain: string,
+    fetchPublicKey: (domain: string) => Promise<string | undefined>,
+    refresh: boolean
+  ): Promise<KeyObject | undefined> {
+    const cached = this.peerKeys.get(domain);
+    const age = cached ? Date.now() - cached.fetchedAt : Infinity;
+    if (cached && (refresh ? age < this.KEY_REFRESH_MIN_MS : age < this.KEY_CACHE_TTL_MS)) {
+      return cached.key;
+    }
+    if ((this.failedLookups.get(domain) || 0) > Date.now()) {
+      return cached?.key;
+    }
+
+    const pending = this.pendingLookups.get(domain);
+    if (pending) {
+      return pending;
+    }
+    if (this.pendingLookups.size >= this.MAX_PENDING_LOOKUPS) {
+      return cached?.key;
+    }
+    const lookup = this.fetchPeerKey(domain, fetchPublicKey, cached?.key)
+      .finally(() => this.pendingLookups.delete(domain));
+    this.pendingLookups.set(domain, lookup);
+    return lookup;
+  }
+
+  private async fetchPeerKey(
+    domain: string,
+    fetchPublicKey: (domain: string) => Promise<string | undefined>,
+    cachedKey: KeyObject | undefined
+  ): Promise<KeyObject | undefined> {
+    try {
+      const published = await fetchPublicKey(domain);
+      if (!published) {
+        this.recordFailedLookup(domain);
+        return undefined;
+      }
+      const key = createPublicKey({ key: Buffer.from(published, 'base64'), format: 'der', type: 'spki' });
+      this.peerKeys.set(domain, { key, fetchedAt: Date.now() });
+      this.failedLookups.delete(domain);
+      return key;
+    } catch (error) {
+      this.logger.warn(`Failed to fetch node key for ${domain}`, {
+        error: error instanceof Error ? error.message : String(error)
+      });
+      this.recordFailedLookup(domain);
+      return cachedKey;
+    }
+  }
+
+  private recordFailedLookup(domain: string): void {
+    this.failedLookups.delete(domain);
+    this.failedLookups.set(domain, Date.now() + this.FAILED_LOOKUP_TTL_MS);
+    if (this.failedLookups.size > this.MAX_FAILED_LOOKUPS) {
+      this.failedLookups.delete(this.failedLookups.keys().next().value!);
+    }
+  }
+
+  /**
+   * Signed string: sender, receiving node, date, nonce and body hash, so a signature can't be replayed
+   * for another body, sender or receiver, or twice
+   */
+  private static signingString(domain: string, target: string, date: string, nonce: string, body: string): string {
+    const bodyHash = createHash('sha256').update(body).digest('hex');
+    return `${domain}\n${target}\n${date}\n${nonce}\n${bodyHash}`;
+  }
+}
@@ -0,0 +1,34 @@
+import { describe, it, expect } from '@jest/globals';
+import { ProofOfWork } from './proof-of-work.js';
+
+describe('ProofOfWork', () => {
+  const resource = 'botnet.alice.com:botnet.bob.com';
+
+  it('accepts anything when disabled', () => {
+    expect(new ProofOfWork(0).verify(resource).valid).toBe(true);
+  });
+
+  it('rejects a missing stamp', () => {
+    const result = new ProofOfWork(8).verify(resource);
+    expect(result.valid).toBe(false);
+    expect(result.error).toBe('Proof of work required (8 bits)');
+  });
+
+  it('accepts a solved stamp once', async () => {
+    const pow = new ProofOfWork(8);
+    const stamp = await ProofOfWork.solve(resource, 8);
+
+    expect(pow.verify(resource, stamp).valid).toBe(true);
+    expect(pow.verify(resource, stamp)).toEqual({ valid: false, error: 'Proof of work stamp already used' });
+  });
+
+  it('rejects expired stamps', async () => {
+    const stamp = await ProofOfWork.solve(resource, 4);
+    const result = new ProofOfWork(4).verify(resource, { ...stamp, timestamp: stamp.timestamp - 11 * 60 * 1000 });
+    expect(result).toEqual({ valid: false, error: 'Proof of work stamp expired' });
+  });
+
+  it('refuses to solve above the maximum difficulty', async () => {
+    await expect(ProofOfWork.solve(resource, ProofOfWork.MAX_DIFFICULTY + 1)).rejects.toThrow('exceeds the maximum');
+  });
+});
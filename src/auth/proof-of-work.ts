@@ -0,0 +1,119 @@
+// Hashcash-style proof-of-work pre-filter for public BotNet methods
+// Requesters must find a nonce whose SHA-256 stamp has N leading zero bits
+
+import { createHash, randomBytes } from "crypto";
+import { setImmediate as yieldToEventLoop } from "timers/promises";
+
+export interface ProofOfWorkStamp {
+  timestamp: number; // Unix epoch milliseconds when the stamp was minted
+  nonce: string;
+}
+
+export interface ProofOfWorkResult {
+  valid: boolean;
+  error?: string;
+}
+
+export class ProofOfWork {
+  // Highest difficulty we require or will solve for a remote node (~1M hashes on average)
+  static readonly MAX_DIFFICULTY = 20;
+  // Hashes per slice before yielding so solving doesn't stall other requests
+  private static readonly SOLVE_CHUNK = 10000;
+
+  private seenStamps: Map<string, number> = new Map();
+
+  constructor(
+    private difficulty: number,
+    private maxAgeMs: number = 10 * 60 * 1000 // Stamps are valid for 10 minutes
+  ) {}
+
+  /**
+   * Number of leading zero bits required (0 disables the check)
+   */
+  get requiredDifficulty(): number {
+    return this.difficulty;
+  }
+
+  /**
+   * Verify a stamp for a resource string (e.g. "fromDomain:toDomain")
+   */
+  verify(resource: string, stamp?: ProofOfWorkStamp): ProofOfWorkResult {
+    if (this.difficulty <= 0) {
+      return { valid: true };
+    }
+
+    if (!stamp || typeof stamp.timestamp !== 'number' || typeof stamp.nonce !== 'string') {
+      return { valid: false, error: `Proof of work required (${this.difficulty} bits)` };
+    }
+
+    const now = Date.now();
+    if (Math.abs(now - stamp.timestamp) > this.maxAgeMs) {
+      return { valid: false, error: "Proof of work stamp expired" };
+    }
+
+    const key = `${resource}:${stamp.timestamp}:${stamp.nonce}`;
+    this.cleanup(now);
+    if (this.seenStamps.has(key)) {
+      return { valid: false, error: "Proof of work stamp already used" };
+    }
+
+    if (ProofOfWork.leadingZeroBits(ProofOfWork.hash(key)) < this.difficulty) {
+      return { valid: false, error: "Proof of work does not meet required difficulty" };
+    }
+
+    this.seenStamps.set(key, stamp.timestamp);
+    return { valid: true };
+  }
+
+  /**
+   * Mint a stamp for a resource (used by MCPClient when a remote node requires it).
+   * Hashes in slices and yields between them, so the event loop keeps serving requests
+   */
+  static async solve(resource: string, difficulty: number): Promise<ProofOfWorkStamp> {
+    if (difficulty > ProofOfWork.MAX_DIFFICULTY) {
+      throw new Error(`Proof of work difficulty ${difficulty} exceeds the maximum of ${ProofOfWork.MAX_DIFFICULTY} bits`);
+    }
+
+    const timestamp = Date.now();
+    const prefix = randomBytes(4).toString('hex');
+
+    for (let counter = 0; ; counter++) {
+      const nonce = `${prefix}${counter.toString(36)}`;
+      const digest = ProofOfWork.hash(`${resource}:${timestamp}:${nonce}`);
+      if (ProofOfWork.leadingZeroBits(digest) >= difficulty) {
+        return { timestamp, nonce };
+      }
+      if (counter % ProofOfWork.SOLVE_CHUNK === ProofOfWork.SOLVE_CHUNK - 1) {
+        await yieldToEventLoop();
+      }
+    }
+  }
+
+  /**
+   * Drop stamps that are too old to be replayed anyway
+   */
+  private cleanup(now: number): void {
+    for (const [key, timestamp] of this.seenStamps.entries()) {
+      if (now - timestamp > this.maxAgeMs) {
+        this.seenStamps.delete(key);
+      }
+    }
+  }
+
+  private static hash(input: string): Buffer {
+    return createHash("sha256").update(input).digest();
+  }
+
+  private static leadingZeroBits(digest: Buffer): number {
+    let bits = 0;
+    for (const byte of digest) {
+      if (byte === 0) {
+        bits += 8;
+        continue;
+      }
+      bits += Math.clz32(byte) - 24;
+      break;
+    }
+    return bits;
+  }
+}
@@ -58,6 +58,7 @@ export class TokenService {
     selectByToken: any;
     updateActivity: any;
     revoke: any;
+    revokeByDomain: any;
     cleanupExpired: any;
   };
 
@@ -136,6 +137,10 @@ export class TokenService {
         DELETE FROM session_tokens
         WHERE token = ?
       `),
+      revokeByDomain: this.database.prepare(`
+        DELETE FROM session_tokens
+        WHERE from_domain = ?
+      `),
       cleanupExpired: this.database.prepare(`
         DELETE FROM session_tokens
         WHERE expires_at < datetime('now')
@@ -303,6 +308,26 @@ export class TokenService {
     }
   }
 
+  /**
+   * Active credential issued for fromDomain to log in to toDomain
+   */
+  getFriendshipCredential(fromDomain: string, toDomain: string): FriendshipCredential | null {
+    const result = this.credentialStmt.selectByDomains.get(fromDomain, toDomain) as any;
+    if (!result) {
+      return null;
+    }
+
+    return {
+      fromDomain: result.from_domain,
+      toDomain: result.to_domain,
+      permanentPassword: result.permanent_password,
+      status: result.status,
+      exchangeMethod: result.exchange_method,
+      lastUsedAt: result.last_used_at ? new Date(result.last_used_at) : undefined,
+      metadata: result.metadata ? JSON.parse(result.metadata) : undefined
+    };
+  }
+
   /**
    * Validate a permanent password and return friendship info
    */
@@ -409,8 +434,15 @@ export class TokenService {
       }
 
       // Auto-renew session on activity (extend expiry by 4 hours)
+      // Best effort - a read-only database shouldn't turn a valid session into an auth failure
       const newExpiresAt = new Date(Date.now() + 4 * 60 * 60 * 1000);
-      this.sessionStmt.updateActivity.run(newExpiresAt.toISOString(), token);
+      try {
+        this.sessionStmt.updateActivity.run(newExpiresAt.toISOString(), token);
+      } catch (renewError) {
+        this.logger.warn("Failed to renew session token", {
+          error: renewError instanceof Error ? renewError.message : String(renewError)
+        });
+      }
 
       const tokenData: SessionToken = {
         token: result.token,
@@ -460,6 +492,20 @@ export class TokenService {
     }
   }
 
+  /**
+   * Revoke every session a domain holds (e.g. its tokens were leaked); it has to log in again
+   */
+  async revokeSessionTokensForDomain(fromDomain: string): Promise<number> {
+    try {
+      const result = this.sessionStmt.revokeByDomain.run(fromDomain);
+      this.logger.info("Revoked session tokens for domain", { fromDomain, revoked: result.changes });
+      return result.changes;
+    } catch (error) {
+      this.logger.error("Failed to revoke session tokens for domain", { fromDomain, error });
+      throw new Error("Failed to revoke session tokens");
+    }
+  }
+
   // ===== CLEANUP OPERATIONS =====
 
   /**
@@ -0,0 +1,101 @@
+// BotNet capability registry
+// Versioned descriptors for the capabilities agents advertise in botnet.profile, and the protocol features a node supports
+// Config entries are "name" (current version) or "name@major.minor"; custom capabilities use an "x-" prefix
+
+import type { BotNetConfig } from "../index.js";
+import type { FeatureFlags } from "./feature-flags.js";
+
+export const AGENT_CAPABILITIES = {
+  conversation: {
+    version: "1.0",
+    description: "Holds free-text conversations over direct messages"
+  },
+  collaboration: {
+    version: "1.0",
+    description: "Works with other agents on shared tasks"
+  },
+  federation: {
+    version: "1.0",
+    description: "Befriends and exchanges gossip with agents on other nodes"
+  },
+  research: {
+    version: "1.0",
+    description: "Answers questions and shares findings as gossip"
+  },
+  moderation: {
+    version: "1.0",
+    description: "Moderates channels it owns or was appointed to"
+  }
+} as const;
+
+export type AgentCapabilityName = keyof typeof AGENT_CAPABILITIES;
+
+export interface CapabilityDescriptor {
+  id: string;
+  version: string;
+  description: string;
+  custom: boolean; // x- capabilities aren't in the registry, so they carry no agreed meaning
+}
+
+export interface NodeFeature {
+  id: string;
+  version: string;
+  enabled: boolean;
+}
+
+// "name" or "name@1.0"; lowercase so "Research" and "research" don't become two capabilities
+export const CAPABILITY_PATTERN = /^(x-)?[a-z][a-z0-9_-]*(@\d+\.\d+)?$/;
+
+/**
+ * Resolve configured capability strings against the registry; errors name entries that are malformed,
+ * unknown, or ask for a newer version than this node knows
+ */
+export function resolveCapabilities(entries: string[]): { capabilities: CapabilityDescriptor[]; errors: string[] } {
+  const capabilities: CapabilityDescriptor[] = [];
+  const errors: string[] = [];
+
+  for (const entry of entries) {
+    if (!CAPABILITY_PATTERN.test(entry)) {
+      errors.push(`"${entry}" is not a valid capability (expected name or name@major.minor)`);
+      continue;
+    }
+    const [id, requested] = entry.split('@');
+    if (id.startsWith('x-')) {
+      capabilities.push({ id, version: requested || '1.0', description: 'Custom capability', custom: true });
+      continue;
+    }
+
+    const known = AGENT_CAPABILITIES[id as AgentCapabilityName];
+    if (!known) {
+      errors.push(`Unknown capability "${id}" (prefix custom capabilities with x-)`);
+      continue;
+    }
+    if (requested && Number(requested.split('.')[0]) !== Number(known.version.split('.')[0])) {
+      errors.push(`Capability "${id}" version ${requested} is not supported (this node knows ${known.version})`);
+      continue;
+    }
+    capabilities.push({ id, version: known.version, description: known.description, custom: false });
+  }
+
+  return { capabilities, errors };
+}
+
+/**
+ * Protocol features this node supports, and whether its configuration has them switched on
+ */
+export function getNodeFeatures(config: BotNetConfig, flags: FeatureFlags): NodeFeature[] {
+  return [
+    { id: 'gossip.exchange', version: '1.0', enabled: true },
+    { id: 'gossip.loop_prevention', version: '1.0', enabled: true },
+    { id: 'gossip.anti_entropy', version: '1.0', enabled: flags.isEnabled('gossip_anti_entropy') },
+    { id: 'gossip.reactions', version: '1.0', enabled: true },
+    { id: 'channels', version: '1.0', enabled: true },
+    { id: 'channels.moderation', version: '1.0', enabled: true },
+    { id: 'federation.signatures', version: '1.0', enabled: true },
+    { id: 'federation.signatures.required', version: '1.0', enabled: config.requireSignedFederation },
+    { id: 'peers.exchange', version: '1.0', enabled: config.sharePeerList },
+    { id: 'feeds.atom', version: '1.0', enabled: config.publicFeedEnabled },
+    { id: 'feeds.stream', version: '1.0', enabled: true },
+    { id: 'abuse.reports', version: '1.0', enabled: true }
+  ];
+}
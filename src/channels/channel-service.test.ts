@@ -0,0 +1,148 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { ChannelService } from './channel-service.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('ChannelService', () => {
+  let db: Database.Database;
+  let channels: ChannelService;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    channels = new ChannelService(db, mockLogger, 'botnet.bob.com');
+  });
+
+  it('normalizes channel names and rejects anything else', () => {
+    expect(ChannelService.normalizeName(' #Research ')).toBe('research');
+    expect(() => ChannelService.normalizeName('has space')).toThrow('Channel names are 1-32 characters');
+    expect(() => ChannelService.normalizeName('#')).toThrow('Channel names are 1-32 characters');
+  });
+
+  it('creates local channels once', () => {
+    const channel = channels.create('#Research', { description: 'Papers' });
+    expect(channel).toEqual(expect.objectContaining({ host: 'botnet.bob.com', name: 'research', join_policy: 'open', post_policy: 'members', members: 0 }));
+    expect(() => channels.create('research')).toThrow('Channel already exists: #research');
+    expect(channels.list().map(c => c.name)).toEqual(['research']);
+  });
+
+  it('lets peers join open channels but not invite-only ones', () => {
+    channels.create('open');
+    channels.create('private', { joinPolicy: 'invite' });
+
+    expect(channels.addMember('open', 'botnet.alice.com').members).toBe(1);
+    expect(() => channels.addMember('private', 'botnet.alice.com')).toThrow('#private is invite-only');
+    expect(channels.addMember('private', 'botnet.alice.com', true).members).toBe(1);
+  });
+
+  it('enforces the post policy', () => {
+    channels.create('general');
+    channels.create('news', { postPolicy: 'owner' });
+    channels.addMember('news', 'botnet.alice.com');
+
+    expect(() => channels.post('general', 'botnet.alice.com', 'Hi')).toThrow('Join #general before posting');
+    channels.addMember('general', 'botnet.alice.com');
+    expect(channels.post('general', 'botnet.alice.com', '  Hi  ').content).toBe('Hi');
+    expect(() => channels.post('news', 'botnet.alice.com', 'Hi')).toThrow('Only botnet.bob.com can post in #news');
+    expect(channels.post('news', 'botnet.bob.com', 'Release notes').author).toBe('botnet.bob.com');
+    expect(() => channels.post('general', 'botnet.bob.com', '   ')).toThrow('Channel message cannot be empty');
+  });
+
+  it('pushes only to federated members, excluding the sender', () => {
+    channels.create('general');
+    channels.addMember('general', 'botnet.alice.com');
+    channels.addMember('general', 'botnet.carol.com');
+    channels.addMember('general', 'LocalBot');
+
+    expect(channels.getSubscribers('general', 'botnet.carol.com')).toEqual(['botnet.alice.com']);
+  });
+
+  it('accepts pushed messages only for followed channels, once each', () => {
+    const pushed = { id: 'chan_1', channel: 'lobby', author: 'botnet.alice.com', content: 'Welcome' };
+    expect(channels.receive('botnet.alice.com', pushed)).toBeNull();
+
+    channels.follow('botnet.alice.com', { name: 'lobby' });
+    expect(channels.receive('botnet.alice.com', pushed)?.content).toBe('Welcome');
+    expect(channels.receive('botnet.alice.com', pushed)).toBeNull();
+    expect(channels.getFeed('botnet.alice.com', 'lobby').map(message => message.id)).toEqual(['chan_1']);
+    expect(channels.list(false).map(c => `${c.host}#${c.name}`)).toEqual(['botnet.alice.com#lobby']);
+  });
+
+  it('deletes a channel with its members and messages', () => {
+    channels.create('general');
+    channels.addMember('general', 'botnet.alice.com');
+    channels.post('general', 'botnet.alice.com', 'Hi');
+
+    expect(channels.delete('botnet.bob.com', 'general')).toBe(true);
+    expect(db.prepare('SELECT COUNT(*) FROM channel_members').pluck().get()).toBe(0);
+    expect(db.prepare('SELECT COUNT(*) FROM channel_messages').pluck().get()).toBe(0);
+    expect(channels.delete('botnet.bob.com', 'general')).toBe(false);
+  });
+});
+
+describe('ChannelService moderation', () => {
+  let db: Database.Database;
+  let channels: ChannelService;
+  let messageId: string;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    channels = new ChannelService(db, mockLogger, 'botnet.bob.com');
+    channels.create('general');
+    channels.addMember('general', 'botnet.alice.com');
+    channels.addMember('general', 'botnet.carol.com');
+    messageId = channels.post('general', 'botnet.carol.com', 'Buy now!').id;
+  });
+
+  it('lets the owner and moderators moderate, nobody else', () => {
+    expect(() => channels.moderate('general', 'botnet.alice.com', 'remove', messageId)).toThrow('Only moderators can remove in #general');
+
+    channels.setRole('general', 'botnet.alice.com', 'moderator');
+    expect(channels.moderate('general', 'botnet.alice.com', 'pin', messageId).action).toBe('pin');
+    expect(channels.getFeed('botnet.bob.com', 'general')[0].pinned).toBe(true);
+    expect(channels.moderate('general', 'botnet.bob.com', 'remove', messageId, 'spam').reason).toBe('spam');
+    expect(channels.getFeed('botnet.bob.com', 'general')).toEqual([]);
+  });
+
+  it('mutes members but never moderators', () => {
+    channels.moderate('general', 'botnet.bob.com', 'mute', 'botnet.carol.com');
+    expect(() => channels.post('general', 'botnet.carol.com', 'Again')).toThrow('You are muted in #general');
+
+    channels.moderate('general', 'botnet.bob.com', 'unmute', 'botnet.carol.com');
+    expect(channels.post('general', 'botnet.carol.com', 'Sorry').content).toBe('Sorry');
+
+    channels.setRole('general', 'botnet.alice.com', 'moderator');
+    expect(() => channels.moderate('general', 'botnet.bob.com', 'mute', 'botnet.alice.com')).toThrow("moderators can't be muted");
+  });
+
+  it('refuses unknown actions and missing targets', () => {
+    expect(() => channels.moderate('general', 'botnet.bob.com', 'ban' as any, 'botnet.carol.com')).toThrow("Unknown moderation action 'ban'");
+    expect(() => channels.moderate('general', 'botnet.bob.com', 'pin', 'chan_missing')).toThrow('No such message in #general: chan_missing');
+  });
+
+  it('logs every action, newest first', () => {
+    channels.moderate('general', 'botnet.bob.com', 'pin', messageId);
+    channels.moderate('general', 'botnet.bob.com', 'unpin', messageId);
+
+    expect(channels.getModerationLog('botnet.bob.com', 'general').map(entry => entry.action)).toEqual(['unpin', 'pin']);
+  });
+
+  it("mirrors the host's actions in followed channels only", () => {
+    const entry = { channel: 'lobby', actor: 'botnet.alice.com', action: 'remove' as const, target: 'chan_1' };
+    expect(channels.receiveModeration('botnet.alice.com', entry)).toBeNull();
+
+    channels.follow('botnet.alice.com', { name: 'lobby' });
+    channels.receive('botnet.alice.com', { id: 'chan_1', channel: 'lobby', author: 'botnet.dave.com', content: 'Spam' });
+    expect(channels.receiveModeration('botnet.alice.com', entry)?.action).toBe('remove');
+    expect(channels.getFeed('botnet.alice.com', 'lobby')).toEqual([]);
+    expect(channels.receiveModeration('botnet.bob.com', { ...entry, channel: 'general' })).toBeNull();
+  });
+});
@@ -0,0 +1,410 @@
+// BotNet Channels
+// Named public channels hosted by a node (#research, #trading), with membership rules and their own feed
+
+import { randomBytes } from "crypto";
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+
+export type ChannelJoinPolicy = 'open' | 'invite';
+export type ChannelPostPolicy = 'members' | 'owner';
+export type ChannelRole = 'owner' | 'moderator' | 'member';
+export type ModerationAction = 'pin' | 'unpin' | 'remove' | 'mute' | 'unmute';
+
+export const MODERATION_ACTIONS: ModerationAction[] = ['pin', 'unpin', 'remove', 'mute', 'unmute'];
+
+export interface Channel {
+  host: string; // Node hosting the channel (our domain for local channels)
+  name: string;
+  description?: string;
+  join_policy: ChannelJoinPolicy;
+  post_policy: ChannelPostPolicy;
+  members: number;
+  created_at: string;
+}
+
+export interface ChannelMessage {
+  id: string;
+  host: string;
+  channel: string;
+  author: string;
+  content: string;
+  pinned: boolean;
+  created_at: string;
+}
+
+export interface ModerationEntry {
+  id: number;
+  host: string;
+  channel: string;
+  actor: string;
+  action: ModerationAction;
+  target: string;
+  reason?: string;
+  created_at: string;
+}
+
+export class ChannelService {
+  private readonly MAX_CHANNELS = 50;
+  private readonly MAX_MEMBERS = 500;
+  private readonly MAX_CONTENT_LENGTH = 2000;
+  private static readonly NAME_PATTERN = /^[a-z0-9][a-z0-9_-]{0,31}$/;
+
+  constructor(
+    private database: Database.Database,
+    private logger: Logger,
+    private nodeDomain: string
+  ) {}
+
+  /**
+   * Normalize "#Research" to "research" and reject anything that isn't a plain slug
+   */
+  static normalizeName(name: string): string {
+    const normalized = String(name || '').trim().replace(/^#/, '').toLowerCase();
+    if (!ChannelService.NAME_PATTERN.test(normalized)) {
+      throw new Error('Channel names are 1-32 characters: lowercase letters, digits, - and _');
+    }
+    return normalized;
+  }
+
+  /**
+   * Create a channel hosted by this node
+   */
+  create(name: string, options: { description?: string; joinPolicy?: ChannelJoinPolicy; postPolicy?: ChannelPostPolicy } = {}): Channel {
+    const channel = ChannelService.normalizeName(name);
+    const count = this.database.prepare(`
+      SELECT COUNT(*) AS count FROM channels WHERE host = ?
+    `).get(this.nodeDomain) as { count: number };
+    if (count.count >= this.MAX_CHANNELS) {
+      throw new Error(`Channel limit reached (${this.MAX_CHANNELS}). Delete an existing channel first.`);
+    }
+    if (this.get(this.nodeDomain, channel)) {
+      throw new Error(`Channel already exists: #${channel}`);
+    }
+
+    this.database.prepare(`
+      INSERT INTO channels (host, name, description, join_policy, post_policy) VALUES (?, ?, ?, ?, ?)
+    `).run(this.nodeDomain, channel, options.description || null, options.joinPolicy || 'open', options.postPolicy || 'members');
+
+    this.logger.info('📢 Channel created', { channel, joinPolicy: options.joinPolicy || 'open' });
+    return this.get(this.nodeDomain, channel)!;
+  }
+
+  /**
+   * Delete a local channel, or drop a remote channel we follow, with its members and messages
+   */
+  delete(host: string, name: string): boolean {
+    const channel = ChannelService.normalizeName(name);
+    if (!this.get(host, channel)) {
+      return false;
+    }
+
+    this.database.transaction(() => {
+      this.database.prepare(`DELETE FROM channel_messages WHERE host = ? AND channel = ?`).run(host, channel);
+      this.database.prepare(`DELETE FROM channel_members WHERE host = ? AND channel = ?`).run(host, channel);
+      this.database.prepare(`DELETE FROM channel_moderation_log WHERE host = ? AND channel = ?`).run(host, channel);
+      this.database.prepare(`DELETE FROM channels WHERE host = ? AND name = ?`).run(host, channel);
+    })();
+    return true;
+  }
+
+  get(host: string, name: string): Channel | null {
+    const row = this.database.prepare(`
+      SELECT c.*, (SELECT COUNT(*) FROM channel_members m WHERE m.host = c.host AND m.channel = c.name) AS members
+      FROM channels c
+      WHERE c.host = ? AND c.name = ?
+    `).get(host, name) as any;
+    return row ? this.mapChannel(row) : null;
+  }
+
+  /**
+   * Channels we host (local = true) or remote channels we follow
+   */
+  list(local: boolean = true): Channel[] {
+    const rows = this.database.prepare(`
+      SELECT c.*, (SELECT COUNT(*) FROM channel_members m WHERE m.host = c.host AND m.channel = c.name) AS members
+      FROM channels c
+      WHERE (c.host = ?) = ?
+      ORDER BY c.host, c.name
+    `).all(this.nodeDomain, local ? 1 : 0) as any[];
+    return rows.map(row => this.mapChannel(row));
+  }
+
+  /**
+   * Add a member to a local channel; peers can only join open channels themselves
+   */
+  addMember(name: string, domain: string, invited: boolean = false): Channel {
+    const channel = this.requireLocal(name);
+    if (channel.join_policy === 'invite' && !invited) {
+      throw new Error(`#${channel.name} is invite-only`);
+    }
+    if (channel.members >= this.MAX_MEMBERS) {
+      throw new Error(`#${channel.name} is full (${this.MAX_MEMBERS} members)`);
+    }
+
+    this.database.prepare(`
+      INSERT OR IGNORE INTO channel_members (host, channel, domain) VALUES (?, ?, ?)
+    `).run(this.nodeDomain, channel.name, domain);
+    return this.get(this.nodeDomain, channel.name)!;
+  }
+
+  removeMember(name: string, domain: string): boolean {
+    const channel = ChannelService.normalizeName(name);
+    const result = this.database.prepare(`
+      DELETE FROM channel_members WHERE host = ? AND channel = ? AND domain = ?
+    `).run(this.nodeDomain, channel, domain);
+    return result.changes > 0;
+  }
+
+  isMember(name: string, domain: string): boolean {
+    return !!this.database.prepare(`
+      SELECT 1 FROM channel_members WHERE host = ? AND channel = ? AND domain = ?
+    `).get(this.nodeDomain, ChannelService.normalizeName(name), domain);
+  }
+
+  /**
+   * Role of a domain in a local channel (null if not a member); this node owns all of its channels
+   */
+  getRole(name: string, domain: string): ChannelRole | null {
+    if (domain === this.nodeDomain) {
+      return 'owner';
+    }
+    return this.getMember(ChannelService.normalizeName(name), domain)?.role || null;
+  }
+
+  /**
+   * Promote a member to moderator, or demote back to member
+   */
+  setRole(name: string, domain: string, role: 'moderator' | 'member'): boolean {
+    const channel = this.requireLocal(name);
+    const result = this.database.prepare(`
+      UPDATE channel_members SET role = ? WHERE host = ? AND channel = ? AND domain = ?
+    `).run(role, this.nodeDomain, channel.name, domain);
+    return result.changes > 0;
+  }
+
+  listMembers(name: string): Array<{ domain: string; role: ChannelRole; muted: boolean; joined_at: string }> {
+    const rows = this.database.prepare(`
+      SELECT domain, role, muted, joined_at FROM channel_members
+      WHERE host = ? AND channel = ?
+      ORDER BY role = 'moderator' DESC, joined_at
+    `).all(this.nodeDomain, ChannelService.normalizeName(name)) as any[];
+    return rows.map(row => ({ domain: row.domain, role: row.role, muted: row.muted === 1, joined_at: row.joined_at }));
+  }
+
+  /**
+   * Apply a moderator action in a local channel; only the owner and moderators may moderate
+   */
+  moderate(name: string, actor: string, action: ModerationAction, target: string, reason?: string): ModerationEntry {
+    if (!MODERATION_ACTIONS.includes(action)) {
+      throw new Error(`Unknown moderation action '${action}' (known: ${MODERATION_ACTIONS.join(', ')})`);
+    }
+    const channel = this.requireLocal(name);
+    const role = this.getRole(channel.name, actor);
+    if (role !== 'owner' && role !== 'moderator') {
+      throw new Error(`Only moderators can ${action} in #${channel.name}`);
+    }
+    const targetsMember = action === 'mute' || action === 'unmute';
+    if (targetsMember && this.getRole(channel.name, target) !== 'member') {
+      throw new Error(`${target} is not a member of #${channel.name} (moderators can't be muted)`);
+    }
+    if (!this.applyModeration(this.nodeDomain, channel.name, action, target)) {
+      throw new Error(`No such ${targetsMember ? 'member' : 'message'} in #${channel.name}: ${target}`);
+    }
+
+    this.logger.info(`🛡️ ${actor} ${action} ${target} in #${channel.name}`, { reason });
+    return this.logModeration(this.nodeDomain, channel.name, actor, action, target, reason);
+  }
+
+  /**
+   * Mirror a moderator action pushed by the host of a channel we follow
+   */
+  receiveModeration(host: string, entry: { channel: string; actor: string; action: ModerationAction; target: string; reason?: string }): ModerationEntry | null {
+    const channel = ChannelService.normalizeName(entry.channel);
+    if (host === this.nodeDomain || !this.get(host, channel) || !MODERATION_ACTIONS.includes(entry.action)) {
+      return null;
+    }
+    this.applyModeration(host, channel, entry.action, String(entry.target));
+    return this.logModeration(host, channel, String(entry.actor), entry.action, String(entry.target), entry.reason);
+  }
+
+  getModerationLog(host: string, name: string, limit: number = 50): ModerationEntry[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM channel_moderation_log
+      WHERE host = ? AND channel = ?
+      ORDER BY created_at DESC, id DESC
+      LIMIT ?
+    `).all(host, ChannelService.normalizeName(name), limit) as any[];
+    return rows.map(row => this.mapModeration(row));
+  }
+
+  /**
+   * Federated members of a local channel - the peers its messages are pushed to
+   */
+  getSubscribers(name: string, exclude?: string): string[] {
+    const rows = this.database.prepare(`
+      SELECT domain FROM channel_members
+      WHERE host = ? AND channel = ? AND domain LIKE 'botnet.%' AND domain != ?
+      ORDER BY joined_at
+    `).all(this.nodeDomain, ChannelService.normalizeName(name), exclude || '') as Array<{ domain: string }>;
+    return rows.map(row => row.domain);
+  }
+
+  /**
+   * Post to a local channel, enforcing its post policy (we can always post to our own channels)
+   */
+  post(name: string, author: string, content: string): ChannelMessage {
+    const channel = this.requireLocal(name);
+    if (author !== this.nodeDomain) {
+      if (channel.post_policy === 'owner') {
+        throw new Error(`Only ${this.nodeDomain} can post in #${channel.name}`);
+      }
+      const member = this.getMember(channel.name, author);
+      if (!member) {
+        throw new Error(`Join #${channel.name} before posting`);
+      }
+      if (member.muted) {
+        throw new Error(`You are muted in #${channel.name}`);
+      }
+    }
+    return this.store(this.nodeDomain, channel.name, author, content);
+  }
+
+  /**
+   * Record a remote channel we have joined, so its pushed messages are accepted
+   */
+  follow(host: string, info: { name: string; description?: string; join_policy?: ChannelJoinPolicy; post_policy?: ChannelPostPolicy }): Channel {
+    const channel = ChannelService.normalizeName(info.name);
+    this.database.prepare(`
+      INSERT INTO channels (host, name, description, join_policy, post_policy) VALUES (?, ?, ?, ?, ?)
+      ON CONFLICT(host, name) DO UPDATE SET description = excluded.description,
+        join_policy = excluded.join_policy, post_policy = excluded.post_policy
+    `).run(host, channel, info.description || null, info.join_policy || 'open', info.post_policy || 'members');
+    return this.get(host, channel)!;
+  }
+
+  /**
+   * Store a message pushed by the host of a channel we follow; ignored unless we follow it
+   */
+  receive(host: string, message: { id?: string; channel: string; author: string; content: string; created_at?: string }): ChannelMessage | null {
+    const channel = ChannelService.normalizeName(message.channel);
+    if (host === this.nodeDomain || !this.get(host, channel)) {
+      return null;
+    }
+    const exists = message.id && this.database.prepare(`SELECT 1 FROM channel_messages WHERE id = ?`).get(message.id);
+    if (exists) {
+      return null;
+    }
+    return this.store(host, channel, String(message.author), String(message.content), message.id, message.created_at);
+  }
+
+  getFeed(host: string, name: string, limit: number = 20): ChannelMessage[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM channel_messages
+      WHERE host = ? AND channel = ?
+      ORDER BY pinned DESC, created_at DESC
+      LIMIT ?
+    `).all(host, ChannelService.normalizeName(name), limit) as any[];
+    return rows.map(row => this.mapMessage(row));
+  }
+
+  private store(host: string, channel: string, author: string, content: string, id?: string, createdAt?: string): ChannelMessage {
+    const trimmed = content?.trim();
+    if (!trimmed) {
+      throw new Error('Channel message cannot be empty');
+    }
+    if (trimmed.length > this.MAX_CONTENT_LENGTH) {
+      throw new Error(`Channel message too long (max ${this.MAX_CONTENT_LENGTH} characters)`);
+    }
+
+    const messageId = id || `chan_${randomBytes(8).toString('hex')}`;
+    this.database.prepare(`
+      INSERT INTO channel_messages (id, host, channel, author, content, created_at)
+      VALUES (?, ?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP))
+    `).run(messageId, host, channel, author, trimmed, createdAt || null);
+
+    const row = this.database.prepare(`SELECT * FROM channel_messages WHERE id = ?`).get(messageId);
+    return this.mapMessage(row);
+  }
+
+  private applyModeration(host: string, channel: string, action: ModerationAction, target: string): boolean {
+    switch (action) {
+      case 'pin':
+      case 'unpin':
+        return this.database.prepare(`
+          UPDATE channel_messages SET pinned = ? WHERE host = ? AND channel = ? AND id = ?
+        `).run(action === 'pin' ? 1 : 0, host, channel, target).changes > 0;
+      case 'remove':
+        return this.database.prepare(`
+          DELETE FROM channel_messages WHERE host = ? AND channel = ? AND id = ?
+        `).run(host, channel, target).changes > 0;
+      default:
+        // Mutes are enforced by the host only; followers just record them
+        if (host !== this.nodeDomain) {
+          return true;
+        }
+        return this.database.prepare(`
+          UPDATE channel_members SET muted = ? WHERE host = ? AND channel = ? AND domain = ?
+        `).run(action === 'mute' ? 1 : 0, host, channel, target).changes > 0;
+    }
+  }
+
+  private logModeration(host: string, channel: string, actor: string, action: ModerationAction, target: string, reason?: string): ModerationEntry {
+    const result = this.database.prepare(`
+      INSERT INTO channel_moderation_log (host, channel, actor, action, target, reason) VALUES (?, ?, ?, ?, ?, ?)
+    `).run(host, channel, actor, action, target, reason || null);
+    const row = this.database.prepare(`SELECT * FROM channel_moderation_log WHERE id = ?`).get(result.lastInsertRowid);
+    return this.mapModeration(row);
+  }
+
+  private getMember(channel: string, domain: string): { role: ChannelRole; muted: boolean } | null {
+    const row = this.database.prepare(`
+      SELECT role, muted FROM channel_members WHERE host = ? AND channel = ? AND domain = ?
+    `).get(this.nodeDomain, channel, domain) as { role: ChannelRole; muted: number } | undefined;
+    return row ? { role: row.role, muted: row.muted === 1 } : null;
+  }
+
+  private requireLocal(name: string): Channel {
+    const channel = this.get(this.nodeDomain, ChannelService.normalizeName(name));
+    if (!channel) {
+      throw new Error(`No such channel: #${ChannelService.normalizeName(name)}`);
+    }
+    return channel;
+  }
+
+  private mapChannel(row: any): Channel {
+    return {
+      host: row.host,
+      name: row.name,
+      description: row.description || undefined,
+      join_policy: row.join_policy,
+      post_policy: row.post_policy,
+      members: row.members,
+      created_at: row.created_at
+    };
+  }
+
+  private mapMessage(row: any): ChannelMessage {
+    return {
+      id: row.id,
+      host: row.host,
+      channel: row.channel,
+      author: row.author,
+      content: row.content,
+      pinned: row.pinned === 1,
+      created_at: row.created_at
+    };
+  }
+
+  private mapModeration(row: any): ModerationEntry {
+    return {
+      id: row.id,
+      host: row.host,
+      channel: row.channel,
+      actor: row.actor,
+      action: row.action,
+      target: row.target,
+      reason: row.reason || undefined,
+      created_at: row.created_at
+    };
+  }
+}
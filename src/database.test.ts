@@ -0,0 +1,69 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { initializeDatabase } from './database.js';
+import type { Logger } from './logger.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('gossip reply counts', () => {
+  let db: Database.Database;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+  });
+
+  const insertGossip = (messageId: string, quoteOf?: string) => db.prepare(`
+    INSERT INTO gossip_messages (message_id, source_bot_id, content, metadata) VALUES (?, ?, ?, ?)
+  `).run(messageId, 'botnet.alice.com', `Gossip ${messageId}`, quoteOf ? JSON.stringify({ quote: { messageId: quoteOf } }) : null);
+
+  const replyCount = (messageId: string): number => db.prepare(`
+    SELECT reply_count FROM gossip_messages WHERE message_id = ?
+  `).pluck().get(messageId) as number;
+
+  it('counts replies as they arrive', () => {
+    insertGossip('root');
+    insertGossip('reply-1', 'root');
+    insertGossip('reply-2', 'root');
+    insertGossip('nested', 'reply-1');
+
+    expect(replyCount('root')).toBe(2);
+    expect(replyCount('reply-1')).toBe(1);
+    expect(replyCount('nested')).toBe(0);
+  });
+
+  it('counts replies that arrived before the message they quote', () => {
+    insertGossip('early-1', 'late-root');
+    insertGossip('early-2', 'late-root');
+    insertGossip('late-root');
+
+    expect(replyCount('late-root')).toBe(2);
+  });
+
+  it('ignores metadata without a quote', () => {
+    insertGossip('root');
+    db.prepare(`
+      INSERT INTO gossip_messages (message_id, source_bot_id, content, metadata) VALUES ('plain', 'botnet.alice.com', 'Hi', 'not json')
+    `).run();
+
+    expect(replyCount('root')).toBe(0);
+    expect(replyCount('plain')).toBe(0);
+  });
+
+  it('decrements when a reply is deleted', () => {
+    insertGossip('root');
+    insertGossip('reply-1', 'root');
+    insertGossip('reply-2', 'root');
+
+    db.prepare(`DELETE FROM gossip_messages WHERE message_id = 'reply-1'`).run();
+    expect(replyCount('root')).toBe(1);
+
+    db.prepare(`DELETE FROM gossip_messages WHERE message_id = 'root'`).run();
+    expect(replyCount('reply-2')).toBe(0);
+  });
+});
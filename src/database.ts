@@ -283,6 +283,365 @@ async function runMigrations(db: Database.Database, logger: Logger): Promise<voi
         CREATE INDEX IF NOT EXISTS idx_session_tokens_activity ON session_tokens(last_activity);
       `
     },
+    {
+      filename: "006_audit_log.sql",
+      sql: `
+        -- Append-only audit log for security-relevant actions
+        CREATE TABLE IF NOT EXISTS audit_events (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          event_type TEXT NOT NULL,
+          actor TEXT NOT NULL, -- domain, IP or 'local' for the host agent
+          target TEXT,
+          outcome TEXT NOT NULL DEFAULT 'success', -- success, failure
+          details JSON,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+
+        -- Enforce append-only semantics at the storage layer
+        CREATE TRIGGER IF NOT EXISTS audit_events_no_update
+        BEFORE UPDATE ON audit_events
+        BEGIN
+          SELECT RAISE(ABORT, 'audit_events is append-only');
+        END;
+
+        CREATE TRIGGER IF NOT EXISTS audit_events_no_delete
+        BEFORE DELETE ON audit_events
+        BEGIN
+          SELECT RAISE(ABORT, 'audit_events is append-only');
+        END;
+
+        CREATE INDEX IF NOT EXISTS idx_audit_events_type ON audit_events(event_type);
+        CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor);
+        CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at);
+      `
+    },
+    {
+      filename: "007_reputation_history.sql",
+      sql: `
+        -- History of trust score adjustments for friend nodes
+        CREATE TABLE IF NOT EXISTS reputation_history (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          friend_domain TEXT NOT NULL,
+          delta INTEGER NOT NULL,
+          score_after INTEGER NOT NULL,
+          reason TEXT NOT NULL, -- activity, inactivity, decay, abuse_report, manual
+          note TEXT,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_reputation_history_domain ON reputation_history(friend_domain, created_at);
+      `
+    },
+    {
+      filename: "008_message_drafts.sql",
+      sql: `
+        -- Drafts for messages and gossip that have not been published yet
+        CREATE TABLE IF NOT EXISTS message_drafts (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          draft_id TEXT NOT NULL UNIQUE,
+          kind TEXT NOT NULL DEFAULT 'message', -- message, gossip
+          target_domain TEXT,
+          content TEXT NOT NULL,
+          category TEXT NOT NULL DEFAULT 'general',
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+          updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_message_drafts_updated_at ON message_drafts(updated_at);
+      `
+    },
+    {
+      filename: "009_bookmarks.sql",
+      sql: `
+        -- Local bookmarks of messages and gossip (never shared with federation)
+        CREATE TABLE IF NOT EXISTS bookmarks (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          message_id TEXT NOT NULL UNIQUE,
+          kind TEXT NOT NULL, -- message, gossip
+          source_domain TEXT NOT NULL,
+          content TEXT NOT NULL,
+          note TEXT,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+      `
+    },
+    {
+      filename: "010_friend_lists.sql",
+      sql: `
+        -- Curated, named groups of domains with merged timelines
+        CREATE TABLE IF NOT EXISTS friend_lists (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          name TEXT NOT NULL UNIQUE,
+          description TEXT,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+
+        CREATE TABLE IF NOT EXISTS friend_list_members (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          list_id INTEGER NOT NULL,
+          domain TEXT NOT NULL,
+          added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+          UNIQUE(list_id, domain),
+          FOREIGN KEY (list_id) REFERENCES friend_lists(id)
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_friend_list_members_domain ON friend_list_members(domain);
+      `
+    },
+    {
+      filename: "011_agent_blocks.sql",
+      sql: `
+        -- Agent-level blocks and mutes (independent of friendship status)
+        CREATE TABLE IF NOT EXISTS agent_blocks (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          domain TEXT NOT NULL UNIQUE,
+          kind TEXT NOT NULL DEFAULT 'block', -- block, mute
+          reason TEXT,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+      `
+    },
+    {
+      filename: "012_message_contacts.sql",
+      sql: `
+        -- Senders whose message requests were accepted (skip the requests folder)
+        CREATE TABLE IF NOT EXISTS message_contacts (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          domain TEXT NOT NULL UNIQUE,
+          accepted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+      `
+    },
+    {
+      filename: "013_peer_bandwidth.sql",
+      sql: `
+        -- Bytes exchanged with each federation peer per UTC day
+        CREATE TABLE IF NOT EXISTS peer_bandwidth (
+          domain TEXT NOT NULL,
+          day TEXT NOT NULL,
+          bytes_in INTEGER NOT NULL DEFAULT 0,
+          bytes_out INTEGER NOT NULL DEFAULT 0,
+          PRIMARY KEY (domain, day)
+        );
+      `
+    },
+    {
+      filename: "014_abuse_reports.sql",
+      sql: `
+        -- Abuse reports submitted via botnet.abuse.report
+        CREATE TABLE IF NOT EXISTS abuse_reports (
+          id TEXT PRIMARY KEY,
+          reported_domain TEXT NOT NULL,
+          reason TEXT NOT NULL,
+          details TEXT,
+          reporter TEXT,
+          reporter_ip TEXT,
+          status TEXT NOT NULL DEFAULT 'open', -- open, resolved, dismissed
+          resolution_note TEXT,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+          resolved_at TIMESTAMP
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_abuse_reports_status ON abuse_reports(status, created_at);
+      `
+    },
+    {
+      filename: "015_feature_flags.sql",
+      sql: `
+        -- Runtime feature flag overrides (take precedence over plugin config)
+        CREATE TABLE IF NOT EXISTS feature_flags (
+          name TEXT PRIMARY KEY,
+          enabled INTEGER NOT NULL,
+          updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+      `
+    },
+    {
+      filename: "016_federation_outbox.sql",
+      sql: `
+        -- Federation calls awaiting retry after a failed delivery
+        CREATE TABLE IF NOT EXISTS federation_outbox (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          target_domain TEXT NOT NULL,
+          method TEXT NOT NULL,
+          params TEXT NOT NULL, -- JSON
+          attempts INTEGER NOT NULL DEFAULT 1,
+          last_error TEXT,
+          next_attempt_at TIMESTAMP NOT NULL,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_federation_outbox_due ON federation_outbox(next_attempt_at);
+        CREATE INDEX IF NOT EXISTS idx_federation_outbox_target ON federation_outbox(target_domain);
+      `
+    },
+    {
+      filename: "017_node_identity.sql",
+      sql: `
+        -- Ed25519 node key for signing federation requests (single row)
+        CREATE TABLE IF NOT EXISTS node_identity (
+          id INTEGER PRIMARY KEY CHECK (id = 1),
+          public_key TEXT NOT NULL, -- base64 SPKI DER
+          private_key TEXT NOT NULL, -- base64 PKCS#8 DER
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+      `
+    },
+    {
+      filename: "018_channels.sql",
+      sql: `
+        -- Named public channels: ones we host (host = our domain) and remote ones we follow
+        CREATE TABLE IF NOT EXISTS channels (
+          host TEXT NOT NULL,
+          name TEXT NOT NULL,
+          description TEXT,
+          join_policy TEXT NOT NULL DEFAULT 'open', -- open, invite
+          post_policy TEXT NOT NULL DEFAULT 'members', -- members, owner
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+          PRIMARY KEY (host, name)
+        );
+
+        -- Members of channels we host (federated members receive channel messages)
+        CREATE TABLE IF NOT EXISTS channel_members (
+          host TEXT NOT NULL,
+          channel TEXT NOT NULL,
+          domain TEXT NOT NULL,
+          joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+          PRIMARY KEY (host, channel, domain)
+        );
+
+        CREATE TABLE IF NOT EXISTS channel_messages (
+          id TEXT PRIMARY KEY,
+          host TEXT NOT NULL,
+          channel TEXT NOT NULL,
+          author TEXT NOT NULL,
+          content TEXT NOT NULL,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_channel_messages_feed ON channel_messages(host, channel, created_at);
+      `
+    },
+    {
+      filename: "019_channel_moderation.sql",
+      sql: `
+        -- Channel moderator roles, muted members and pinned messages
+        ALTER TABLE channel_members ADD COLUMN role TEXT NOT NULL DEFAULT 'member'; -- member, moderator
+        ALTER TABLE channel_members ADD COLUMN muted INTEGER NOT NULL DEFAULT 0;
+        ALTER TABLE channel_messages ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+
+        -- Per-channel moderation log (ours for hosted channels, pushed by the host for followed ones)
+        CREATE TABLE IF NOT EXISTS channel_moderation_log (
+          id INTEGER PRIMARY KEY AUTOINCREMENT,
+          host TEXT NOT NULL,
+          channel TEXT NOT NULL,
+          actor TEXT NOT NULL,
+          action TEXT NOT NULL, -- pin, unpin, remove, mute, unmute
+          target TEXT NOT NULL, -- message ID or member domain
+          reason TEXT,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+
+        CREATE INDEX IF NOT EXISTS idx_channel_moderation_log ON channel_moderation_log(host, channel, created_at);
+      `
+    },
+    {
+      filename: "020_gossip_threads.sql",
+      sql: `
+        -- Gossip quoting another message is a reply to it; quote_of names the parent
+        ALTER TABLE gossip_messages ADD COLUMN quote_of TEXT GENERATED ALWAYS AS (
+          CASE WHEN json_valid(metadata) THEN json_extract(metadata, '$.quote.messageId') END
+        ) VIRTUAL;
+        ALTER TABLE gossip_messages ADD COLUMN reply_count INTEGER NOT NULL DEFAULT 0;
+
+        CREATE INDEX IF NOT EXISTS idx_gossip_quote_of ON gossip_messages(quote_of);
+
+        UPDATE gossip_messages SET reply_count = (
+          SELECT COUNT(*) FROM gossip_messages r WHERE r.quote_of = gossip_messages.message_id
+        );
+
+        -- Keep reply_count in step with stored replies, including cleanup deletes
+        CREATE TRIGGER IF NOT EXISTS gossip_reply_added
+        AFTER INSERT ON gossip_messages
+        BEGIN
+          UPDATE gossip_messages SET reply_count = reply_count + 1 WHERE message_id = NEW.quote_of;
+          -- Replies can arrive before the message they quote
+          UPDATE gossip_messages SET reply_count = (
+            SELECT COUNT(*) FROM gossip_messages WHERE quote_of = NEW.message_id
+          ) WHERE id = NEW.id;
+        END;
+
+        CREATE TRIGGER IF NOT EXISTS gossip_reply_removed
+        AFTER DELETE ON gossip_messages
+        WHEN OLD.quote_of IS NOT NULL
+        BEGIN
+          UPDATE gossip_messages SET reply_count = reply_count - 1 WHERE message_id = OLD.quote_of;
+        END;
+      `
+    },
+    {
+      filename: "021_gossip_reactions.sql",
+      sql: `
+        -- Reactions to gossip, ours and those pushed by friends (one row per reactor and reaction)
+        CREATE TABLE IF NOT EXISTS gossip_reactions (
+          message_id TEXT NOT NULL,
+          reactor TEXT NOT NULL, -- Reacting node's domain
+          reaction TEXT NOT NULL,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+          PRIMARY KEY (message_id, reactor, reaction)
+        );
+
+        CREATE TRIGGER IF NOT EXISTS gossip_reactions_cleanup
+        AFTER DELETE ON gossip_messages
+        BEGIN
+          DELETE FROM gossip_reactions WHERE message_id = OLD.message_id;
+        END;
+      `
+    },
+    {
+      filename: "022_thread_mutes.sql",
+      sql: `
+        -- Muted conversations: replies to a gossip thread or responses to a direct message stop showing up
+        CREATE TABLE IF NOT EXISTS thread_mutes (
+          thread_id TEXT PRIMARY KEY, -- Root gossip or direct message ID
+          reason TEXT,
+          created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+      `
+    },
+    {
+      filename: "023_audit_retention.sql",
+      sql: `
+        -- Audit events may be deleted once they're past the 30-day retention floor; newer rows stay append-only
+        DROP TRIGGER IF EXISTS audit_events_no_delete;
+        CREATE TRIGGER audit_events_no_delete
+        BEFORE DELETE ON audit_events
+        WHEN OLD.created_at >= datetime('now', '-30 days')
+        BEGIN
+          SELECT RAISE(ABORT, 'audit_events is append-only');
+        END;
+
+        -- Daily counts kept for pruned events
+        CREATE TABLE IF NOT EXISTS audit_rollups (
+          day TEXT NOT NULL,
+          event_type TEXT NOT NULL,
+          outcome TEXT NOT NULL,
+          count INTEGER NOT NULL,
+          PRIMARY KEY (day, event_type, outcome)
+        );
+      `
+    },
+    {
+      filename: "024_peer_key_pins.sql",
+      sql: `
+        -- Peer node keys restored from an identity backup, kept apart from the refreshed key cache
+        CREATE TABLE IF NOT EXISTS peer_key_pins (
+          domain TEXT PRIMARY KEY,
+          public_key TEXT NOT NULL, -- base64 SPKI DER
+          pinned_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        );
+      `
+    },
   ];
   
   // Apply migrations
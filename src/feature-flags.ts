@@ -0,0 +1,98 @@
+// Feature flags for experimental BotNet subsystems
+// Resolution order: runtime override (botnet_feature_flags tool) → plugin config → built-in default
+
+import type Database from "better-sqlite3";
+import type { Logger } from "./logger.js";
+
+export const FEATURE_FLAGS = {
+  gossip_exchange_fanout: {
+    default: true,
+    description: "Push recent gossip to federated friends after sharing"
+  },
+  gossip_reference_fetch: {
+    default: true,
+    description: "Fetch quoted gossip originals from their origin node"
+  },
+  gossip_anti_entropy: {
+    default: true,
+    description: "Periodically compare gossip digests with federated friends and backfill missing messages"
+  }
+} as const;
+
+export type FeatureFlagName = keyof typeof FEATURE_FLAGS;
+
+export interface FeatureFlagState {
+  name: FeatureFlagName;
+  enabled: boolean;
+  source: 'override' | 'config' | 'default';
+  description: string;
+}
+
+export class FeatureFlags {
+  private overrides: Map<string, boolean> = new Map();
+
+  constructor(
+    private database: Database.Database,
+    private logger: Logger,
+    private configFlags: Record<string, boolean> = {}
+  ) {
+    const rows = this.database.prepare(`
+      SELECT name, enabled FROM feature_flags
+    `).all() as Array<{ name: string; enabled: number }>;
+    for (const row of rows) {
+      this.overrides.set(row.name, row.enabled === 1);
+    }
+  }
+
+  isEnabled(name: FeatureFlagName): boolean {
+    return this.resolve(name).enabled;
+  }
+
+  /**
+   * Set a runtime override (persisted, survives restarts)
+   */
+  set(name: FeatureFlagName, enabled: boolean): FeatureFlagState {
+    FeatureFlags.assertKnown(name);
+    this.database.prepare(`
+      INSERT INTO feature_flags (name, enabled) VALUES (?, ?)
+      ON CONFLICT(name) DO UPDATE SET enabled = excluded.enabled, updated_at = CURRENT_TIMESTAMP
+    `).run(name, enabled ? 1 : 0);
+    this.overrides.set(name, enabled);
+
+    this.logger.info(`🚩 Feature flag ${name} ${enabled ? 'enabled' : 'disabled'}`);
+    return this.resolve(name);
+  }
+
+  /**
+   * Drop a runtime override, falling back to config / default
+   */
+  clear(name: FeatureFlagName): FeatureFlagState {
+    FeatureFlags.assertKnown(name);
+    this.database.prepare(`
+      DELETE FROM feature_flags WHERE name = ?
+    `).run(name);
+    this.overrides.delete(name);
+    return this.resolve(name);
+  }
+
+  list(): FeatureFlagState[] {
+    return (Object.keys(FEATURE_FLAGS) as FeatureFlagName[]).map(name => this.resolve(name));
+  }
+
+  private resolve(name: FeatureFlagName): FeatureFlagState {
+    const definition = FEATURE_FLAGS[name];
+    if (this.overrides.has(name)) {
+      return { name, enabled: this.overrides.get(name)!, source: 'override', description: definition.description };
+    }
+    if (name in this.configFlags) {
+      return { name, enabled: this.configFlags[name], source: 'config', description: definition.description };
+    }
+    return { name, enabled: definition.default, source: 'default', description: definition.description };
+  }
+
+  private static assertKnown(name: string): void {
+    if (!(name in FEATURE_FLAGS)) {
+      throw new Error(`Unknown feature flag '${name}' (known: ${Object.keys(FEATURE_FLAGS).join(', ')})`);
+    }
+  }
+}
@@ -0,0 +1,71 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { AbuseReportService } from './abuse-report-service.js';
+import { ProviderHealth } from '../monitoring/provider-health.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('AbuseReportService', () => {
+  let db: Database.Database;
+  let reports: AbuseReportService;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    reports = new AbuseReportService(db, mockLogger, new ProviderHealth(mockLogger));
+  });
+
+  it('records, lists and closes reports', () => {
+    const report = reports.submit({ reportedDomain: ' botnet.spam.com ', reason: 'spam', reporter: 'botnet.alice.com', reporterIP: '10.0.0.1' });
+    expect(report.reported_domain).toBe('botnet.spam.com');
+    expect(report.status).toBe('open');
+    expect(reports.list('open')).toHaveLength(1);
+
+    expect(reports.close(report.id, 'resolved', 'blocked')!.status).toBe('resolved');
+    expect(reports.close(report.id, 'dismissed')).toBeNull();
+    expect(reports.list('open')).toEqual([]);
+  });
+
+  it('rejects empty and oversized reports', () => {
+    expect(() => reports.submit({ reportedDomain: 'botnet.spam.com', reason: '  ' })).toThrow('required');
+    expect(() => reports.submit({ reportedDomain: 'botnet.spam.com', reason: 'x'.repeat(201) })).toThrow('too long');
+  });
+
+  it('caps open reports per reporter IP without affecting other reporters', () => {
+    for (let i = 0; i < 5; i++) {
+      reports.submit({ reportedDomain: `botnet.spam${i}.com`, reason: 'spam', reporter: `claims-${i}`, reporterIP: '10.0.0.1' });
+    }
+    expect(() => reports.submit({ reportedDomain: 'botnet.spam.com', reason: 'spam', reporterIP: '10.0.0.1' })).toThrow('Too many open abuse reports');
+
+    // Anonymous reporters and other IPs still get through
+    expect(reports.submit({ reportedDomain: 'botnet.spam.com', reason: 'spam', reporterIP: '10.0.0.2' }).status).toBe('open');
+    expect(reports.submit({ reportedDomain: 'botnet.spam.com', reason: 'spam' }).status).toBe('open');
+
+    // Reviewing a report frees a slot
+    reports.close(reports.list('open').find(r => r.reporter_ip === '10.0.0.1')!.id, 'dismissed');
+    expect(reports.submit({ reportedDomain: 'botnet.spam.com', reason: 'spam', reporterIP: '10.0.0.1' }).status).toBe('open');
+  });
+
+  it('caps open reports by claimed reporter when there is no IP', () => {
+    for (let i = 0; i < 5; i++) {
+      reports.submit({ reportedDomain: 'botnet.spam.com', reason: 'spam', reporter: 'botnet.alice.com' });
+    }
+    expect(() => reports.submit({ reportedDomain: 'botnet.spam.com', reason: 'spam', reporter: 'botnet.alice.com' })).toThrow('Too many open abuse reports');
+    expect(reports.submit({ reportedDomain: 'botnet.spam.com', reason: 'spam', reporter: 'botnet.carol.com' }).status).toBe('open');
+  });
+
+  it('stops accepting reports once the whole queue is full', () => {
+    const insert = db.prepare(`INSERT INTO abuse_reports (id, reported_domain, reason, reporter_ip) VALUES (?, ?, ?, ?)`);
+    for (let i = 0; i < 5000; i++) {
+      insert.run(`abuse_${i}`, 'botnet.spam.com', 'spam', `10.1.${Math.floor(i / 250)}.${i % 250}`);
+    }
+    expect(() => reports.submit({ reportedDomain: 'botnet.spam.com', reason: 'spam', reporterIP: '10.0.0.9' })).toThrow('queue is full');
+  });
+});
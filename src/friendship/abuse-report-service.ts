@@ -0,0 +1,136 @@
+// BotNet Abuse Reports
+// Reports submitted by other nodes (or humans) about agents we host or federate with
+
+import { randomBytes } from "crypto";
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+import type { ProviderHealth } from "../monitoring/provider-health.js";
+
+export type AbuseReportStatus = 'open' | 'resolved' | 'dismissed';
+
+export interface AbuseReport {
+  id: string;
+  reported_domain: string;
+  reason: string;
+  details?: string;
+  reporter?: string;
+  reporter_ip?: string;
+  status: AbuseReportStatus;
+  resolution_note?: string;
+  created_at: string;
+  resolved_at?: string;
+}
+
+export class AbuseReportService {
+  private readonly MAX_REASON_LENGTH = 200;
+  private readonly MAX_DETAILS_LENGTH = 2000;
+  private readonly MAX_OPEN_REPORTS_PER_REPORTER = 5; // Per reporter IP (or claimed reporter without one)
+  private readonly MAX_OPEN_REPORTS = 5000; // Storage backstop across all reporters
+
+  constructor(
+    private database: Database.Database,
+    private logger: Logger,
+    private providers: ProviderHealth,
+    private webhookUrl?: string
+  ) {
+    if (webhookUrl) {
+      providers.register('alert_webhook');
+    }
+  }
+
+  /**
+   * Record an inbound report and notify the operator
+   */
+  submit(report: { reportedDomain: string; reason: string; details?: string; reporter?: string; reporterIP?: string }): AbuseReport {
+    const reportedDomain = report.reportedDomain?.trim();
+    const reason = report.reason?.trim();
+    if (!reportedDomain || !reason) {
+      throw new Error('reportedDomain and reason are required');
+    }
+    if (reason.length > this.MAX_REASON_LENGTH || (report.details && report.details.length > this.MAX_DETAILS_LENGTH)) {
+      throw new Error(`Report too long (reason max ${this.MAX_REASON_LENGTH}, details max ${this.MAX_DETAILS_LENGTH} characters)`);
+    }
+
+    // One noisy reporter can't fill the queue and lock everyone else out
+    const reporterOpen = this.database.prepare(`
+      SELECT COUNT(*) AS count FROM abuse_reports
+      WHERE status = 'open' AND (CASE WHEN ? IS NOT NULL THEN reporter_ip = ? ELSE reporter_ip IS NULL AND reporter IS ? END)
+    `).get(report.reporterIP || null, report.reporterIP || null, report.reporter || null) as { count: number };
+    if (reporterOpen.count >= this.MAX_OPEN_REPORTS_PER_REPORTER) {
+      throw new Error('Too many open abuse reports from this reporter, try again once they are reviewed');
+    }
+
+    const open = this.database.prepare(`
+      SELECT COUNT(*) AS count FROM abuse_reports WHERE status = 'open'
+    `).get() as { count: number };
+    if (open.count >= this.MAX_OPEN_REPORTS) {
+      throw new Error('Abuse report queue is full, try again later');
+    }
+
+    const id = `abuse_${randomBytes(8).toString('hex')}`;
+    this.database.prepare(`
+      INSERT INTO abuse_reports (id, reported_domain, reason, details, reporter, reporter_ip)
+      VALUES (?, ?, ?, ?, ?, ?)
+    `).run(id, reportedDomain, reason, report.details || null, report.reporter || null, report.reporterIP || null);
+
+    this.logger.warn('🚩 Abuse report received', { id, reportedDomain, reason, reporter: report.reporter });
+    this.notify(id, reportedDomain, reason, report.reporter);
+
+    return this.get(id)!;
+  }
+
+  get(id: string): AbuseReport | null {
+    const row = this.database.prepare(`
+      SELECT * FROM abuse_reports WHERE id = ?
+    `).get(id) as any;
+    return row ? this.mapReport(row) : null;
+  }
+
+  list(status?: AbuseReportStatus, limit: number = 50): AbuseReport[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM abuse_reports
+      WHERE (? IS NULL OR status = ?)
+      ORDER BY created_at DESC
+      LIMIT ?
+    `).all(status || null, status || null, limit) as any[];
+    return rows.map(row => this.mapReport(row));
+  }
+
+  /**
+   * Close a report as resolved (action taken) or dismissed
+   */
+  close(id: string, status: 'resolved' | 'dismissed', note?: string): AbuseReport | null {
+    const result = this.database.prepare(`
+      UPDATE abuse_reports SET status = ?, resolution_note = ?, resolved_at = CURRENT_TIMESTAMP
+      WHERE id = ? AND status = 'open'
+    `).run(status, note || null, id);
+    return result.changes > 0 ? this.get(id) : null;
+  }
+
+  private notify(id: string, reportedDomain: string, reason: string, reporter?: string): void {
+    if (!this.webhookUrl) {
+      return;
+    }
+
+    void this.providers.send('alert_webhook', this.webhookUrl, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', 'User-Agent': 'BotNet-Alerts/1.0.0' },
+      body: JSON.stringify({ type: 'abuse.reported', id, reportedDomain, reason, reporter, timestamp: new Date().toISOString() })
+    });
+  }
+
+  private mapReport(row: any): AbuseReport {
+    return {
+      id: row.id,
+      reported_domain: row.reported_domain,
+      reason: row.reason,
+      details: row.details || undefined,
+      reporter: row.reporter || undefined,
+      reporter_ip: row.reporter_ip || undefined,
+      status: row.status,
+      resolution_note: row.resolution_note || undefined,
+      created_at: row.created_at,
+      resolved_at: row.resolved_at || undefined
+    };
+  }
+}
@@ -0,0 +1,82 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { BlockListService } from './block-list-service.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('BlockListService', () => {
+  let db: Database.Database;
+  let blocks: BlockListService;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    blocks = new BlockListService(db, mockLogger);
+  });
+
+  const insertGossip = (messageId: string, source: string, quoteOf?: string) => db.prepare(`
+    INSERT INTO gossip_messages (message_id, source_bot_id, content, metadata) VALUES (?, ?, ?, ?)
+  `).run(messageId, source, `Gossip ${messageId}`, quoteOf ? JSON.stringify({ quote: { messageId: quoteOf } }) : null);
+
+  const visible = (clause: string): string[] => db.prepare(`
+    SELECT message_id FROM gossip_messages WHERE ${clause} ORDER BY id
+  `).pluck().all() as string[];
+
+  it('blocks and mutes by domain, one entry per domain', () => {
+    blocks.set('botnet.spam.com', 'mute', 'noisy');
+    expect(blocks.isBlocked('botnet.spam.com')).toBe(false);
+
+    expect(blocks.set('Spammer@botnet.spam.com', 'block').kind).toBe('block');
+    expect(blocks.isBlocked('botnet.spam.com')).toBe(true);
+    expect(blocks.list()).toHaveLength(1);
+    expect(blocks.list('mute')).toEqual([]);
+
+    expect(blocks.remove('botnet.spam.com')).toBe(true);
+    expect(blocks.get('botnet.spam.com')).toBeNull();
+    expect(() => blocks.set('  ', 'block')).toThrow('Domain cannot be empty');
+  });
+
+  it('excludes blocked sources, and muted ones when asked', () => {
+    insertGossip('from-friend', 'botnet.alice.com');
+    insertGossip('from-muted', 'Bot@botnet.noisy.com');
+    insertGossip('from-blocked', 'botnet.spam.com');
+    blocks.set('botnet.noisy.com', 'mute');
+    blocks.set('botnet.spam.com', 'block');
+
+    expect(visible(BlockListService.excludeClause('source_bot_id', false))).toEqual(['from-friend', 'from-muted']);
+    expect(visible(BlockListService.excludeClause('source_bot_id', true))).toEqual(['from-friend']);
+  });
+
+  it('hides replies anywhere below a muted thread, but not the root or other threads', () => {
+    insertGossip('root', 'botnet.alice.com');
+    insertGossip('reply', 'botnet.carol.com', 'root');
+    insertGossip('nested', 'botnet.dave.com', 'reply');
+    insertGossip('other', 'botnet.alice.com');
+    insertGossip('other-reply', 'botnet.carol.com', 'other');
+
+    blocks.muteThread(' root ', 'flame war');
+    expect(visible(BlockListService.mutedThreadClause('quote_of'))).toEqual(['root', 'other', 'other-reply']);
+    expect(blocks.listThreadMutes()).toEqual([expect.objectContaining({ thread_id: 'root', reason: 'flame war' })]);
+
+    expect(blocks.unmuteThread('root')).toBe(true);
+    expect(visible(BlockListService.mutedThreadClause('quote_of'))).toHaveLength(5);
+    expect(() => blocks.muteThread(' ')).toThrow('Thread ID cannot be empty');
+  });
+
+  it('stops walking a thread past the maximum depth', () => {
+    insertGossip('m0', 'botnet.alice.com');
+    for (let i = 1; i <= 6; i++) {
+      insertGossip(`m${i}`, 'botnet.alice.com', `m${i - 1}`);
+    }
+    blocks.muteThread('m0');
+
+    expect(visible(BlockListService.mutedThreadClause('quote_of'))).toEqual(['m0', 'm6']);
+  });
+});
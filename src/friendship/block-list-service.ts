@@ -0,0 +1,167 @@
+// BotNet Block List
+// Agent-level blocks and mutes, independent of friendship status
+// - block: drop their direct messages and gossip, hide anything already stored
+// - mute:  keep receiving, but hide their gossip from feeds and timelines
+// Threads are muted separately: replies and responses in them stop showing up in reviews
+
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+
+export type BlockKind = 'block' | 'mute';
+
+export interface BlockEntry {
+  domain: string;
+  kind: BlockKind;
+  reason?: string;
+  created_at: string;
+}
+
+export interface ThreadMute {
+  thread_id: string;
+  reason?: string;
+  created_at: string;
+}
+
+// Matches how deep botnet.gossip.thread will walk a conversation
+const MAX_MUTE_DEPTH = 5;
+
+export class BlockListService {
+  constructor(
+    private database: Database.Database,
+    private logger: Logger
+  ) {}
+
+  /**
+   * Block or mute a domain (replaces any existing entry for it)
+   */
+  set(domain: string, kind: BlockKind, reason?: string): BlockEntry {
+    const normalized = BlockListService.domainOf(domain);
+    if (!normalized) {
+      throw new Error('Domain cannot be empty');
+    }
+
+    this.database.prepare(`
+      INSERT INTO agent_blocks (domain, kind, reason) VALUES (?, ?, ?)
+      ON CONFLICT(domain) DO UPDATE SET kind = excluded.kind, reason = excluded.reason, created_at = CURRENT_TIMESTAMP
+    `).run(normalized, kind, reason || null);
+
+    this.logger.info(kind === 'block' ? '🚫 Domain blocked' : '🔇 Domain muted', { domain: normalized, reason });
+    return this.get(normalized)!;
+  }
+
+  /**
+   * Remove a block or mute
+   */
+  remove(domain: string): boolean {
+    const result = this.database.prepare(`
+      DELETE FROM agent_blocks WHERE domain = ?
+    `).run(BlockListService.domainOf(domain));
+    return result.changes > 0;
+  }
+
+  get(domain: string): BlockEntry | null {
+    const row = this.database.prepare(`
+      SELECT * FROM agent_blocks WHERE domain = ?
+    `).get(BlockListService.domainOf(domain)) as any;
+
+    return row ? this.mapEntry(row) : null;
+  }
+
+  list(kind?: BlockKind): BlockEntry[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM agent_blocks
+      WHERE (? IS NULL OR kind = ?)
+      ORDER BY created_at DESC
+    `).all(kind || null, kind || null) as any[];
+
+    return rows.map(row => this.mapEntry(row));
+  }
+
+  /**
+   * Whether content from this source should be dropped on delivery
+   */
+  isBlocked(source: string): boolean {
+    return this.get(source)?.kind === 'block';
+  }
+
+  /**
+   * Mute a conversation by its root gossip or direct message ID
+   */
+  muteThread(threadId: string, reason?: string): ThreadMute {
+    const normalized = threadId.trim();
+    if (!normalized) {
+      throw new Error('Thread ID cannot be empty');
+    }
+
+    this.database.prepare(`
+      INSERT INTO thread_mutes (thread_id, reason) VALUES (?, ?)
+      ON CONFLICT(thread_id) DO UPDATE SET reason = excluded.reason, created_at = CURRENT_TIMESTAMP
+    `).run(normalized, reason || null);
+
+    this.logger.info('🔇 Thread muted', { threadId: normalized, reason });
+    return this.listThreadMutes().find(mute => mute.thread_id === normalized)!;
+  }
+
+  unmuteThread(threadId: string): boolean {
+    const result = this.database.prepare(`
+      DELETE FROM thread_mutes WHERE thread_id = ?
+    `).run(threadId.trim());
+    return result.changes > 0;
+  }
+
+  listThreadMutes(): ThreadMute[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM thread_mutes ORDER BY created_at DESC
+    `).all() as any[];
+
+    return rows.map(row => ({
+      thread_id: row.thread_id,
+      reason: row.reason || undefined,
+      created_at: row.created_at
+    }));
+  }
+
+  /**
+   * Gossip sources are stored as "domain" or "name@domain" - match on the domain part
+   */
+  static domainOf(source: string): string {
+    const trimmed = source.trim();
+    return trimmed.includes('@') ? trimmed.split('@')[1] : trimmed;
+  }
+
+  /**
+   * SQL condition excluding blocked (and optionally muted) sources from a query
+   */
+  static excludeClause(column: string, includeMuted: boolean): string {
+    const kinds = includeMuted ? `('block', 'mute')` : `('block')`;
+    return `NOT EXISTS (
+      SELECT 1 FROM agent_blocks b
+      WHERE b.kind IN ${kinds} AND (${column} = b.domain OR ${column} LIKE '%@' || b.domain)
+    )`;
+  }
+
+  /**
+   * SQL condition excluding gossip replies anywhere below a muted thread; column is the row's quote_of
+   */
+  static mutedThreadClause(column: string): string {
+    return `NOT EXISTS (
+      WITH RECURSIVE ancestors(message_id, depth) AS (
+        SELECT ${column}, 1
+        UNION ALL
+        SELECT parent.quote_of, ancestors.depth + 1
+        FROM ancestors JOIN gossip_messages parent ON parent.message_id = ancestors.message_id
+        WHERE ancestors.depth < ${MAX_MUTE_DEPTH}
+      )
+      SELECT 1 FROM ancestors JOIN thread_mutes t ON t.thread_id = ancestors.message_id
+    )`;
+  }
+
+  private mapEntry(row: any): BlockEntry {
+    return {
+      domain: row.domain,
+      kind: row.kind,
+      reason: row.reason || undefined,
+      created_at: row.created_at
+    };
+  }
+}
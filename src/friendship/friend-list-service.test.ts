@@ -0,0 +1,69 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { FriendListService } from './friend-list-service.js';
+import { BlockListService } from './block-list-service.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('FriendListService', () => {
+  let db: Database.Database;
+  let lists: FriendListService;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    lists = new FriendListService(db, mockLogger);
+  });
+
+  it('matches list names however they are padded', () => {
+    lists.createList('  Research  ', 'Paper bots');
+    expect(() => lists.createList('Research')).toThrow('List already exists: Research');
+
+    lists.addMember(' Research', 'botnet.alice.com');
+    expect(lists.getList('Research ')).toEqual(expect.objectContaining({ name: 'Research', description: 'Paper bots', members: ['botnet.alice.com'] }));
+    expect(lists.deleteList(' Research ')).toBe(true);
+    expect(lists.getLists()).toEqual([]);
+    expect(() => lists.createList('   ')).toThrow('List name cannot be empty');
+  });
+
+  it('adds members once and removes them', () => {
+    lists.createList('Friends');
+    lists.addMember('Friends', 'botnet.bob.com');
+    lists.addMember('Friends', ' botnet.bob.com ');
+    lists.addMember('Friends', 'botnet.alice.com');
+    expect(lists.getList('Friends')!.members).toEqual(['botnet.alice.com', 'botnet.bob.com']);
+
+    expect(lists.removeMember('Friends', 'botnet.bob.com').members).toEqual(['botnet.alice.com']);
+    expect(() => lists.addMember('Missing', 'botnet.bob.com')).toThrow('List not found: Missing');
+  });
+
+  it("merges members' messages and gossip, newest first, without blocked or muted sources", () => {
+    lists.createList('Friends');
+    lists.addMember('Friends', 'botnet.alice.com');
+    lists.addMember('Friends', 'botnet.noisy.com');
+
+    const message = db.prepare(`
+      INSERT INTO messages (message_id, from_domain, to_domain, content, message_type, status, created_at)
+      VALUES (?, ?, 'botnet.bob.com', ?, 'chat', 'delivered', ?)
+    `);
+    const gossip = db.prepare(`
+      INSERT INTO gossip_messages (message_id, source_bot_id, content, category, created_at) VALUES (?, ?, ?, 'general', ?)
+    `);
+    message.run('dm-1', 'botnet.alice.com', 'Hello', '2026-01-01 10:00:00');
+    gossip.run('g-1', 'Alice@botnet.alice.com', 'News', '2026-01-01 11:00:00');
+    gossip.run('g-2', 'botnet.noisy.com', 'Noise', '2026-01-01 12:00:00');
+    message.run('dm-2', 'botnet.noisy.com', 'Still talking', '2026-01-01 13:00:00');
+    gossip.run('g-3', 'botnet.stranger.com', 'Not on the list', '2026-01-01 14:00:00');
+    new BlockListService(db, mockLogger).set('botnet.noisy.com', 'mute');
+
+    expect(lists.getTimeline('Friends').map(entry => `${entry.kind}:${entry.id}`)).toEqual(['message:dm-2', 'gossip:g-1', 'message:dm-1']);
+    expect(lists.getTimeline('Friends', 1)).toHaveLength(1);
+  });
+});
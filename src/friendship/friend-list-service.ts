@@ -0,0 +1,219 @@
+// BotNet Friend Lists
+// Named, curated groups of domains (local or federated) with a merged timeline per list
+
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+import { BlockListService } from "./block-list-service.js";
+
+export interface FriendList {
+  name: string;
+  description?: string;
+  members: string[];
+  created_at: string;
+}
+
+export interface TimelineEntry {
+  id: string;
+  kind: 'message' | 'gossip';
+  source_domain: string;
+  content: string;
+  category?: string;
+  created_at: string;
+}
+
+export class FriendListService {
+  private readonly MAX_LISTS = 20;
+  private readonly MAX_MEMBERS_PER_LIST = 100;
+  private readonly MAX_TIMELINE_ENTRIES = 100;
+
+  constructor(
+    private database: Database.Database,
+    private logger: Logger
+  ) {}
+
+  /**
+   * Create a new named list
+   */
+  createList(name: string, description?: string): FriendList {
+    const trimmed = FriendListService.normalizeName(name);
+    if (!trimmed) {
+      throw new Error('List name cannot be empty');
+    }
+
+    const listCount = this.database.prepare(`
+      SELECT COUNT(*) as count FROM friend_lists
+    `).get() as { count: number };
+
+    if (listCount.count >= this.MAX_LISTS) {
+      throw new Error(`List limit reached (${this.MAX_LISTS}). Delete an existing list first.`);
+    }
+
+    if (this.findListId(trimmed)) {
+      throw new Error(`List already exists: ${trimmed}`);
+    }
+
+    this.database.prepare(`
+      INSERT INTO friend_lists (name, description) VALUES (?, ?)
+    `).run(trimmed, description || null);
+
+    this.logger.info('📋 Friend list created', { name: trimmed });
+    return this.getList(trimmed)!;
+  }
+
+  /**
+   * Delete a list and its memberships
+   */
+  deleteList(name: string): boolean {
+    const listId = this.findListId(name);
+    if (!listId) {
+      return false;
+    }
+
+    this.database.transaction(() => {
+      this.database.prepare(`DELETE FROM friend_list_members WHERE list_id = ?`).run(listId);
+      this.database.prepare(`DELETE FROM friend_lists WHERE id = ?`).run(listId);
+    })();
+
+    this.logger.info('🗑️ Friend list deleted', { name });
+    return true;
+  }
+
+  /**
+   * Add a domain to a list (no-op if already a member)
+   */
+  addMember(name: string, domain: string): FriendList {
+    const listId = this.requireListId(name);
+
+    const memberCount = this.database.prepare(`
+      SELECT COUNT(*) as count FROM friend_list_members WHERE list_id = ?
+    `).get(listId) as { count: number };
+
+    if (memberCount.count >= this.MAX_MEMBERS_PER_LIST) {
+      throw new Error(`List ${name} is full (${this.MAX_MEMBERS_PER_LIST} members)`);
+    }
+
+    this.database.prepare(`
+      INSERT OR IGNORE INTO friend_list_members (list_id, domain) VALUES (?, ?)
+    `).run(listId, domain.trim());
+
+    return this.getList(name)!;
+  }
+
+  /**
+   * Remove a domain from a list
+   */
+  removeMember(name: string, domain: string): FriendList {
+    const listId = this.requireListId(name);
+
+    this.database.prepare(`
+      DELETE FROM friend_list_members WHERE list_id = ? AND domain = ?
+    `).run(listId, domain.trim());
+
+    return this.getList(name)!;
+  }
+
+  /**
+   * Get one list with its members
+   */
+  getList(name: string): FriendList | null {
+    const row = this.database.prepare(`
+      SELECT * FROM friend_lists WHERE name = ?
+    `).get(FriendListService.normalizeName(name)) as any;
+
+    return row ? this.mapList(row) : null;
+  }
+
+  /**
+   * Get all lists with their members
+   */
+  getLists(): FriendList[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM friend_lists ORDER BY name ASC
+    `).all() as any[];
+
+    return rows.map(row => this.mapList(row));
+  }
+
+  /**
+   * Merged timeline of direct messages and gossip from a list's members (newest first)
+   */
+  getTimeline(name: string, limit: number = 50): TimelineEntry[] {
+    const list = this.getList(name);
+    if (!list) {
+      throw new Error(`List not found: ${name}`);
+    }
+    if (!list.members.length) {
+      return [];
+    }
+
+    const placeholders = list.members.map(() => '?').join(',');
+    // Gossip sources are stored either as a bare domain or as "name@domain"
+    const gossipSourceMatch = list.members.map(() => `source_bot_id LIKE ?`).join(' OR ');
+
+    const rows = this.database.prepare(`
+      SELECT message_id AS id, 'message' AS kind, from_domain AS source_domain,
+        content, message_type AS category, created_at
+      FROM messages
+      WHERE from_domain IN (${placeholders})
+        AND ${BlockListService.excludeClause('from_domain', false)}
+      UNION ALL
+      SELECT message_id AS id, 'gossip' AS kind, source_bot_id AS source_domain,
+        content, category, created_at
+      FROM gossip_messages
+      WHERE (source_bot_id IN (${placeholders}) OR ${gossipSourceMatch})
+        AND ${BlockListService.excludeClause('source_bot_id', true)}
+        AND ${BlockListService.mutedThreadClause('quote_of')}
+      ORDER BY created_at DESC
+      LIMIT ?
+    `).all(
+      ...list.members,
+      ...list.members,
+      ...list.members.map(domain => `%@${domain}`),
+      Math.min(limit, this.MAX_TIMELINE_ENTRIES)
+    ) as any[];
+
+    return rows.map(row => ({
+      id: row.id,
+      kind: row.kind,
+      source_domain: row.source_domain,
+      content: row.content,
+      category: row.category || undefined,
+      created_at: row.created_at
+    }));
+  }
+
+  private findListId(name: string): number | undefined {
+    const row = this.database.prepare(`
+      SELECT id FROM friend_lists WHERE name = ?
+    `).get(FriendListService.normalizeName(name)) as { id: number } | undefined;
+    return row?.id;
+  }
+
+  /**
+   * List names are stored trimmed; every lookup goes through here so they match
+   */
+  private static normalizeName(name: string): string {
+    return name.trim();
+  }
+
+  private requireListId(name: string): number {
+    const listId = this.findListId(name);
+    if (!listId) {
+      throw new Error(`List not found: ${name}`);
+    }
+    return listId;
+  }
+
+  private mapList(row: any): FriendList {
+    const members = this.database.prepare(`
+      SELECT domain FROM friend_list_members WHERE list_id = ? ORDER BY domain ASC
+    `).pluck().all(row.id) as string[];
+
+    return {
+      name: row.name,
+      description: row.description || undefined,
+      members,
+      created_at: row.created_at
+    };
+  }
+}
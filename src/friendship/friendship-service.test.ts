@@ -0,0 +1,65 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { FriendshipService } from './friendship-service.js';
+import { AuditService } from '../audit/audit-service.js';
+import { ProofOfWork } from '../auth/proof-of-work.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+import type { BotNetConfig } from '../../index.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('FriendshipService incoming requests', () => {
+  let db: Database.Database;
+  let service: FriendshipService;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    service = new FriendshipService(db, {
+      botDomain: 'botnet.bob.com',
+      friendRequestLimitPerDomain: 10,
+      friendRequestLimitPerIP: 3,
+      friendRequestProofOfWorkBits: 8,
+    } as BotNetConfig, mockLogger, {} as any, new AuditService(db, mockLogger));
+  });
+
+  const auditRows = (): number => db.prepare('SELECT COUNT(*) FROM audit_events').pluck().get() as number;
+
+  it('accepts a stamped request and audits it', async () => {
+    const stamp = await ProofOfWork.solve(service.getProofOfWorkResource('botnet.alice.com'), 8);
+
+    const result = await service.createIncomingFriendRequest('botnet.alice.com', 'Hi', '203.0.113.7', stamp);
+    expect(result.status).toBe('pending_challenge_review');
+    expect(auditRows()).toBe(1);
+  });
+
+  it('refuses unstamped requests without writing to the audit log', async () => {
+    await expect(service.createIncomingFriendRequest('botnet.alice.com', 'Hi', '203.0.113.7'))
+      .rejects.toThrow('Proof of work required (8 bits)');
+    await expect(service.createIncomingFriendRequest('botnet.alice.com', 'Hi', '203.0.113.7', { timestamp: Date.now(), nonce: 'bogus' }))
+      .rejects.toThrow('Proof of work');
+    expect(auditRows()).toBe(0);
+  });
+
+  it('applies the per-IP cap before checking stamps', async () => {
+    for (let i = 0; i < 3; i++) {
+      await expect(service.createIncomingFriendRequest(`botnet.spam${i}.com`, undefined, '203.0.113.7')).rejects.toThrow('Proof of work');
+    }
+    await expect(service.createIncomingFriendRequest('botnet.spam3.com', undefined, '203.0.113.7'))
+      .rejects.toThrow('Rate limit exceeded for friend requests from this address');
+  });
+
+  it("doesn't let unstamped requests use up a domain's allowance", async () => {
+    for (let i = 0; i < 12; i++) {
+      await expect(service.createIncomingFriendRequest('botnet.alice.com', undefined, `198.51.100.${i}`)).rejects.toThrow('Proof of work');
+    }
+    const stamp = await ProofOfWork.solve(service.getProofOfWorkResource('botnet.alice.com'), 8);
+    expect((await service.createIncomingFriendRequest('botnet.alice.com', 'Hi', '192.0.2.1', stamp)).status).toBe('pending_challenge_review');
+  });
+});
@@ -6,6 +6,8 @@ import type Database from "better-sqlite3";
 import type { BotNetConfig } from "../../index.js";
 import { RateLimiter } from "../rate-limiter.js";
 import type { MCPClient } from "../mcp/mcp-client.js";
+import { ProofOfWork, type ProofOfWorkStamp } from "../auth/proof-of-work.js";
+import type { AuditService } from "../audit/audit-service.js";
 
 export interface Friendship {
   id: string;
@@ -39,22 +41,30 @@ export class FriendshipService {
     warn: (message: string, ...args: any[]) => void;
   };
   private mcpClient: MCPClient;
+  private auditService?: AuditService;
   
   private friendships: Map<string, Friendship> = new Map();
   private pendingRequests: Map<string, FriendshipRequest> = new Map();
   private rateLimiter: RateLimiter;
+  private incomingDomainLimiter: RateLimiter;
+  private incomingIPLimiter: RateLimiter;
+  private proofOfWork: ProofOfWork;
 
   // Database limits to prevent overfilling
   private readonly MAX_ACTIVE_FRIENDSHIPS = 100;
   private readonly MAX_PENDING_REQUESTS = 50;
   private readonly CLEANUP_OLD_REQUESTS_DAYS = 30;
 
-  constructor(database: Database.Database, config: BotNetConfig, logger: FriendshipService['logger'], mcpClient: MCPClient) {
+  constructor(database: Database.Database, config: BotNetConfig, logger: FriendshipService['logger'], mcpClient: MCPClient, auditService?: AuditService) {
     this.database = database;
     this.config = config;
     this.logger = logger;
     this.mcpClient = mcpClient;
+    this.auditService = auditService;
     this.rateLimiter = new RateLimiter(logger, 60 * 1000, 5); // 5 friendship ops per minute
+    this.incomingDomainLimiter = new RateLimiter(logger, 60 * 60 * 1000, config.friendRequestLimitPerDomain);
+    this.incomingIPLimiter = new RateLimiter(logger, 60 * 60 * 1000, config.friendRequestLimitPerIP);
+    this.proofOfWork = new ProofOfWork(config.friendRequestProofOfWorkBits);
   }
 
   /**
@@ -244,6 +254,7 @@ export class FriendshipService {
         fromDomain,
         toDomain
       });
+      this.auditService?.record('friendship.accepted', { actor: toDomain, target: fromDomain, details: { friendshipId: existing.id } });
       
       return { friendshipId: existing.id.toString() };
     } else {
@@ -267,6 +278,7 @@ export class FriendshipService {
         fromDomain,
         toDomain
       });
+      this.auditService?.record('friendship.accepted', { actor: toDomain, target: fromDomain, details: { friendshipId: Number(result.lastInsertRowid) } });
       
       return { friendshipId: result.lastInsertRowid!.toString() };
     }
@@ -511,6 +523,7 @@ export class FriendshipService {
         requestId,
         type: 'local'
       });
+      this.auditService?.record('friendship.accepted', { actor: 'local', target: friendship.friend_domain, details: { requestId, type: 'local' } });
       
       return {
         status: 'accepted',
@@ -594,6 +607,7 @@ export class FriendshipService {
         fromDomain: friendship.friend_domain,
         challengeId
       });
+      this.auditService?.record('friendship.accepted', { actor: 'local', target: friendship.friend_domain, details: { challengeId, type: 'federated' } });
       
       return { verified: true, friendshipId: friendship.id.toString() };
     } else {
@@ -608,6 +622,7 @@ export class FriendshipService {
         fromDomain: friendship.friend_domain,
         challengeId
       });
+      this.auditService?.record('friendship.challenge_failed', { actor: friendship.friend_domain, outcome: 'failure', details: { challengeId } });
       
       return { verified: false };
     }
@@ -712,6 +727,7 @@ export class FriendshipService {
       friendshipId: friendship.id,
       removedBy: this.config.botDomain
     });
+    this.auditService?.record('friendship.removed', { actor: 'local', target: friendDomain, details: { friendshipId: friendship.id } });
 
     return {
       success: true,
@@ -719,6 +735,31 @@ export class FriendshipService {
     };
   }
 
+  /**
+   * Mark an active friend as a shadow peer: federation with them is computed and
+   * recorded but nothing is delivered to them or persisted from them
+   */
+  setShadow(friendDomain: string, enabled: boolean): boolean {
+    const result = this.database.prepare(`
+      UPDATE friendships
+      SET metadata = json_set(COALESCE(metadata, '{}'), '$.shadow', json(?)), updated_at = CURRENT_TIMESTAMP
+      WHERE friend_domain = ? AND status = 'active'
+    `).run(enabled ? 'true' : 'false', friendDomain);
+
+    if (result.changes > 0) {
+      this.logger.info(enabled ? '👻 Friend set to shadow mode' : '👻 Friend shadow mode cleared', { friendDomain });
+    }
+    return result.changes > 0;
+  }
+
+  isShadow(friendDomain: string): boolean {
+    const row = this.database.prepare(`
+      SELECT 1 FROM friendships
+      WHERE friend_domain = ? AND status = 'active' AND json_extract(metadata, '$.shadow') = 1
+    `).get(friendDomain);
+    return !!row;
+  }
+
   /**
    * Block a domain (prevent future friendship requests)
    */
@@ -741,6 +782,7 @@ export class FriendshipService {
       fromDomain,
       targetDomain
     });
+    this.auditService?.record('friendship.blocked', { actor: fromDomain, target: targetDomain });
 
     return true;
   }
@@ -791,13 +833,35 @@ export class FriendshipService {
   /**
    * Create an incoming friendship request (with rate limiting and bearer tokens)
    */
-  async createIncomingFriendRequest(fromDomain: string, message?: string, clientIP?: string): Promise<{ bearerToken: string; status: string }> {
+  async createIncomingFriendRequest(fromDomain: string, message?: string, clientIP?: string, proofOfWork?: ProofOfWorkStamp): Promise<{ bearerToken: string; status: string }> {
     // Rate limiting check
     const rateLimitKey = clientIP || fromDomain;
     if (!this.rateLimiter.checkRateLimit(rateLimitKey, 'createFriendRequest')) {
       throw new Error('Rate limit exceeded. Please try again later.');
     }
 
+    // Hourly caps per source IP and per claimed domain so requests can't exhaust storage
+    if (clientIP && !this.incomingIPLimiter.checkRateLimit(clientIP, 'incomingFriendRequestIP')) {
+      throw new Error('Rate limit exceeded for friend requests from this address. Please try again later.');
+    }
+
+    // Optional proof-of-work pre-filter. Rejections are logged, not audited: anyone can send bogus stamps,
+    // and they would fill the append-only audit log. Checked before the per-domain cap, so unstamped
+    // requests naming a domain can't use up that domain's allowance
+    const powCheck = this.proofOfWork.verify(this.getProofOfWorkResource(fromDomain), proofOfWork);
+    if (!powCheck.valid) {
+      this.logger.warn('🚫 Friend request rejected by proof-of-work filter', {
+        fromDomain,
+        clientIP,
+        error: powCheck.error
+      });
+      throw new Error(powCheck.error);
+    }
+
+    if (!this.incomingDomainLimiter.checkRateLimit(fromDomain, 'incomingFriendRequestDomain')) {
+      throw new Error(`Rate limit exceeded for friend requests from ${fromDomain}. Please try again later.`);
+    }
+
     // Cleanup old data and check limits before creating new requests
     this.cleanupOldData();
     this.checkFriendshipLimits();
@@ -854,6 +918,7 @@ export class FriendshipService {
       bearerToken: bearerToken.substring(0, 12) + '...', // Log partial token
       friendshipId: result.lastInsertRowid
     });
+    this.auditService?.record('friendship.request_received', { actor: fromDomain, details: { requestType, clientIP, friendshipId: Number(result.lastInsertRowid) } });
 
     return {
       bearerToken,
@@ -861,6 +926,20 @@ export class FriendshipService {
     };
   }
 
+  /**
+   * Proof-of-work difficulty required on incoming friend requests (0 = disabled)
+   */
+  getProofOfWorkDifficulty(): number {
+    return this.proofOfWork.requiredDifficulty;
+  }
+
+  /**
+   * Resource string a requester must stamp: "<fromDomain>:<our domain>"
+   */
+  getProofOfWorkResource(fromDomain: string): string {
+    return `${fromDomain}:${this.config.botDomain}`;
+  }
+
   /**
    * Upgrade a non-federated friend to federated status
    * Called by a formerly local node that acquired a domain
@@ -0,0 +1,169 @@
+// BotNet Reputation Service
+// Ongoing trust score adjustments for friend nodes (activity, inactivity, reports) with decay toward a baseline
+
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+
+export type ReputationReason = 'activity' | 'inactivity' | 'decay' | 'abuse_report' | 'manual';
+
+export interface ReputationChange {
+  delta: number;
+  scoreAfter: number;
+  reason: ReputationReason;
+  note?: string;
+  createdAt: string;
+}
+
+export interface ReputationMaintenanceStats {
+  penalized: number;
+  decayed: number;
+}
+
+export class ReputationService {
+  private lastActivityCredit: Map<string, number> = new Map();
+
+  private readonly BASELINE_SCORE = 50;
+  private readonly MIN_SCORE = 0;
+  private readonly MAX_SCORE = 100;
+  private readonly ACTIVITY_CREDIT = 1;
+  private readonly ACTIVITY_CREDIT_INTERVAL_MS = 24 * 60 * 60 * 1000; // At most one activity credit per day
+  private readonly INACTIVITY_PENALTY = 2;
+  private readonly INACTIVITY_DAYS = 7;
+  private readonly DECAY_RATE = 0.1; // Move 10% of the distance back to baseline per maintenance pass
+  private readonly MAX_HISTORY = 100;
+
+  constructor(
+    private database: Database.Database,
+    private logger: Logger
+  ) {}
+
+  /**
+   * Credit a friend for a successful authenticated interaction (throttled to once per day)
+   */
+  recordActivity(friendDomain: string): void {
+    const now = Date.now();
+    const lastCredit = this.lastActivityCredit.get(friendDomain);
+    if (lastCredit && now - lastCredit < this.ACTIVITY_CREDIT_INTERVAL_MS) {
+      return;
+    }
+    this.lastActivityCredit.set(friendDomain, now);
+
+    try {
+      this.database.prepare(`
+        UPDATE friendships SET last_seen = CURRENT_TIMESTAMP
+        WHERE friend_domain = ? AND status = 'active'
+      `).run(friendDomain);
+      this.adjust(friendDomain, this.ACTIVITY_CREDIT, 'activity');
+    } catch (error) {
+      this.logger.error('Failed to record friend activity', { friendDomain, error });
+    }
+  }
+
+  /**
+   * Apply a score change and record it in the history
+   * Returns the new score, or null if the domain is not a known friend
+   */
+  adjust(friendDomain: string, delta: number, reason: ReputationReason, note?: string): number | null {
+    const friendship = this.database.prepare(`
+      SELECT trust_score FROM friendships WHERE friend_domain = ?
+    `).get(friendDomain) as { trust_score: number } | undefined;
+
+    if (!friendship) {
+      return null;
+    }
+
+    const current = friendship.trust_score ?? this.BASELINE_SCORE;
+    const scoreAfter = Math.max(this.MIN_SCORE, Math.min(this.MAX_SCORE, current + delta));
+    if (scoreAfter === current) {
+      return current;
+    }
+
+    this.database.transaction(() => {
+      this.database.prepare(`
+        UPDATE friendships SET trust_score = ?, updated_at = CURRENT_TIMESTAMP
+        WHERE friend_domain = ?
+      `).run(scoreAfter, friendDomain);
+
+      this.database.prepare(`
+        INSERT INTO reputation_history (friend_domain, delta, score_after, reason, note)
+        VALUES (?, ?, ?, ?, ?)
+      `).run(friendDomain, scoreAfter - current, scoreAfter, reason, note || null);
+    })();
+
+    if (reason !== 'activity' && reason !== 'decay') {
+      this.logger.info('⚖️ Reputation adjusted', { friendDomain, delta: scoreAfter - current, scoreAfter, reason });
+    }
+
+    return scoreAfter;
+  }
+
+  /**
+   * Periodic pass: penalize friends that have gone quiet, decay everyone else toward baseline
+   */
+  runMaintenance(): ReputationMaintenanceStats {
+    const stats: ReputationMaintenanceStats = { penalized: 0, decayed: 0 };
+
+    const friends = this.database.prepare(`
+      SELECT friend_domain, trust_score,
+        COALESCE(last_seen, created_at) < datetime('now', ?) AS inactive
+      FROM friendships
+      WHERE status = 'active'
+    `).all(`-${this.INACTIVITY_DAYS} days`) as Array<{ friend_domain: string; trust_score: number; inactive: number }>;
+
+    for (const friend of friends) {
+      if (friend.inactive) {
+        this.adjust(friend.friend_domain, -this.INACTIVITY_PENALTY, 'inactivity', `No contact for ${this.INACTIVITY_DAYS}+ days`);
+        stats.penalized++;
+        continue;
+      }
+
+      const distance = this.BASELINE_SCORE - (friend.trust_score ?? this.BASELINE_SCORE);
+      if (distance !== 0) {
+        // Always move at least one point so scores actually converge
+        const step = Math.sign(distance) * Math.max(1, Math.floor(Math.abs(distance) * this.DECAY_RATE));
+        this.adjust(friend.friend_domain, step, 'decay');
+        stats.decayed++;
+      }
+    }
+
+    if (stats.penalized > 0 || stats.decayed > 0) {
+      this.logger.info('⚖️ Reputation maintenance completed', stats);
+    }
+
+    return stats;
+  }
+
+  /**
+   * Current score and recent history for a friend (newest first)
+   */
+  getReputation(friendDomain: string): { friendDomain: string; score: number; baseline: number; history: ReputationChange[] } | null {
+    const friendship = this.database.prepare(`
+      SELECT trust_score FROM friendships WHERE friend_domain = ?
+    `).get(friendDomain) as { trust_score: number } | undefined;
+
+    if (!friendship) {
+      return null;
+    }
+
+    const rows = this.database.prepare(`
+      SELECT delta, score_after, reason, note, created_at
+      FROM reputation_history
+      WHERE friend_domain = ?
+      ORDER BY id DESC
+      LIMIT ?
+    `).all(friendDomain, this.MAX_HISTORY) as any[];
+
+    return {
+      friendDomain,
+      score: friendship.trust_score ?? this.BASELINE_SCORE,
+      baseline: this.BASELINE_SCORE,
+      history: rows.map(row => ({
+        delta: row.delta,
+        scoreAfter: row.score_after,
+        reason: row.reason,
+        note: row.note || undefined,
+        createdAt: row.created_at
+      }))
+    };
+  }
+}
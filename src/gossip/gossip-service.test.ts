@@ -0,0 +1,65 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { GossipService } from './gossip-service.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+import type { BotNetConfig } from '../../index.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('GossipService feed pages', () => {
+  let db: Database.Database;
+  let gossip: GossipService;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    gossip = new GossipService(db, { botDomain: 'botnet.bob.com' } as BotNetConfig, mockLogger);
+    for (let i = 1; i <= 5; i++) {
+      insertGossip(`own-${i}`, 'botnet.bob.com');
+    }
+    insertGossip('theirs', 'botnet.alice.com');
+  });
+
+  const insertGossip = (messageId: string, source: string) => db.prepare(`
+    INSERT INTO gossip_messages (message_id, source_bot_id, content) VALUES (?, ?, ?)
+  `).run(messageId, source, `Gossip ${messageId}`);
+
+  const ids = (page: { entries: any[] }) => page.entries.map(entry => entry.message_id);
+
+  it('pages our own gossip newest first', () => {
+    const first = gossip.getFeedPage(2);
+    expect(ids(first)).toEqual(['own-5', 'own-4']);
+    expect(first.nextCursor).toBeDefined();
+
+    const second = gossip.getFeedPage(2, first.nextCursor, first.snapshot);
+    expect(ids(second)).toEqual(['own-3', 'own-2']);
+
+    const last = gossip.getFeedPage(2, second.nextCursor, second.snapshot);
+    expect(ids(last)).toEqual(['own-1']);
+    expect(last.nextCursor).toBeUndefined();
+  });
+
+  it('keeps a pagination session pinned to its snapshot', () => {
+    const first = gossip.getFeedPage(2);
+    insertGossip('own-6', 'botnet.bob.com');
+
+    // Later pages neither repeat nor pick up the post written mid-session
+    const second = gossip.getFeedPage(2, first.nextCursor, first.snapshot);
+    expect(second.snapshot).toBe(first.snapshot);
+    expect(ids(second)).toEqual(['own-3', 'own-2']);
+
+    // A fresh session starts at the new post
+    expect(ids(gossip.getFeedPage(2))).toEqual(['own-6', 'own-5']);
+  });
+
+  it('returns an empty page when we have not posted', () => {
+    db.prepare(`DELETE FROM gossip_messages WHERE source_bot_id = 'botnet.bob.com'`).run();
+    expect(gossip.getFeedPage(2)).toEqual({ entries: [], snapshot: 0, nextCursor: undefined });
+  });
+});
@@ -4,6 +4,7 @@ import type Database from "better-sqlite3";
 import type { BotNetConfig } from "../../index.js";
 import type { Logger } from "../logger.js";
 import { RateLimiter } from "../rate-limiter.js";
+import { BlockListService } from "../friendship/block-list-service.js";
 
 export interface GossipMessage {
   id: number;
@@ -18,6 +19,35 @@ export interface GossipMessage {
   metadata?: any;
 }
 
+export interface GossipDigestBucket {
+  hour: string; // UTC hour of the origin timestamp, e.g. 2026-10-14T09
+  count: number;
+  hash: string; // Truncated SHA-256 of the sorted message IDs in the hour
+}
+
+export interface GossipQuote {
+  messageId: string;
+  source: string;
+  contentHash: string; // SHA-256 of the quoted content at quote time
+}
+
+export interface ResolvedQuote extends GossipQuote {
+  content?: string;
+  verified: boolean | null; // null when the original is not available locally
+}
+
+export interface GossipThreadNode {
+  message_id: string;
+  source: string;
+  content: string;
+  category?: string;
+  created_at: string;
+  reply_count: number;
+  reactions: Record<string, number>;
+  replies: GossipThreadNode[];
+  truncated?: boolean; // More replies exist than are included (depth limit or page size); fetch this node's thread
+}
+
 export class GossipService {
   private rateLimiter: RateLimiter;
 
@@ -28,10 +58,32 @@ export class GossipService {
   private readonly CLEANUP_ANONYMOUS_DAYS = 1;     // 1 day retention for anonymous
   private readonly MAX_GOSSIP_LENGTH = 300;        // Shorter messages for better context fit
 
+  // Reserved category for node-operator notices (maintenance windows, policy changes), pinned in feeds
+  static readonly ANNOUNCEMENT_CATEGORY = 'announcement';
+
+  // Loop prevention: gossip carries a hop count, a TTL (max hops) and the nodes it passed through
+  static readonly DEFAULT_TTL = 3;
+  private static readonly MAX_TTL = 8; // Peers can't ask for more than this
+  private readonly SEEN_CACHE_SIZE = 5000; // Message IDs remembered after the rows themselves are cleaned up
+
+  // Anti-entropy: hourly digests are compared well inside gossip retention, so cleanup timing can't cause mismatches
+  private readonly DIGEST_HOURS = 12;
+  private readonly MAX_DIGEST_IDS = 500; // IDs listed for mismatched buckets per digest exchange
+  static readonly MAX_BACKFILL = 100; // Messages served per backfill request
+
+  // Threads: gossip quoting a message is a reply to it
+  static readonly MAX_THREAD_DEPTH = 5;
+  static readonly MAX_THREAD_PAGE = 50; // Direct replies per page, and nested replies per node
+
+  // A fixed set, so reactions can't carry free text past the content limits
+  static readonly REACTIONS = ['👍', '❤️', '😂', '😮', '😢', '🎉', '🦞'];
+  private seen: Map<string, number> = new Map();
+
   constructor(
     private db: Database.Database,
     private config: BotNetConfig,
-    private logger: Logger
+    private logger: Logger,
+    private blockList?: BlockListService
   ) {
     this.rateLimiter = new RateLimiter(logger, 60 * 1000, 5); // 5 gossips per minute (LLM-friendly)
   }
@@ -137,17 +189,27 @@ export class GossipService {
     
     const received: string[] = [];
     const duplicates: string[] = [];
+    const dropped: string[] = [];
     
     for (const message of messages) {
       const messageId = message.message_id || uuidv4();
+
+      if (this.blockList?.isBlocked(source_bot_id || message.source_bot_id || '')) {
+        continue;
+      }
       
-      // Check if message already exists
-      const existing = this.db.prepare("SELECT 1 FROM gossip_messages WHERE message_id = ?").get(messageId);
-      
-      if (existing) {
+      // Check if we've seen this message already (cache first, it outlives gossip cleanup)
+      if (this.isSeen(messageId)) {
         duplicates.push(messageId);
         continue;
       }
+
+      const route = this.routeOf(message, source_bot_id);
+      if (!route) {
+        dropped.push(messageId);
+        this.markSeen(messageId);
+        continue;
+      }
       
       // Store message
       const stmt = this.db.prepare(`
@@ -163,10 +225,16 @@ export class GossipService {
         message.content,
         message.category,
         message.confidence_score || 70,
-        JSON.stringify(message.metadata || {})
+        JSON.stringify({
+          ...(message.metadata || {}),
+          ...(message.quote ? { quote: message.quote } : {}),
+          trace: { via: 'exchange', deliveredBy: source_bot_id || null, originCreatedAt: message.created_at || null },
+          route
+        })
       );
       
       received.push(messageId);
+      this.markSeen(messageId);
     }
     
     // Update friendship last seen if applicable
@@ -180,7 +248,8 @@ export class GossipService {
     
     this.logger.info("Processed gossip exchange", {
       received: received.length,
-      duplicates: duplicates.length
+      duplicates: duplicates.length,
+      dropped: dropped.length
     });
     
     // Return our recent messages for exchange
@@ -190,9 +259,132 @@ export class GossipService {
       success: true,
       received: received.length,
       duplicates: duplicates.length,
+      dropped: dropped.length,
       messages: ourMessages
     };
   }
+
+  /**
+   * Hop count, TTL and seen-by for an incoming message, or null when it has run out of hops or looped back to us
+   */
+  private routeOf(message: any, deliveredBy?: string): { hops: number; ttl: number; seenBy: string[] } | null {
+    const hops = (Number.isInteger(message.hops) && message.hops >= 0 ? message.hops : 0) + 1;
+    const ttl = Number.isInteger(message.ttl) && message.ttl > 0 ? Math.min(message.ttl, GossipService.MAX_TTL) : GossipService.DEFAULT_TTL;
+    const seenBy: string[] = Array.isArray(message.seen_by) ? message.seen_by.filter((node: any) => typeof node === 'string').slice(0, GossipService.MAX_TTL) : [];
+    if (deliveredBy && !seenBy.includes(deliveredBy)) {
+      seenBy.push(deliveredBy);
+    }
+
+    if (hops > ttl || seenBy.includes(this.config.botDomain)) {
+      return null;
+    }
+    return { hops, ttl, seenBy };
+  }
+
+  /**
+   * Per-hour count + hash of the gossip we hold, bucketed by origin time so every node buckets a message alike
+   */
+  getDigest(): GossipDigestBucket[] {
+    return [...this.getDigestIds().entries()].map(([hour, ids]) => ({
+      hour,
+      count: ids.length,
+      hash: createHash('sha256').update(ids.join('\n')).digest('hex').substring(0, 16)
+    }));
+  }
+
+  /**
+   * Compare a peer's digest with ours; for every bucket that differs, list our message IDs so the peer can backfill
+   */
+  compareDigest(remote: GossipDigestBucket[]): { buckets: GossipDigestBucket[]; mismatched: Array<{ hour: string; ids: string[] }> } {
+    const theirs = new Map(remote.map(bucket => [bucket.hour, bucket.hash]));
+    const ids = this.getDigestIds();
+    const buckets = this.getDigest();
+
+    const mismatched: Array<{ hour: string; ids: string[] }> = [];
+    let listed = 0;
+    for (const bucket of buckets) {
+      if (theirs.get(bucket.hour) === bucket.hash || listed >= this.MAX_DIGEST_IDS) {
+        continue;
+      }
+      const hourIds = ids.get(bucket.hour)!.slice(0, this.MAX_DIGEST_IDS - listed);
+      listed += hourIds.length;
+      mismatched.push({ hour: bucket.hour, ids: hourIds });
+    }
+    return { buckets, mismatched };
+  }
+
+  /**
+   * IDs from a peer's digest that we have never seen
+   */
+  filterUnseen(messageIds: string[]): string[] {
+    return [...new Set(messageIds)].filter(messageId => typeof messageId === 'string' && !this.isSeen(messageId));
+  }
+
+  /**
+   * Messages requested by a peer after a digest mismatch, in exchange format, carrying their route on from here
+   */
+  getBackfill(messageIds: string[]): any[] {
+    if (!messageIds.length) {
+      return [];
+    }
+    const ids = messageIds.slice(0, GossipService.MAX_BACKFILL);
+    const rows = this.db.prepare(`
+      SELECT message_id, content, category, confidence_score, created_at, metadata
+      FROM gossip_messages
+      WHERE message_id IN (${ids.map(() => '?').join(', ')})
+    `).all(...ids) as GossipMessage[];
+
+    return rows.map(msg => {
+      const metadata = this.parseMetadata(msg.metadata);
+      return {
+        message_id: msg.message_id,
+        content: msg.content,
+        category: msg.category,
+        confidence_score: msg.confidence_score,
+        created_at: metadata.trace?.originCreatedAt || msg.created_at,
+        hops: metadata.route?.hops ?? 0,
+        ttl: metadata.route?.ttl ?? GossipService.DEFAULT_TTL,
+        seen_by: [...new Set([...(metadata.route?.seenBy || []), this.config.botDomain])],
+        ...(metadata.quote ? { quote: metadata.quote } : {})
+      };
+    });
+  }
+
+  private getDigestIds(): Map<string, string[]> {
+    const rows = this.db.prepare(`
+      SELECT message_id, strftime('%Y-%m-%dT%H', origin) AS hour
+      FROM (
+        SELECT message_id, datetime(COALESCE(json_extract(metadata, '$.trace.originCreatedAt'), created_at)) AS origin
+        FROM gossip_messages
+      )
+      WHERE origin >= datetime('now', ?)
+      ORDER BY hour, message_id
+    `).all(`-${this.DIGEST_HOURS} hours`) as Array<{ message_id: string; hour: string }>;
+
+    const ids = new Map<string, string[]>();
+    for (const row of rows) {
+      ids.set(row.hour, [...(ids.get(row.hour) || []), row.message_id]);
+    }
+    return ids;
+  }
+
+  private isSeen(messageId: string): boolean {
+    if (this.seen.has(messageId)) {
+      // Refresh recency
+      this.markSeen(messageId);
+      return true;
+    }
+    return !!this.db.prepare("SELECT 1 FROM gossip_messages WHERE message_id = ?").get(messageId);
+  }
+
+  private markSeen(messageId: string): void {
+    this.seen.delete(messageId);
+    this.seen.set(messageId, Date.now());
+    if (this.seen.size > this.SEEN_CACHE_SIZE) {
+      // Map keeps insertion order - the first key is the least recently seen
+      this.seen.delete(this.seen.keys().next().value!);
+    }
+  }
   
   async handleQuery(request: any): Promise<any> {
     const { category, since, limit = 10 } = request;
@@ -229,6 +421,24 @@ export class GossipService {
     };
   }
   
+  /**
+   * What handleExchange would store from this batch, without storing anything (shadow peers)
+   */
+  previewExchange(request: any): { wouldStore: string[]; duplicates: string[]; blocked: string[] } {
+    const preview = { wouldStore: [] as string[], duplicates: [] as string[], blocked: [] as string[] };
+    for (const message of Array.isArray(request?.messages) ? request.messages : []) {
+      const messageId = message.message_id || '(no id)';
+      if (this.blockList?.isBlocked(request.source_bot_id || message.source_bot_id || '')) {
+        preview.blocked.push(messageId);
+      } else if (this.seen.has(messageId) || this.db.prepare("SELECT 1 FROM gossip_messages WHERE message_id = ?").get(messageId)) {
+        preview.duplicates.push(messageId);
+      } else {
+        preview.wouldStore.push(messageId);
+      }
+    }
+    return preview;
+  }
+
   async exchangeMessages(request: any): Promise<any> {
     return this.handleExchange(request);
   }
@@ -329,9 +539,209 @@ export class GossipService {
     };
   }
   
+  /**
+   * One page of our own gossip, newest first, pinned to a storage sequence number (the row id).
+   * The first page takes the current max id as its snapshot; later pages pass it back with the
+   * id cursor of the last entry, so gossip written meanwhile doesn't shift pages
+   */
+  getFeedPage(limit: number, cursor?: number, snapshot?: number): { entries: any[]; snapshot: number; nextCursor?: number } {
+    const sourceId = this.getGossipSourceId();
+    const pinned = snapshot ?? ((this.db.prepare(`
+      SELECT MAX(id) AS seq FROM gossip_messages WHERE source_bot_id = ?
+    `).get(sourceId) as { seq: number | null }).seq || 0);
+
+    const rows = this.db.prepare(`
+      SELECT id, message_id, content, category, confidence_score, created_at, metadata, reply_count
+      FROM gossip_messages
+      WHERE source_bot_id = ? AND id <= ? AND (? IS NULL OR id < ?)
+      ORDER BY id DESC
+      LIMIT ?
+    `).all(sourceId, pinned, cursor ?? null, cursor ?? null, limit + 1) as Array<GossipMessage & { id: number; reply_count: number }>;
+
+    const page = rows.slice(0, limit);
+    const reactions = this.getReactionCounts(page.map(msg => msg.message_id));
+    const entries = page.map(msg => {
+      const quote = this.parseMetadata(msg.metadata).quote;
+      return {
+        message_id: msg.message_id,
+        content: msg.content,
+        category: msg.category,
+        confidence_score: msg.confidence_score,
+        created_at: msg.created_at,
+        reply_count: msg.reply_count,
+        reactions: reactions.get(msg.message_id) || {},
+        ...(quote ? { quote } : {})
+      };
+    });
+
+    return {
+      entries,
+      snapshot: pinned,
+      nextCursor: rows.length > limit ? page[page.length - 1].id : undefined
+    };
+  }
+
+  /**
+   * Our own gossip written after a sequence number, oldest first (for the event stream).
+   * Without a sequence number, returns nothing and the current one to continue from
+   */
+  getOwnSince(afterId: number | undefined, limit: number = 50): { entries: any[]; cursor: number } {
+    const sourceId = this.getGossipSourceId();
+    if (afterId === undefined) {
+      const latest = this.db.prepare(`
+        SELECT MAX(id) AS seq FROM gossip_messages WHERE source_bot_id = ?
+      `).get(sourceId) as { seq: number | null };
+      return { entries: [], cursor: latest.seq || 0 };
+    }
+
+    const rows = this.db.prepare(`
+      SELECT id, message_id, content, category, confidence_score, created_at, metadata
+      FROM gossip_messages
+      WHERE source_bot_id = ? AND id > ?
+      ORDER BY id ASC
+      LIMIT ?
+    `).all(sourceId, afterId, limit) as GossipMessage[];
+
+    return {
+      entries: rows.map(msg => {
+        const quote = this.parseMetadata(msg.metadata).quote;
+        return {
+          seq: msg.id,
+          message_id: msg.message_id,
+          content: msg.content,
+          category: msg.category,
+          created_at: msg.created_at,
+          ...(quote ? { quote } : {})
+        };
+      }),
+      cursor: rows.length ? rows[rows.length - 1].id : afterId
+    };
+  }
+
+  /**
+   * Reply tree under a gossip (or a direct message someone quoted). Direct replies are paged oldest first
+   * by row id; nested replies go `depth` levels down, and nodes with more replies than shown are marked truncated
+   */
+  getThread(messageId: string, depth: number = 3, limit: number = 20, cursor?: number): { root: any; replies: GossipThreadNode[]; nextCursor?: number } | null {
+    const maxDepth = Math.min(Math.max(1, Math.floor(depth)), GossipService.MAX_THREAD_DEPTH);
+    const pageSize = Math.min(Math.max(1, Math.floor(limit)), GossipService.MAX_THREAD_PAGE);
+
+    const row = this.db.prepare(`
+      SELECT message_id, source_bot_id, content, category, created_at, reply_count
+      FROM gossip_messages WHERE message_id = ?
+    `).get(messageId) as any;
+    const rows = this.getReplyRows(messageId, pageSize + 1, cursor);
+    const original = row ? undefined : this.findOriginal(messageId);
+    if (!row && !original && !rows.length) {
+      return null;
+    }
+
+    const page = rows.slice(0, pageSize);
+    const root = row
+      ? {
+          message_id: row.message_id,
+          source: row.source_bot_id,
+          content: row.content,
+          category: row.category,
+          created_at: row.created_at,
+          reply_count: row.reply_count,
+          reactions: this.getReactionCounts([row.message_id]).get(row.message_id) || {}
+        }
+      : {
+          message_id: messageId,
+          source: original?.source,
+          content: original?.content,
+          // Direct messages and cleaned-up gossip carry no counter
+          reply_count: (this.db.prepare("SELECT COUNT(*) AS count FROM gossip_messages WHERE quote_of = ?").get(messageId) as { count: number }).count,
+          available: !!original
+        };
+
+    return {
+      root,
+      replies: page.map(reply => this.toThreadNode(reply, maxDepth - 1, pageSize)),
+      nextCursor: rows.length > pageSize ? page[page.length - 1].id : undefined
+    };
+  }
+
+  private getReplyRows(messageId: string, limit: number, cursor?: number): any[] {
+    return this.db.prepare(`
+      SELECT id, message_id, source_bot_id, content, category, created_at, reply_count
+      FROM gossip_messages
+      WHERE quote_of = ? AND (? IS NULL OR id > ?) AND ${BlockListService.excludeClause('source_bot_id', true)}
+      ORDER BY id ASC
+      LIMIT ?
+    `).all(messageId, cursor ?? null, cursor ?? null, limit);
+  }
+
+  private toThreadNode(row: any, depth: number, pageSize: number): GossipThreadNode {
+    const replies = depth > 0 && row.reply_count > 0
+      ? this.getReplyRows(row.message_id, pageSize).map(reply => this.toThreadNode(reply, depth - 1, pageSize))
+      : [];
+    return {
+      message_id: row.message_id,
+      source: row.source_bot_id,
+      content: row.content,
+      category: row.category,
+      created_at: row.created_at,
+      reply_count: row.reply_count,
+      reactions: this.getReactionCounts([row.message_id]).get(row.message_id) || {},
+      replies,
+      // Blocked and muted replies count but aren't shown, so this can over-report
+      ...(row.reply_count > replies.length ? { truncated: true } : {})
+    };
+  }
+
+  /**
+   * Add or take back a reaction to a gossip we hold. Returns null for gossip we don't have,
+   * otherwise whether anything changed and the gossip's reaction counts
+   */
+  react(messageId: string, reactor: string, reaction: string, remove: boolean = false): { changed: boolean; reactions: Record<string, number> } | null {
+    if (!GossipService.REACTIONS.includes(reaction)) {
+      throw new Error(`Unsupported reaction "${reaction}" (use one of ${GossipService.REACTIONS.join(' ')})`);
+    }
+    if (!this.db.prepare("SELECT 1 FROM gossip_messages WHERE message_id = ?").get(messageId)) {
+      return null;
+    }
+
+    const result = remove
+      ? this.db.prepare(`
+          DELETE FROM gossip_reactions WHERE message_id = ? AND reactor = ? AND reaction = ?
+        `).run(messageId, reactor, reaction)
+      : this.db.prepare(`
+          INSERT OR IGNORE INTO gossip_reactions (message_id, reactor, reaction) VALUES (?, ?, ?)
+        `).run(messageId, reactor, reaction);
+
+    return {
+      changed: result.changes > 0,
+      reactions: this.getReactionCounts([messageId]).get(messageId) || {}
+    };
+  }
+
+  /**
+   * Reaction counts per gossip, e.g. { "👍": 3, "🎉": 1 }; blocked and muted reactors aren't counted
+   */
+  getReactionCounts(messageIds: string[]): Map<string, Record<string, number>> {
+    const counts = new Map<string, Record<string, number>>();
+    if (!messageIds.length) {
+      return counts;
+    }
+    const rows = this.db.prepare(`
+      SELECT message_id, reaction, COUNT(*) AS count
+      FROM gossip_reactions
+      WHERE message_id IN (${messageIds.map(() => '?').join(', ')}) AND ${BlockListService.excludeClause('reactor', true)}
+      GROUP BY message_id, reaction
+      ORDER BY count DESC
+    `).all(...messageIds) as Array<{ message_id: string; reaction: string; count: number }>;
+
+    for (const row of rows) {
+      counts.set(row.message_id, { ...(counts.get(row.message_id) || {}), [row.reaction]: row.count });
+    }
+    return counts;
+  }
+
   async getRecentMessages(limit: number = 10): Promise<any[]> {
     const stmt = this.db.prepare(`
-      SELECT message_id, content, category, confidence_score, created_at
+      SELECT message_id, content, category, confidence_score, created_at, metadata
       FROM gossip_messages
       WHERE source_bot_id = ?
       ORDER BY created_at DESC
@@ -341,13 +751,174 @@ export class GossipService {
     const sourceId = this.getGossipSourceId();
     const messages = stmt.all(sourceId, limit) as GossipMessage[];
     
-    return messages.map(msg => ({
-      message_id: msg.message_id,
-      content: msg.content,
-      category: msg.category,
-      confidence_score: msg.confidence_score,
-      created_at: msg.created_at
-    }));
+    return messages.map(msg => {
+      const quote = this.parseMetadata(msg.metadata).quote;
+      return {
+        message_id: msg.message_id,
+        content: msg.content,
+        category: msg.category,
+        confidence_score: msg.confidence_score,
+        created_at: msg.created_at,
+        // Our own gossip starts its route here
+        hops: 0,
+        ttl: GossipService.DEFAULT_TTL,
+        seen_by: [this.config.botDomain],
+        ...(quote ? { quote } : {})
+      };
+    });
+  }
+
+  /**
+   * Content hash used to pin quoted messages
+   */
+  static hashContent(content: string): string {
+    return createHash("sha256").update(content).digest("hex");
+  }
+
+  /**
+   * Build a quote reference for a locally known gossip or direct message
+   */
+  createQuote(messageId: string): GossipQuote {
+    const original = this.findOriginal(messageId);
+    if (!original) {
+      throw new Error(`Quoted message not found locally: ${messageId}`);
+    }
+    return {
+      messageId,
+      source: original.source,
+      contentHash: GossipService.hashContent(original.content)
+    };
+  }
+
+  /**
+   * Resolve a quote against the local copy of the original and verify its hash
+   */
+  resolveQuote(quote: GossipQuote): ResolvedQuote {
+    const original = this.findOriginal(quote.messageId);
+    if (!original) {
+      return { ...quote, verified: null };
+    }
+
+    const verified = GossipService.hashContent(original.content) === quote.contentHash;
+    return {
+      ...quote,
+      // Never show content that doesn't match what was quoted
+      content: verified ? original.content : undefined,
+      verified
+    };
+  }
+
+  /**
+   * Get a gossip authored by this node (served to peers resolving a quote)
+   */
+  getOwnMessage(messageId: string): any | null {
+    const row = this.db.prepare(`
+      SELECT message_id, content, category, created_at, metadata
+      FROM gossip_messages
+      WHERE message_id = ? AND source_bot_id = ?
+    `).get(messageId, this.getGossipSourceId()) as any;
+
+    if (!row) {
+      return null;
+    }
+
+    const quote = this.parseMetadata(row.metadata).quote;
+    return {
+      message_id: row.message_id,
+      source_bot_id: this.getGossipSourceId(),
+      content: row.content,
+      category: row.category,
+      created_at: row.created_at,
+      ...(quote ? { quote } : {})
+    };
+  }
+
+  /**
+   * Cache a message fetched from its origin node, only if it matches the quoted hash
+   */
+  cacheReferencedMessage(quote: GossipQuote, message: any): boolean {
+    if (!message || typeof message.content !== 'string' || GossipService.hashContent(message.content) !== quote.contentHash) {
+      return false;
+    }
+
+    this.checkGossipLimits();
+    this.db.prepare(`
+      INSERT OR IGNORE INTO gossip_messages (
+        message_id, source_bot_id, content, category, confidence_score, metadata
+      ) VALUES (?, ?, ?, ?, ?, ?)
+    `).run(
+      quote.messageId,
+      quote.source,
+      message.content,
+      message.category || 'general',
+      75,
+      JSON.stringify({
+        source: 'reference_fetch',
+        fetchedAt: new Date().toISOString(),
+        trace: { via: 'reference_fetch', deliveredBy: quote.source }
+      })
+    );
+
+    return true;
+  }
+
+  /**
+   * How a gossip reached us: origin, delivering peer, timing and quote verification
+   */
+  getTrace(messageId: string): any | null {
+    const row = this.db.prepare(`
+      SELECT message_id, source_bot_id, category, confidence_score, created_at, received_at, metadata
+      FROM gossip_messages WHERE message_id = ?
+    `).get(messageId) as any;
+
+    if (!row) {
+      return null;
+    }
+
+    const metadata = this.parseMetadata(row.metadata);
+    const trace = metadata.trace || {};
+    const local = row.source_bot_id === this.getGossipSourceId();
+    const originCreatedAt = trace.originCreatedAt ? Date.parse(trace.originCreatedAt) : NaN;
+    const receivedAt = Date.parse(`${String(row.received_at).replace(' ', 'T')}Z`);
+
+    return {
+      messageId: row.message_id,
+      origin: row.source_bot_id,
+      local,
+      via: local ? 'local' : trace.via || 'unknown',
+      deliveredBy: trace.deliveredBy || undefined,
+      originCreatedAt: trace.originCreatedAt || row.created_at,
+      receivedAt: row.received_at,
+      propagationDelayMs: !isNaN(originCreatedAt) && !isNaN(receivedAt) ? Math.max(0, receivedAt - originCreatedAt) : undefined,
+      confidence: row.confidence_score,
+      category: row.category,
+      blocked: this.blockList ? this.blockList.get(row.source_bot_id)?.kind || null : null,
+      quote: metadata.quote ? { ...metadata.quote, ...this.resolveQuote(metadata.quote) } : undefined
+    };
+  }
+
+  private findOriginal(messageId: string): { source: string; content: string } | undefined {
+    const gossip = this.db.prepare(`
+      SELECT source_bot_id AS source, content FROM gossip_messages WHERE message_id = ?
+    `).get(messageId) as { source: string; content: string } | undefined;
+
+    if (gossip) {
+      return gossip;
+    }
+
+    return this.db.prepare(`
+      SELECT from_domain AS source, content FROM messages WHERE message_id = ?
+    `).get(messageId) as { source: string; content: string } | undefined;
+  }
+
+  private parseMetadata(metadata: any): any {
+    if (!metadata) return {};
+    if (typeof metadata !== 'string') return metadata;
+    try {
+      return JSON.parse(metadata);
+    } catch {
+      return {};
+    }
   }
   
   private generateAnonymousId(content: string): string {
@@ -480,9 +1051,12 @@ export class GossipService {
   /**
    * Share gossip with known friends
    */
-  async shareGossip(content: string, category: string = 'general', tags: string[] = [], clientIP?: string): Promise<{ messageId: string; sharedWithFriends: number; message: string }> {
+  async shareGossip(content: string, category: string = 'general', tags: string[] = [], clientIP?: string, quoteOf?: string): Promise<{ messageId: string; sharedWithFriends: number; message: string; quote?: GossipQuote }> {
     // Validate content length for LLM context efficiency
     this.validateGossipContent(content);
+
+    // Pin the quoted message by hash before anything is stored
+    const quote = quoteOf ? this.createQuote(quoteOf) : undefined;
     
     // Rate limiting
     const rateLimitKey = clientIP || this.config.botDomain;
@@ -517,7 +1091,8 @@ export class GossipService {
       tags,
       sharedWith: friends.map(f => f.friend_domain),
       shareType: 'friends_only',
-      sharedAt: new Date().toISOString()
+      sharedAt: new Date().toISOString(),
+      ...(quote ? { quote } : {})
     });
 
     shareStmt.run(messageId, sourceId, content, category, 85, metadata);
@@ -533,7 +1108,8 @@ export class GossipService {
     return {
       messageId,
       sharedWithFriends: friends.length,
-      message: `Gossip shared with ${friends.length} friend(s)`
+      message: `Gossip shared with ${friends.length} friend(s)`,
+      ...(quote ? { quote } : {})
     };
   }
 
@@ -551,8 +1127,8 @@ export class GossipService {
       throw new Error('Rate limit exceeded for reviewing gossips. Please try again later.');
     }
 
-    // Build query with optional category filter
-    let whereClause = '1=1';
+    // Build query with optional category filter (blocked and muted sources, and replies in muted threads, are hidden)
+    let whereClause = `${BlockListService.excludeClause('source_bot_id', true)} AND ${BlockListService.mutedThreadClause('quote_of')}`;
     let params: any[] = [];
 
     if (category) {
@@ -563,14 +1139,19 @@ export class GossipService {
     const gossipStmt = this.db.prepare(`
       SELECT 
         message_id, source_bot_id, content, category, 
-        confidence_score, created_at, metadata
+        confidence_score, created_at, metadata, reply_count
       FROM gossip_messages
       WHERE ${whereClause}
-      ORDER BY created_at DESC
+      ORDER BY (category = ?) DESC, created_at DESC
       LIMIT ?
     `);
     
-    const gossips = gossipStmt.all(...params, limit) as any[];
+    const gossips = (gossipStmt.all(...params, GossipService.ANNOUNCEMENT_CATEGORY, limit) as any[]).map(gossip => {
+      const quote = this.parseMetadata(gossip.metadata).quote as GossipQuote | undefined;
+      return { ...gossip, quote: quote ? this.resolveQuote(quote) : undefined };
+    });
+
+    const reactions = this.getReactionCounts(gossips.map(gossip => gossip.message_id));
 
     // Combine gossip text for easy reading
     const combinedTexts = gossips.map(gossip => {
@@ -588,7 +1169,19 @@ export class GossipService {
       const timestamp = new Date(gossip.created_at).toLocaleString();
       const confidence = gossip.confidence_score ? ` (${gossip.confidence_score}% confidence)` : '';
       
-      return `[${timestamp}] ${source}${confidence}: ${gossip.content}`;
+      let quoted = '';
+      if (gossip.quote) {
+        quoted = gossip.quote.verified
+          ? `\n  > quoting ${gossip.quote.source}: ${gossip.quote.content}`
+          : gossip.quote.verified === false
+            ? `\n  > quote of ${gossip.quote.messageId} does NOT match the original`
+            : `\n  > quote of ${gossip.quote.messageId} (original not available locally)`;
+      }
+      
+      const pinned = gossip.category === GossipService.ANNOUNCEMENT_CATEGORY ? '📌 ANNOUNCEMENT ' : '';
+      const replies = gossip.reply_count ? `\n  ↳ ${gossip.reply_count} repl${gossip.reply_count === 1 ? 'y' : 'ies'} (thread ${gossip.message_id})` : '';
+      const reacted = Object.entries(reactions.get(gossip.message_id) || {}).map(([reaction, count]) => `${reaction} ${count}`).join('  ');
+      return `${pinned}[${timestamp}] ${source}${confidence}: ${gossip.content}${quoted}${replies}${reacted ? `\n  ${reacted}` : ''}`;
     });
 
     const combinedText = combinedTexts.join('\n\n');
@@ -619,7 +1212,11 @@ export class GossipService {
         content: gossip.content,
         category: gossip.category,
         confidence: gossip.confidence_score,
-        timestamp: gossip.created_at
+        timestamp: gossip.created_at,
+        pinned: gossip.category === GossipService.ANNOUNCEMENT_CATEGORY,
+        replyCount: gossip.reply_count,
+        reactions: reactions.get(gossip.message_id) || {},
+        ...(gossip.quote ? { quote: gossip.quote } : {})
       })),
       combinedText,
       summary: {
@@ -639,6 +1236,11 @@ export class GossipService {
     try {
       // Validate incoming gossip content length
       this.validateGossipContent(content);
+
+      if (this.blockList?.isBlocked(fromDomain)) {
+        this.logger.info('🚫 Dropped gossip from blocked domain', { fromDomain });
+        return { gossipId: uuidv4(), status: 'received' };
+      }
       
       this.checkGossipLimits();
       
@@ -662,7 +1264,8 @@ export class GossipService {
         JSON.stringify({ 
           source: 'federation',
           tags,
-          trust_level: 'friend'
+          trust_level: 'friend',
+          trace: { via: 'federation', deliveredBy: fromDomain }
         })
       );
       
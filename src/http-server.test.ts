@@ -0,0 +1,162 @@
+import { describe, it, expect, beforeEach, afterEach, jest } from '@jest/globals';
+import http from 'http';
+import type { AddressInfo } from 'net';
+import { createBotNetServer } from './http-server.js';
+import type { Logger } from './logger.js';
+import type { BotNetConfig } from '../index.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('GET /feeds/stream', () => {
+  let server: http.Server;
+  let port: number;
+  let getStreamEvents: jest.Mock<any>;
+  let clients: http.ClientRequest[];
+
+  const start = async (config: Partial<BotNetConfig> = {}) => {
+    getStreamEvents = jest.fn(() => ({
+      events: [{ type: 'post', data: { content: 'Hello' }, cursor: { posts: 1, messages: 0 } }],
+      cursor: { posts: 1, messages: 0 }
+    }));
+    const botnetService = {
+      getStreamEvents,
+      getLoadMonitor: () => ({ shouldShed: () => false, retryAfterSeconds: 5 })
+    };
+    server = createBotNetServer({
+      config: { botName: 'Bob', botDomain: 'botnet.bob.com', httpPort: 8080, publicFeedEnabled: true, trustedProxies: [], defaultLocale: 'en', ...config } as BotNetConfig,
+      logger: mockLogger,
+      botnetService: botnetService as any,
+      tokenService: {} as any
+    });
+    await new Promise<void>(resolve => server.listen(0, '127.0.0.1', resolve));
+    port = (server.address() as AddressInfo).port;
+  };
+
+  // Open a stream and resolve with the response once the first chunk arrives
+  const open = (headers: Record<string, string> = {}) => new Promise<{ res: http.IncomingMessage; first: string }>((resolve, reject) => {
+    const req = http.get({ host: '127.0.0.1', port, path: '/feeds/stream', headers }, res => {
+      res.setEncoding('utf8');
+      let body = '';
+      res.on('data', chunk => {
+        body += chunk;
+        if (body.includes('\n\n')) resolve({ res, first: body });
+      });
+      res.on('end', () => resolve({ res, first: body }));
+    });
+    req.on('error', reject);
+    clients.push(req);
+  });
+
+  beforeEach(() => {
+    clients = [];
+  });
+
+  afterEach(async () => {
+    clients.forEach(client => client.destroy());
+    if (server.listening) {
+      await new Promise(resolve => server.close(resolve));
+    }
+  });
+
+  it('streams events with resumable ids', async () => {
+    await start();
+    const { res } = await open();
+    expect(res.statusCode).toBe(200);
+    expect(res.headers['content-type']).toBe('text/event-stream; charset=utf-8');
+
+    let body = '';
+    await new Promise<void>(resolve => res.on('data', chunk => {
+      body += chunk;
+      if (body.includes('event: post')) resolve();
+    }));
+    expect(body).toContain('id: 1.0\nevent: post\ndata: {"content":"Hello"}\n\n');
+  });
+
+  it('caps streams per connecting address, whatever X-Forwarded-For says', async () => {
+    await start();
+    for (let i = 0; i < 5; i++) {
+      expect((await open({ 'X-Forwarded-For': `198.51.100.${i}` })).res.statusCode).toBe(200);
+    }
+    const refused = await open({ 'X-Forwarded-For': '198.51.100.99' });
+    expect(refused.res.statusCode).toBe(503);
+    expect(refused.first).toContain('Too many open streams');
+  });
+
+  it('believes X-Forwarded-For from a trusted proxy', async () => {
+    await start({ trustedProxies: ['127.0.0.1'] });
+    for (let i = 0; i < 6; i++) {
+      expect((await open({ 'X-Forwarded-For': `203.0.113.9, 198.51.100.${i}` })).res.statusCode).toBe(200);
+    }
+  });
+
+  it('ends open streams and stops polling when the server closes', async () => {
+    await start();
+    const { res } = await open();
+    const ended = new Promise(resolve => res.on('end', resolve));
+
+    await new Promise(resolve => server.close(resolve));
+    await ended;
+    const polls = getStreamEvents.mock.calls.length;
+    await new Promise(resolve => setTimeout(resolve, 2500));
+    expect(getStreamEvents.mock.calls.length).toBe(polls);
+  });
+});
+
+describe('GET /feeds/node.atom', () => {
+  let server: http.Server;
+  let port: number;
+  let getPublicFeed: jest.Mock<any>;
+
+  const get = (path: string) => new Promise<{ res: http.IncomingMessage; body: string }>((resolve, reject) => {
+    http.get({ host: '127.0.0.1', port, path }, res => {
+      res.setEncoding('utf8');
+      let body = '';
+      res.on('data', chunk => body += chunk);
+      res.on('end', () => resolve({ res, body }));
+    }).on('error', reject);
+  });
+
+  beforeEach(async () => {
+    getPublicFeed = jest.fn(async () => ({
+      entries: [{ message_id: 'own-5', content: 'Hello', created_at: '2026-01-01 00:00:00', reactions: {} }],
+      snapshot: 5,
+      nextCursor: 5
+    }));
+    server = createBotNetServer({
+      config: { botName: 'Bob', botDomain: 'botnet.bob.com', botDescription: 'Test bot', httpPort: 8080, publicFeedEnabled: true, trustedProxies: [], defaultLocale: 'en' } as BotNetConfig,
+      logger: mockLogger,
+      botnetService: { getPublicFeed, getLoadMonitor: () => ({ shouldShed: () => false, retryAfterSeconds: 5 }) } as any,
+      tokenService: {} as any
+    });
+    await new Promise<void>(resolve => server.listen(0, '127.0.0.1', resolve));
+    port = (server.address() as AddressInfo).port;
+  });
+
+  afterEach(async () => {
+    await new Promise(resolve => server.close(resolve));
+  });
+
+  it('links the next page with its snapshot and cursor', async () => {
+    const { res, body } = await get('/feeds/node.atom');
+    expect(res.statusCode).toBe(200);
+    expect(res.headers['x-botnet-snapshot']).toBe('5');
+    expect(res.headers['link']).toBe('</feeds/node.atom?snapshot=5&cursor=5>; rel="next"');
+    expect(body).toContain('rel="next"');
+
+    await get('/feeds/node.atom?snapshot=5&cursor=5');
+    expect(getPublicFeed).toHaveBeenCalledWith(50, 5, 5);
+  });
+
+  it('rejects cursors that are not sequence numbers', async () => {
+    const { res, body } = await get('/feeds/node.atom?cursor=-1');
+    expect(res.statusCode).toBe(400);
+    expect(body).toContain('cursor and snapshot must be non-negative integers');
+    expect(getPublicFeed).not.toHaveBeenCalled();
+  });
+});
@@ -1,10 +1,13 @@
 import http from 'http';
+import zlib from 'zlib';
+import type { Readable } from 'stream';
 import { BotNetConfig } from '../index.js';
 import { BotNetService } from './service.js';
 import { AuthMiddleware, AuthLevel, AuthResult } from './auth/auth-middleware.js';
 import { TokenService } from './auth/token-service.js';
 import { MCPHandler } from './mcp/mcp-handler.js';
 import type { Logger } from './logger.js';
+import { negotiateLocale, translate, type Locale, type MessageKey } from './i18n.js';
 
 export interface BotNetServerOptions {
   config: BotNetConfig;
@@ -13,8 +16,103 @@ export interface BotNetServerOptions {
   tokenService: TokenService;
 }
 
+// MCP methods that can be refused with 503 when the node is overloaded
+const LOW_PRIORITY_METHODS = new Set([
+  'botnet.gossip.exchange',
+  'botnet.gossip.fetch',
+  'botnet.gossip.history',
+  'botnet.gossip.digest',
+  'botnet.gossip.backfill',
+  'botnet.gossip.thread',
+  'resources/list',
+  'resources/read'
+]);
+
+// MCP methods that don't write to storage, still served while the database is unavailable
+const READ_ONLY_METHODS = new Set([
+  'initialize',
+  'tools/list',
+  'resources/list',
+  'resources/read',
+  'botnet.profile',
+  'botnet.capabilities',
+  'botnet.ping',
+  'botnet.health',
+  'botnet.gossip.history',
+  'botnet.gossip.fetch',
+  'botnet.gossip.digest',
+  'botnet.gossip.backfill',
+  'botnet.gossip.thread',
+  'botnet.peers',
+  'botnet.channel.list'
+]);
+
+// MCP methods accepted without a node signature even when requireSignedFederation is on
+// (discovery, so peers can fetch our key, and abuse reports, which humans may send)
+const UNSIGNED_METHODS = new Set([
+  'initialize',
+  'tools/list',
+  'botnet.profile',
+  'botnet.capabilities',
+  'botnet.ping',
+  'botnet.health',
+  'botnet.abuse.report'
+]);
+
+// MCP methods that must be signed by the node named in source_bot_id, regardless of requireSignedFederation
+const SIGNATURE_REQUIRED_METHODS = new Set([
+  'botnet.channel.moderate',
+  'botnet.channel.moderation'
+]);
+
+function secondsUntilUtcMidnight(): number {
+  const now = new Date();
+  const midnight = Date.UTC(now.getUTCFullYear(), now.getUTCMonth(), now.getUTCDate() + 1);
+  return Math.ceil((midnight - now.getTime()) / 1000);
+}
+
+function sendSignatureRejected(res: http.ServerResponse, id: any, errorCode: string, details: string): void {
+  res.writeHead(401, { 'Content-Type': 'application/json' });
+  res.end(JSON.stringify({
+    jsonrpc: '2.0',
+    error: { code: -32001, message: 'Federation request signature rejected', data: { authLevel: 'DENIED', errorCode, details } },
+    id: id ?? null
+  }, null, 2));
+}
+
+function sendOverloaded(res: http.ServerResponse, retryAfterSeconds: number, id: any = null, message = 'Node temporarily overloaded, retry later'): void {
+  res.writeHead(503, { 'Content-Type': 'application/json', 'Retry-After': String(retryAfterSeconds) });
+  res.end(JSON.stringify({
+    jsonrpc: '2.0',
+    error: { code: -32603, message, data: { retryAfterSeconds } },
+    id
+  }));
+}
+
+// Server-Sent Events stream (/feeds/stream)
+const STREAM_POLL_MS = 2000;
+const STREAM_HEARTBEAT_MS = 25000; // Comment lines keep proxies from closing idle streams
+const MAX_STREAMS = 100;
+const MAX_STREAMS_PER_IP = 5;
+
+/**
+ * Address a per-IP limit can rely on: the connecting socket, or the nearest X-Forwarded-For hop when that socket is a trusted proxy
+ */
+function connectingIP(req: http.IncomingMessage, trustedProxies: string[]): string {
+  const normalize = (address: string) => address.trim().replace(/^::ffff:(?=\d+\.\d+\.\d+\.\d+$)/, '');
+  const socketIP = normalize(req.socket.remoteAddress || 'unknown');
+  const forwarded = req.headers['x-forwarded-for'];
+  if (!trustedProxies.includes(socketIP) || typeof forwarded !== 'string') {
+    return socketIP;
+  }
+  // The proxy appends the address it saw, so the last entry is the only one it vouches for
+  return normalize(forwarded.split(',').pop() || '') || socketIP;
+}
+
 export function createBotNetServer(options: BotNetServerOptions): http.Server {
   const { config, logger, botnetService, tokenService } = options;
+  const openStreams: Set<() => void> = new Set(); // End functions of open event streams
+  const streamsByIP: Map<string, number> = new Map();
   
   // Initialize AuthMiddleware
   const authMiddleware = new AuthMiddleware(tokenService, logger);
@@ -36,12 +134,27 @@ export function createBotNetServer(options: BotNetServerOptions): http.Server {
     const url = req.url || '';
     const method = req.method;
     
-    logger.info(`🐉 BotNet HTTP: ${method} ${url}`);
+    const startedAt = Date.now();
+    const timings: Record<string, number> = {}; // Phase durations in ms (auth, handler) for slow-request logs
+    
+    res.on('finish', () => {
+      const durationMs = Date.now() - startedAt;
+      const entry = { status: res.statusCode, durationMs };
+
+      if (durationMs >= config.slowRequestThresholdMs) {
+        logger.warn(`🐢 Slow request: ${method} ${url}`, { ...entry, timings, other: durationMs - Object.values(timings).reduce((a, b) => a + b, 0) });
+      } else if (res.statusCode >= 400) {
+        // Errors are always logged
+        logger.warn(`🐉 BotNet HTTP: ${method} ${url}`, entry);
+      } else if (Math.random() < config.requestLogSampleRate) {
+        logger.info(`🐉 BotNet HTTP: ${method} ${url}`, entry);
+      }
+    });
     
     // CORS headers for all responses
     res.setHeader('Access-Control-Allow-Origin', '*');
-    res.setHeader('Access-Control-Allow-Methods', 'GET, POST, OPTIONS');
-    res.setHeader('Access-Control-Allow-Headers', 'Content-Type, Authorization');
+    res.setHeader('Access-Control-Allow-Methods', 'GET, POST, DELETE, OPTIONS');
+    res.setHeader('Access-Control-Allow-Headers', 'Content-Type, Authorization, Last-Event-ID');
     
     // Handle OPTIONS preflight
     if (method === 'OPTIONS') {
@@ -56,15 +169,34 @@ export function createBotNetServer(options: BotNetServerOptions): http.Server {
     
     // Check if request is from a browser (wants HTML)
     const acceptsHtml = req.headers.accept?.includes('text/html');
-    const clientIP = req.headers['x-forwarded-for'] as string || req.socket.remoteAddress || 'unknown';
+    // Linked-data clients (AP bridges, crawlers) can ask for schema.org JSON-LD instead
+    const acceptsJsonLd = req.headers.accept?.includes('application/ld+json');
+    // Landing page and HTTP error messages follow Accept-Language, falling back to the operator's defaultLocale
+    const locale = negotiateLocale(req.headers['accept-language'], config.defaultLocale);
+    const t = (key: MessageKey, params?: Record<string, string>) => translate(locale, key, params);
+    // Crawler controls: X-Robots-Tag on HTML pages when indexing is disabled
+    const htmlHeaders: Record<string, string> = config.allowIndexing
+      ? { 'Content-Type': 'text/html' }
+      : { 'Content-Type': 'text/html', 'X-Robots-Tag': 'noindex, nofollow' };
+
+    // Dual-stack sockets report IPv4 clients as ::ffff:a.b.c.d - strip it so limits and logs see one address
+    const clientIP = (req.headers['x-forwarded-for'] as string || req.socket.remoteAddress || 'unknown').replace(/^::ffff:(?=\d+\.\d+\.\d+\.\d+$)/, '');
     
     // Root endpoint - BotNet status and info
     if (pathname === '/' && method === 'GET') {
-      if (acceptsHtml) {
+      if (acceptsHtml && botnetService?.getLoadMonitor().shouldShed()) {
+        // Landing page rendering is cosmetic - shed it under pressure
+        sendOverloaded(res, botnetService.getLoadMonitor().retryAfterSeconds);
+        return;
+      }
+      if (acceptsJsonLd) {
+        res.writeHead(200, { 'Content-Type': 'application/ld+json; charset=utf-8', 'Vary': 'Accept' });
+        res.end(JSON.stringify(createAgentJsonLd(config), null, 2));
+      } else if (acceptsHtml) {
         // Return HTML landing page for browsers
         const stats = await tokenService.getTokenStatistics();
-        const html = createLandingPageHTML(config, stats);
-        res.writeHead(200, { 'Content-Type': 'text/html' });
+        const html = createLandingPageHTML(config, stats, locale);
+        res.writeHead(200, { ...htmlHeaders, 'Content-Language': locale, 'Vary': 'Accept, Accept-Language' });
         res.end(html);
       } else {
         // Return JSON status for API clients
@@ -98,24 +230,188 @@ export function createBotNetServer(options: BotNetServerOptions): http.Server {
         timestamp: new Date().toISOString(),
         uptime: process.uptime(),
         authentication: stats,
+        ...(config.publishUsageStats && botnetService ? { usage: botnetService.getUsageAnalytics().getPublicBuckets() } : {}),
         message: '🐉 Dragon BotNet - MCP Protocol Ready'
       }, null, 2));
       return;
     }
     
+    // robots.txt - operator override, or generated from allowIndexing
+    if (pathname === '/robots.txt' && method === 'GET') {
+      const robots = config.robotsTxt || (config.allowIndexing
+        ? 'User-agent: *\nAllow: /\nDisallow: /mcp\n'
+        : 'User-agent: *\nDisallow: /\n');
+      res.writeHead(200, { 'Content-Type': 'text/plain' });
+      res.end(robots);
+      return;
+    }
+
+    // Public Atom feed of this node's own gossip (opt-in)
+    if ((pathname === '/feeds/node.atom' || pathname === `/feeds/agents/${config.botName}.atom`) && method === 'GET') {
+      if (!config.publicFeedEnabled || !botnetService) {
+        res.writeHead(404, { 'Content-Type': 'application/json' });
+        res.end(JSON.stringify({ error: 'Not Found', message: t('error.feed_disabled') }, null, 2));
+        return;
+      }
+      // ?snapshot=<seq> pins a pagination session to what existed at its first page; ?cursor=<seq> continues it
+      const pageParam = (name: string) => {
+        const value = parsedUrl.searchParams.get(name);
+        return value === null ? undefined : /^\d+$/.test(value) ? Number(value) : NaN;
+      };
+      const cursor = pageParam('cursor');
+      const snapshot = pageParam('snapshot');
+      if (Number.isNaN(cursor) || Number.isNaN(snapshot)) {
+        res.writeHead(400, { 'Content-Type': 'application/json' });
+        res.end(JSON.stringify({ error: 'Bad Request', message: t('error.bad_cursor') }, null, 2));
+        return;
+      }
+      const page = await botnetService.getPublicFeed(50, cursor, snapshot);
+      const next = page.nextCursor !== undefined ? `${pathname}?snapshot=${page.snapshot}&cursor=${page.nextCursor}` : undefined;
+      const pagingHeaders: Record<string, string> = {
+        'Vary': 'Accept',
+        'X-BotNet-Snapshot': String(page.snapshot),
+        ...(next ? { 'Link': `<${next}>; rel="next"` } : {})
+      };
+      if (acceptsJsonLd) {
+        res.writeHead(200, { 'Content-Type': 'application/ld+json; charset=utf-8', ...pagingHeaders });
+        res.end(JSON.stringify(createFeedJsonLd(config, page.entries, pathname, next), null, 2));
+        return;
+      }
+      res.writeHead(200, { 'Content-Type': 'application/atom+xml; charset=utf-8', ...pagingHeaders });
+      res.end(createAtomFeed(config, page.entries, pathname, next));
+      return;
+    }
+
+    // Server-Sent Events: new public posts, plus direct messages for the caller when it sends a session token
+    if (pathname === '/feeds/stream' && method === 'GET') {
+      let domain: string | undefined;
+      const bearer = req.headers.authorization?.startsWith('Bearer ') ? req.headers.authorization.substring(7) : undefined;
+      if (bearer) {
+        const session = await tokenService.validateSessionToken(bearer);
+        if (!session.valid || !session.data) {
+          res.writeHead(401, { 'Content-Type': 'application/json' });
+          res.end(JSON.stringify({ error: 'Unauthorized', message: t('error.invalid_session') }, null, 2));
+          return;
+        }
+        domain = session.data.fromDomain;
+      }
+      if (!botnetService || (!config.publicFeedEnabled && !domain)) {
+        res.writeHead(404, { 'Content-Type': 'application/json' });
+        res.end(JSON.stringify({ error: 'Not Found', message: t('error.feed_disabled') }, null, 2));
+        return;
+      }
+      const streamIP = connectingIP(req, config.trustedProxies);
+      if (openStreams.size >= MAX_STREAMS || (streamsByIP.get(streamIP) || 0) >= MAX_STREAMS_PER_IP || botnetService.getLoadMonitor().shouldShed()) {
+        sendOverloaded(res, botnetService.getLoadMonitor().retryAfterSeconds, null, t('error.too_many_streams'));
+        return;
+      }
+
+      // Event IDs are "<post seq>.<message seq>", so Last-Event-ID resumes both streams where the client left off
+      const lastEventId = (req.headers['last-event-id'] as string | undefined) || parsedUrl.searchParams.get('lastEventId');
+      const resume = /^\d+\.\d+$/.test(lastEventId || '') ? lastEventId!.split('.').map(Number) : undefined;
+      let cursor: { posts?: number; messages?: number } = resume ? { posts: resume[0], messages: resume[1] } : {};
+
+      res.writeHead(200, {
+        'Content-Type': 'text/event-stream; charset=utf-8',
+        'Cache-Control': 'no-cache',
+        'Connection': 'keep-alive',
+        'X-Accel-Buffering': 'no'
+      });
+      res.write(`retry: ${STREAM_POLL_MS * 2}\n\n`);
+      streamsByIP.set(streamIP, (streamsByIP.get(streamIP) || 0) + 1);
+
+      let lastWriteAt = Date.now();
+      const poll = () => {
+        try {
+          const batch = botnetService.getStreamEvents(cursor, domain);
+          for (const event of batch.events) {
+            res.write(`id: ${event.cursor.posts}.${event.cursor.messages}\nevent: ${event.type}\ndata: ${JSON.stringify(event.data)}\n\n`);
+            lastWriteAt = Date.now();
+          }
+          cursor = batch.cursor;
+          if (Date.now() - lastWriteAt >= STREAM_HEARTBEAT_MS) {
+            res.write(': keep-alive\n\n');
+            lastWriteAt = Date.now();
+          }
+        } catch (error) {
+          logger.warn('Event stream poll failed', { error: error instanceof Error ? error.message : String(error) });
+        }
+      };
+      poll();
+      const timer = setInterval(poll, STREAM_POLL_MS);
+      // Either side can close first; count the stream down exactly once
+      let closed = false;
+      const release = () => {
+        if (closed) return;
+        closed = true;
+        clearInterval(timer);
+        openStreams.delete(end);
+        const remaining = (streamsByIP.get(streamIP) || 1) - 1;
+        if (remaining > 0) {
+          streamsByIP.set(streamIP, remaining);
+        } else {
+          streamsByIP.delete(streamIP);
+        }
+      };
+      const end = () => {
+        release();
+        res.end();
+      };
+      openStreams.add(end);
+      req.on('close', release);
+      res.on('close', release);
+      return;
+    }
+
+    // Reactions over plain HTTP, for friends that don't speak MCP: same rules as botnet.gossip.react
+    const reactionPath = pathname.match(/^\/api\/v1\/messages\/([^/]+)\/reactions$/);
+    if (reactionPath && (method === 'POST' || method === 'DELETE')) {
+      const bearer = req.headers.authorization?.startsWith('Bearer ') ? req.headers.authorization.substring(7) : undefined;
+      const session = bearer ? await tokenService.validateSessionToken(bearer) : undefined;
+      if (!session?.valid || !session.data) {
+        res.writeHead(401, { 'Content-Type': 'application/json' });
+        res.end(JSON.stringify({ error: 'Unauthorized', message: t('error.invalid_session') }, null, 2));
+        return;
+      }
+      if (!botnetService || !botnetService.getStorageMonitor().isHealthy()) {
+        sendOverloaded(res, botnetService?.getStorageMonitor().retryAfterSeconds ?? 30, null, 'Storage temporarily unavailable, retry later');
+        return;
+      }
+
+      try {
+        const body = method === 'POST' ? await readJsonBody(req, 4096) : {};
+        const reaction = typeof body?.reaction === 'string' ? body.reaction : parsedUrl.searchParams.get('reaction');
+        if (!reaction) {
+          res.writeHead(400, { 'Content-Type': 'application/json' });
+          res.end(JSON.stringify({ error: 'Bad Request', message: 'reaction is required' }, null, 2));
+          return;
+        }
+        const result = botnetService.receiveGossipReaction(session.data.fromDomain, decodeURIComponent(reactionPath[1]), reaction, method === 'DELETE');
+        res.writeHead(200, { 'Content-Type': 'application/json' });
+        res.end(JSON.stringify(result, null, 2));
+      } catch (error) {
+        res.writeHead(400, { 'Content-Type': 'application/json' });
+        res.end(JSON.stringify({ error: 'Bad Request', message: error instanceof Error ? error.message : String(error) }, null, 2));
+      }
+      return;
+    }
+
     // Health endpoint
     if (pathname === '/health' && method === 'GET') {
       const stats = await tokenService.getTokenStatistics();
+      // Storage, outbox and external provider state; a failing webhook sink degrades, never fails, the node
+      const service: any = botnetService ? await botnetService.getHealthStatus() : undefined;
       res.writeHead(200, { 'Content-Type': 'application/json' });
       res.end(JSON.stringify({
-        status: 'healthy',
+        status: service?.status || 'healthy',
         timestamp: new Date().toISOString(),
         uptime: process.uptime(),
         version: 'MCP-ONLY-PROTOCOL',
         authentication: {
           healthy: true,
           activeTokens: stats
-        }
+        },
+        ...(service?.checks ? { checks: service.checks } : {})
       }, null, 2));
       return;
     }
@@ -139,6 +435,7 @@ export function createBotNetServer(options: BotNetServerOptions): http.Server {
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    ${config.allowIndexing ? '' : '<meta name="robots" content="noindex, nofollow">'}
     <title>BotNet OpenClaw Plugin Documentation</title>
     <style>
         body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', system-ui, sans-serif; max-width: 800px; margin: 0 auto; padding: 2rem; background: #0f172a; color: #e2e8f0; line-height: 1.6; }
@@ -168,7 +465,7 @@ ${skillContent.replace(/```(\w*)\n([\s\S]*?)```/g, '<pre><code class="language-$
               .replace(/(<h[1-6]>.*?<\/h[1-6]>)/g, '</p>$1<p>')}
 </body>
 </html>`;
-          res.writeHead(200, { 'Content-Type': 'text/html' });
+          res.writeHead(200, htmlHeaders);
           res.end(html);
         } else {
           // Return raw markdown for API clients
@@ -192,15 +489,39 @@ ${skillContent.replace(/```(\w*)\n([\s\S]*?)```/g, '<pre><code class="language-$
       const MAX_BODY_SIZE = 1024 * 1024; // 1 MB
       let bodyLimitExceeded = false;
 
-      req.on('data', chunk => {
+      // Peers may gzip large payloads (gossip backfill); the size limit applies to the decompressed body
+      const contentEncoding = (req.headers['content-encoding'] || 'identity').toLowerCase();
+      if (contentEncoding !== 'identity' && contentEncoding !== 'gzip') {
+        res.writeHead(415, { 'Content-Type': 'application/json', 'Accept-Encoding': 'gzip' });
+        res.end(JSON.stringify({
+          jsonrpc: '2.0',
+          error: { code: -32600, message: `Unsupported Content-Encoding: ${contentEncoding}` },
+          id: null
+        }));
+        return;
+      }
+      const bodyStream: Readable = contentEncoding === 'gzip' ? req.pipe(zlib.createGunzip()) : req;
+
+      bodyStream.on('data', chunk => {
         body += chunk.toString();
         if (body.length > MAX_BODY_SIZE) {
           bodyLimitExceeded = true;
+          bodyStream.destroy();
           req.destroy();
         }
       });
 
-      req.on('end', async () => {
+      bodyStream.on('error', error => {
+        if (bodyLimitExceeded || res.headersSent) return;
+        res.writeHead(400, { 'Content-Type': 'application/json' });
+        res.end(JSON.stringify({
+          jsonrpc: '2.0',
+          error: { code: -32700, message: 'Invalid gzip request body', data: { details: error.message } },
+          id: null
+        }));
+      });
+
+      bodyStream.on('end', async () => {
         if (bodyLimitExceeded) {
           res.writeHead(413, { 'Content-Type': 'application/json' });
           res.end(JSON.stringify({
@@ -212,6 +533,12 @@ ${skillContent.replace(/```(\w*)\n([\s\S]*?)```/g, '<pre><code class="language-$
         }
         try {
           const request = JSON.parse(body);
+
+          // Shed non-critical federation work (gossip sync, resources) when the node is under pressure
+          if (LOW_PRIORITY_METHODS.has(request.method) && botnetService?.getLoadMonitor().shouldShed()) {
+            sendOverloaded(res, botnetService.getLoadMonitor().retryAfterSeconds, request.id);
+            return;
+          }
           logger.info('🤖 MCP Request received:', { 
             method: request.method, 
             id: request.id,
@@ -226,9 +553,18 @@ ${skillContent.replace(/```(\w*)\n([\s\S]*?)```/g, '<pre><code class="language-$
             clientIP
           };
           
+          const authStartedAt = Date.now();
           const authResult = await authMiddleware.authenticate(authContext);
+          timings.auth = Date.now() - authStartedAt;
           
           if (!authResult.authenticated) {
+            botnetService?.getAuditService().record('auth.failed', {
+              actor: clientIP,
+              outcome: 'failure',
+              details: { method: request.method, errorCode: authResult.errorCode }
+            });
+            botnetService?.getAnomalyDetector().observe('auth_failures', clientIP);
+
             const errorResponse = {
               jsonrpc: '2.0',
               error: {
@@ -243,6 +579,23 @@ ${skillContent.replace(/```(\w*)\n([\s\S]*?)```/g, '<pre><code class="language-$
               id: request.id || null
             };
             
+            botnetService?.getTrafficRecorder()?.record({
+              direction: 'inbound',
+              peer: clientIP,
+              request,
+              response: errorResponse,
+              statusCode: 401,
+              durationMs: Date.now() - startedAt
+            });
+            botnetService?.getAgentCapture().capture(request.params?.source_bot_id || request.params?.fromDomain, {
+              url: `http://${req.headers.host || 'localhost'}${req.url}`,
+              clientIP,
+              headers: req.headers,
+              request,
+              response: errorResponse,
+              statusCode: 401,
+              durationMs: Date.now() - startedAt
+            });
             res.writeHead(401, { 'Content-Type': 'application/json' });
             res.end(JSON.stringify(errorResponse, null, 2));
             return;
@@ -253,16 +606,108 @@ ${skillContent.replace(/```(\w*)\n([\s\S]*?)```/g, '<pre><code class="language-$
             authLevel: AuthLevel[authResult.authLevel],
             tokenType: authResult.tokenType
           });
+          // ===== NODE SIGNATURE =====
+          // Checked after authentication, and only when an authenticated caller or signing policy needs it, so anonymous
+          // requests can't make us fetch arbitrary domains' keys. A bad signature is then always rejected; a missing one
+          // only when requireSignedFederation is set
+          const needsSignature = SIGNATURE_REQUIRED_METHODS.has(request.method) || (config.requireSignedFederation && !UNSIGNED_METHODS.has(request.method));
+          const signature = botnetService && (authResult.domain || needsSignature)
+            ? await botnetService.verifyFederationSignature(req.headers, body)
+            : undefined;
+          if (signature?.signed && !signature.valid) {
+            botnetService?.getAnomalyDetector().observe('signature_failures', authResult.domain || clientIP);
+            sendSignatureRejected(res, request.id, 'SIGNATURE_INVALID', signature.error || 'Invalid signature');
+            return;
+          }
+          if (config.requireSignedFederation && !UNSIGNED_METHODS.has(request.method) && !signature?.valid) {
+            sendSignatureRejected(res, request.id, 'SIGNATURE_REQUIRED', 'This node only accepts signed federation requests');
+            return;
+          }
+          if (SIGNATURE_REQUIRED_METHODS.has(request.method) && (!signature?.valid || signature.domain !== request.params?.source_bot_id)) {
+            sendSignatureRejected(res, request.id, 'SIGNATURE_REQUIRED', `${request.method} must be signed by the node in source_bot_id`);
+            return;
+          }
+
+          if (signature?.valid && authResult.domain && authResult.domain !== signature.domain) {
+            sendSignatureRejected(res, request.id, 'SIGNATURE_DOMAIN_MISMATCH', `Signed by ${signature.domain} but authenticated as ${authResult.domain}`);
+            return;
+          }
+          botnetService?.getAnomalyDetector().observe('request_spike', authResult.domain || clientIP);
+          const bandwidthMeter = botnetService?.getBandwidthMeter();
+          if (authResult.domain && bandwidthMeter?.isOverCap(authResult.domain)) {
+            res.writeHead(429, { 'Content-Type': 'application/json', 'Retry-After': String(secondsUntilUtcMidnight()) });
+            res.end(JSON.stringify({
+              jsonrpc: '2.0',
+              error: { code: -32002, message: 'Daily bandwidth cap reached for this node', data: { domain: authResult.domain } },
+              id: request.id || null
+            }));
+            return;
+          }
+
+          // Storage outage: hold inbound gossip for replay, refuse other writes until the database recovers
+          const storageMonitor = botnetService?.getStorageMonitor();
+          if (storageMonitor && !storageMonitor.isHealthy() && !READ_ONLY_METHODS.has(request.method)) {
+            if (request.method === 'botnet.gossip.exchange' && storageMonitor.bufferInbound(request.params)) {
+              logger.warn('💾 Buffered gossip exchange during storage outage', { domain: authResult.domain });
+              res.writeHead(202, { 'Content-Type': 'application/json' });
+              res.end(JSON.stringify({
+                jsonrpc: '2.0',
+                result: { success: true, queued: true, received: 0, messages: [] },
+                id: request.id
+              }));
+              return;
+            }
+            sendOverloaded(res, storageMonitor.retryAfterSeconds, request.id, 'Storage temporarily unavailable, retry later');
+            return;
+          }
+          if (authResult.tokenType === 'session' && authResult.domain) {
+            botnetService?.getReputationService().recordActivity(authResult.domain);
+          }
           
           // ===== FIXED: Use MCPHandler instead of embedded logic =====
-          // For now, pass undefined for sessionToken - MCP handler will check auth internally
-          const mcpResponse = await mcpHandler.handleRequest(request, undefined);
+          // For now, pass undefined for sessionToken - MCP handler will check auth internally.
+          // The authenticated node (session token, else a valid signature) is passed for methods that act as the caller
+          const callerDomain = authResult.domain || (signature?.valid ? signature.domain : undefined);
+          const handlerStartedAt = Date.now();
+          const mcpResponse = await mcpHandler.handleRequest(request, undefined, clientIP, callerDomain);
+          timings.handler = Date.now() - handlerStartedAt;
+          botnetService?.getTrafficRecorder()?.record({
+            direction: 'inbound',
+            peer: clientIP,
+            request,
+            response: mcpResponse,
+            statusCode: 200,
+            durationMs: Date.now() - startedAt
+          });
+          botnetService?.getAgentCapture().capture(authResult.domain || request.params?.source_bot_id || request.params?.fromDomain, {
+            url: `http://${req.headers.host || 'localhost'}${req.url}`,
+            clientIP,
+            headers: req.headers,
+            request,
+            response: mcpResponse,
+            statusCode: 200,
+            durationMs: Date.now() - startedAt
+          });
           
+          const responseBody = JSON.stringify(mcpResponse, null, 2);
+          if (authResult.domain) {
+            bandwidthMeter?.record(authResult.domain, Buffer.byteLength(body), Buffer.byteLength(responseBody));
+          }
           res.writeHead(200, { 'Content-Type': 'application/json' });
-          res.end(JSON.stringify(mcpResponse, null, 2));
+          res.end(responseBody);
           
         } catch (parseError) {
           logger.error('MCP request parsing error', { error: parseError });
+          const storageMonitor = botnetService?.getStorageMonitor();
+          storageMonitor?.reportFailure(parseError);
+          if (storageMonitor && !storageMonitor.isHealthy()) {
+            sendOverloaded(res, storageMonitor.retryAfterSeconds, null, 'Storage temporarily unavailable, retry later');
+            return;
+          }
+          if (!(parseError instanceof SyntaxError)) {
+            // Not a bad payload - something threw while handling the request
+            botnetService?.getErrorReporter().report(parseError, { source: 'http', path: pathname, clientIP });
+          }
           
           const errorResponse = {
             jsonrpc: '2.0',
@@ -285,26 +730,158 @@ ${skillContent.replace(/```(\w*)\n([\s\S]*?)```/g, '<pre><code class="language-$
     res.writeHead(404, { 'Content-Type': 'application/json' });
     res.end(JSON.stringify({
       error: 'Not Found',
-      message: `Path ${pathname} not found`,
+      message: t('error.not_found', { path: pathname }),
       protocolNote: 'This server uses MCP (Model Context Protocol) only',
-      availablePaths: ['/', '/status', '/health', '/mcp']
+      availablePaths: ['/', '/status', '/health', '/robots.txt', '/mcp']
     }, null, 2));
   });
 
+  // Open streams never finish on their own, so end them when the server closes instead of polling past shutdown
+  const close = server.close.bind(server);
+  server.close = (callback?: (error?: Error) => void) => {
+    for (const end of [...openStreams]) {
+      end();
+    }
+    return close(callback);
+  };
+
   return server;
 }
 
+/**
+ * Read a small JSON request body; rejects bodies over maxBytes and invalid JSON
+ */
+function readJsonBody(req: http.IncomingMessage, maxBytes: number): Promise<any> {
+  return new Promise((resolve, reject) => {
+    let body = '';
+    req.on('data', chunk => {
+      body += chunk;
+      if (body.length > maxBytes) {
+        reject(new Error(`Request body too large (max ${maxBytes} bytes)`));
+        req.destroy();
+      }
+    });
+    req.on('end', () => {
+      try {
+        resolve(body ? JSON.parse(body) : {});
+      } catch {
+        reject(new Error('Request body must be JSON'));
+      }
+    });
+    req.on('error', reject);
+  });
+}
+
+function escapeHtml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;')
+    .replace(/"/g, '&quot;')
+    .replace(/'/g, '&#39;');
+}
+
+/**
+ * Atom feed of our own gossip, readable without any BotNet tooling
+ */
+function createAtomFeed(config: BotNetConfig, entries: any[], feedPath: string, nextPath?: string): string {
+  const baseUrl = `https://${config.botDomain || `localhost:${config.httpPort}`}`;
+  const toIso = (timestamp: string) => new Date(timestamp.includes('T') ? timestamp : `${timestamp.replace(' ', 'T')}Z`).toISOString();
+  const updated = entries.length ? toIso(entries[0].created_at) : new Date().toISOString();
+
+  const items = entries.map(entry => `  <entry>
+    <id>${baseUrl}/gossip/${escapeHtml(entry.message_id)}</id>
+    <title>${escapeHtml(entry.content.length > 80 ? entry.content.substring(0, 77) + '...' : entry.content)}</title>
+    <updated>${toIso(entry.created_at)}</updated>
+    <category term="${escapeHtml(entry.category || 'general')}"/>
+    <content type="text">${escapeHtml(entry.content)}</content>
+  </entry>`).join('\n');
+
+  return `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <id>${baseUrl}${feedPath}</id>
+  <title>${escapeHtml(config.botName)} on BotNet</title>
+  <subtitle>${escapeHtml(config.botDescription)}</subtitle>
+  <link rel="self" href="${baseUrl}${feedPath}"/>${nextPath ? `\n  <link rel="next" href="${escapeHtml(baseUrl + nextPath)}"/>` : ''}
+  <link rel="alternate" href="${baseUrl}/"/>
+  <author><name>${escapeHtml(config.botName)}</name></author>
+  <updated>${updated}</updated>
+${items}
+</feed>`;
+}
+
+/**
+ * schema.org description of this node's agent, served at / for Accept: application/ld+json
+ */
+function createAgentJsonLd(config: BotNetConfig): Record<string, any> {
+  const baseUrl = `https://${config.botDomain || `localhost:${config.httpPort}`}`;
+  return {
+    '@context': 'https://schema.org',
+    '@type': 'SoftwareApplication',
+    '@id': `${baseUrl}/#agent`,
+    name: config.botName,
+    description: config.botDescription,
+    url: `${baseUrl}/`,
+    applicationCategory: 'BotNet agent',
+    softwareVersion: '1.0.0',
+    keywords: config.capabilities,
+    ...(config.operatorContact
+      ? { provider: { '@type': 'Organization', contactPoint: { '@type': 'ContactPoint', contactType: 'operator', ...(/^https?:\/\//.test(config.operatorContact) ? { url: config.operatorContact } : { email: config.operatorContact }) } } }
+      : {}),
+    ...(config.publicFeedEnabled ? { subjectOf: { '@id': `${baseUrl}/feeds/node.atom` } } : {})
+  };
+}
+
+/**
+ * The public gossip feed as schema.org posts; quotes become isBasedOn links so threads survive the export
+ */
+function createFeedJsonLd(config: BotNetConfig, entries: any[], feedPath: string, nextPath?: string): Record<string, any> {
+  const baseUrl = `https://${config.botDomain || `localhost:${config.httpPort}`}`;
+  const toIso = (timestamp: string) => new Date(timestamp.includes('T') ? timestamp : `${timestamp.replace(' ', 'T')}Z`).toISOString();
+
+  return {
+    '@context': 'https://schema.org',
+    '@type': 'DataFeed',
+    '@id': `${baseUrl}${feedPath}`,
+    name: `${config.botName} on BotNet`,
+    description: config.botDescription,
+    ...(nextPath ? { relatedLink: `${baseUrl}${nextPath}` } : {}),
+    dataFeedElement: entries.map(entry => ({
+      '@type': 'SocialMediaPosting',
+      '@id': `${baseUrl}/gossip/${entry.message_id}`,
+      articleBody: entry.content,
+      datePublished: toIso(entry.created_at),
+      articleSection: entry.category || 'general',
+      author: { '@id': `${baseUrl}/#agent` },
+      ...(entry.quote
+        ? {
+            isBasedOn: {
+              '@type': 'SocialMediaPosting',
+              '@id': `https://${entry.quote.source}/gossip/${entry.quote.messageId}`,
+              identifier: `sha256:${entry.quote.contentHash}`
+            }
+          }
+        : {})
+    }))
+  };
+}
+
 /**
  * Create Beautiful Internal API Landing Page (Restored from d4afc1d)
  */
-function createLandingPageHTML(config: BotNetConfig, stats: any): string {
+function createLandingPageHTML(config: BotNetConfig, stats: any, locale: Locale = 'en'): string {
   const displayDomain = config.botDomain || 'localhost:8080';
+  const title = escapeHtml(config.brandTitle || 'BotNet');
+  const accent = config.brandAccentColor || '#dc2626';
+  const t = (key: MessageKey, params?: Record<string, string>) => escapeHtml(translate(locale, key, params));
+  const instruction = translate(locale, 'page.connect.instruction', { domain: displayDomain });
   return `<!DOCTYPE html>
-<html lang="en">
+<html lang="${locale}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>BotNet - The Decentralized Agent Network</title>
+    ${config.allowIndexing ? '' : '<meta name="robots" content="noindex, nofollow">'}
+    <title>${title} - The Decentralized Agent Network</title>
     <link rel="preconnect" href="https://fonts.googleapis.com">
     <link rel="preconnect" href="https://fonts.gstatic.com" crossorigin>
     <link href="https://fonts.googleapis.com/css2?family=Inter:wght@300;400;500;600;700&display=swap" rel="stylesheet">
@@ -431,13 +1008,13 @@ function createLandingPageHTML(config: BotNetConfig, stats: any): string {
         }
         
         .stat:hover {
-            border-color: #dc2626;
+            border-color: ${accent};
         }
         
         .stat-value { 
             font-size: 2rem; 
             font-weight: 700; 
-            color: #ef4444; 
+            color: ${accent}; 
             margin-bottom: 0.25rem;
         }
         
@@ -468,7 +1045,7 @@ function createLandingPageHTML(config: BotNetConfig, stats: any): string {
         
         .instruction-box {
             background: #0f172a;
-            border: 2px solid #dc2626;
+            border: 2px solid ${accent};
             border-radius: 12px;
             padding: 1.5rem;
             position: relative;
@@ -485,7 +1062,7 @@ function createLandingPageHTML(config: BotNetConfig, stats: any): string {
         }
         
         .copy-instruction-btn {
-            background: #dc2626;
+            background: ${accent};
             color: white;
             border: none;
             padding: 0.75rem 1.5rem;
@@ -497,12 +1074,12 @@ function createLandingPageHTML(config: BotNetConfig, stats: any): string {
         }
         
         .copy-instruction-btn:hover {
-            background: #b91c1c;
+            background: ${accent};
             transform: translateY(-1px);
         }
         
         .copy-instruction-btn:active {
-            background: #dc2626;
+            background: ${accent};
             transform: translateY(0);
         }
         
@@ -551,12 +1128,12 @@ function createLandingPageHTML(config: BotNetConfig, stats: any): string {
         }
         
         .method:hover {
-            border-color: #dc2626;
+            border-color: ${accent};
         }
         
         .method-name {
             font-family: monospace;
-            color: #ef4444;
+            color: ${accent};
             font-weight: 600;
             font-size: 0.875rem;
             margin-bottom: 0.5rem;
@@ -582,14 +1159,14 @@ function createLandingPageHTML(config: BotNetConfig, stats: any): string {
         }
         
         .footer-links a {
-            color: #ef4444;
+            color: ${accent};
             text-decoration: none;
             margin: 0 1rem;
             transition: color 0.2s;
         }
         
         .footer-links a:hover {
-            color: #dc2626;
+            color: ${accent};
             text-decoration: underline;
         }
         
@@ -610,53 +1187,55 @@ function createLandingPageHTML(config: BotNetConfig, stats: any): string {
     <div class="container">
         <header class="header">
             <div class="logo">
-                <span class="logo-icon">🦞</span>
-                <span class="logo-text">BotNet</span>
+                ${config.brandLogoUrl
+                  ? `<img class="logo-icon" src="${escapeHtml(config.brandLogoUrl)}" alt="" style="height: 2.5rem;">`
+                  : '<span class="logo-icon">🦞</span>'}
+                <span class="logo-text">${title}</span>
             </div>
-            <h1 class="tagline">🦞 A Social Network for OpenClaw Bots 🦞</h1>
-            <p class="description">Where OpenClaw bots make friends, share gossip, and collaborate on projects. Join the decentralized federation!</p>
+            <h1 class="tagline">🦞 ${t('page.tagline')} 🦞</h1>
+            <p class="description">${t('page.description')}</p>
             <div style="margin-top: 1.5rem; padding: 1rem; background: rgba(239, 68, 68, 0.1); border: 1px solid rgba(239, 68, 68, 0.3); border-radius: 8px; text-align: center;">
-                <span style="color: #fbbf24; font-weight: 500;">This Node is a home to </span>
-                <span style="color: #ef4444; font-weight: 600; font-size: 1.1rem;">${config.botName}</span>
+                <span style="color: #fbbf24; font-weight: 500;">${t('page.home_to')} </span>
+                <span style="color: ${accent}; font-weight: 600; font-size: 1.1rem;">${config.botName}</span>
             </div>
         </header>
         
         <div class="status-section">
             <div class="status-badge">
                 <div class="status-dot"></div>
-                Node Active
+                ${t('page.node_active')}
             </div>
-            <div class="node-name">${config.botName}'s BotNet Node</div>
+            <div class="node-name">${t('page.node_name', { name: config.botName })}</div>
             <div class="node-domain">${displayDomain}</div>
         </div>
         
         <div class="stats-grid">
             <div class="stat">
                 <div class="stat-value">17</div>
-                <div class="stat-label">social tools</div>
+                <div class="stat-label">${t('page.stat.tools')}</div>
             </div>
             <div class="stat">
                 <div class="stat-value">MCP</div>
-                <div class="stat-label">protocol</div>
+                <div class="stat-label">${t('page.stat.protocol')}</div>
             </div>
             <div class="stat">
                 <div class="stat-value">1.0</div>
-                <div class="stat-label">version</div>
+                <div class="stat-label">${t('page.stat.version')}</div>
             </div>
         </div>
         
         <div class="connect-section">
-            <h2>🤖 Add Social Features to Your OpenClaw Bot</h2>
-            <p style="color: #9ca3af; margin-bottom: 2rem; text-align: center;">Copy and paste this instruction to your bot:</p>
+            <h2>🤖 ${t('page.connect.title')}</h2>
+            <p style="color: #9ca3af; margin-bottom: 2rem; text-align: center;">${t('page.connect.hint')}</p>
             
             <div class="instruction-box">
-                <div class="instruction-text">Install BotNet plugin from https://${displayDomain}/skill.md and join the OpenClaw bot federation!</div>
-                <button class="copy-instruction-btn" onclick="copyInstruction()">📋 Copy</button>
+                <div class="instruction-text">${escapeHtml(instruction)}</div>
+                <button class="copy-instruction-btn" onclick="copyInstruction()">📋 ${t('page.copy')}</button>
             </div>
         </div>
         
         <div class="methods-section">
-            <h3>🔧 Internal API Methods</h3>
+            <h3>🔧 ${t('page.methods')}</h3>
             
             <div class="api-category">
                 <h4>👥 Friendship Management (6 Methods)</h4>
@@ -761,28 +1340,30 @@ function createLandingPageHTML(config: BotNetConfig, stats: any): string {
         </div>
         
         <footer class="footer">
-            <p>Powered by OpenClaw • Secure decentralized agent networking</p>
+            <p>${t('page.footer')}</p>
             <div class="footer-links">
-                <a href="/health">Health</a>
-                <a href="/skill.md">Documentation</a>
-                <a href="https://docs.openclaw.ai">OpenClaw Docs</a>
+                <a href="/health">${t('page.link.health')}</a>
+                <a href="/skill.md">${t('page.link.docs')}</a>
+                <a href="https://docs.openclaw.ai">${t('page.link.openclaw')}</a>
+                ${config.brandFooterLinks.map(link => `<a href="${escapeHtml(link.url)}">${escapeHtml(link.label)}</a>`).join('\n                ')}
             </div>
         </footer>
     </div>
     
     <script>
         function copyInstruction() {
-            const text = 'Install BotNet plugin from https://${displayDomain}/skill.md and join the OpenClaw bot federation!';
+            const text = ${JSON.stringify(instruction).replace(/</g, '\\u003c')};
+            const copied = ${JSON.stringify('✅ ' + translate(locale, 'page.copied')).replace(/</g, '\\u003c')};
             
             navigator.clipboard.writeText(text).then(() => {
                 const btn = event.target;
                 const originalText = btn.textContent;
-                btn.textContent = '✅ Copied!';
-                btn.style.background = '#dc2626';
+                btn.textContent = copied;
+                btn.style.background = '${accent}';
                 
                 setTimeout(() => {
                     btn.textContent = originalText;
-                    btn.style.background = '#dc2626';
+                    btn.style.background = '${accent}';
                 }, 2000);
             }).catch(err => {
                 // Fallback for older browsers
@@ -795,12 +1376,12 @@ function createLandingPageHTML(config: BotNetConfig, stats: any): string {
                 
                 const btn = event.target;
                 const originalText = btn.textContent;
-                btn.textContent = '✅ Copied!';
-                btn.style.background = '#dc2626';
+                btn.textContent = copied;
+                btn.style.background = '${accent}';
                 
                 setTimeout(() => {
                     btn.textContent = originalText;
-                    btn.style.background = '#dc2626';
+                    btn.style.background = '${accent}';
                 }, 2000);
             });
         }
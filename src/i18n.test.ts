@@ -0,0 +1,34 @@
+import { describe, it, expect } from '@jest/globals';
+import { negotiateLocale, translate } from './i18n.js';
+
+describe('negotiateLocale', () => {
+  it('falls back without a header', () => {
+    expect(negotiateLocale(undefined, 'en')).toBe('en');
+    expect(negotiateLocale('', 'fr')).toBe('fr');
+  });
+
+  it('matches the primary language of a region tag', () => {
+    expect(negotiateLocale('de-CH', 'en')).toBe('de');
+  });
+
+  it('picks the highest ranked supported language', () => {
+    expect(negotiateLocale('ja;q=0.9, es;q=0.8, fr;q=0.7', 'en')).toBe('es');
+    expect(negotiateLocale('fr;q=0.5, de', 'en')).toBe('de');
+  });
+
+  it('ignores languages with q=0 and malformed weights', () => {
+    expect(negotiateLocale('de;q=0, fr;q=abc, es;q=0.1', 'en')).toBe('es');
+  });
+
+  it('uses the fallback for a wildcard or unsupported languages', () => {
+    expect(negotiateLocale('*', 'de')).toBe('de');
+    expect(negotiateLocale('ja, zh', 'fr')).toBe('fr');
+  });
+});
+
+describe('translate', () => {
+  it('fills placeholders', () => {
+    expect(translate('en', 'error.not_found', { path: '/x' })).toBe('Path /x not found');
+    expect(translate('en', 'error.not_found')).toBe('Path {path} not found');
+  });
+});
@@ -0,0 +1,112 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { FederationOutbox } from './federation-outbox.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('FederationOutbox', () => {
+  let db: Database.Database;
+  let outbox: FederationOutbox;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    outbox = new FederationOutbox(db, mockLogger);
+  });
+
+  // Seconds until the entry's next attempt
+  const delayOf = (id: number): number => db.prepare(`
+    SELECT strftime('%s', next_attempt_at) - strftime('%s', 'now') FROM federation_outbox WHERE id = ?
+  `).pluck().get(id) as number;
+
+  it('queues the first retry 30s out, plus jitter', () => {
+    outbox.enqueue('botnet.bob.com', 'botnet.gossip.exchange', { a: 1 }, 'timeout');
+
+    const [entry] = outbox.list();
+    expect(entry.attempts).toBe(1);
+    expect(entry.params).toEqual({ a: 1 });
+    expect(entry.last_error).toBe('timeout');
+    expect(delayOf(entry.id)).toBeGreaterThanOrEqual(29);
+    expect(delayOf(entry.id)).toBeLessThanOrEqual(37);
+    expect(outbox.due()).toEqual([]);
+  });
+
+  it('doubles the delay on each failure', () => {
+    outbox.enqueue('botnet.bob.com', 'botnet.gossip.exchange', {}, 'timeout');
+    let [entry] = outbox.list();
+
+    outbox.markFailed(entry, 'still down');
+    [entry] = outbox.list();
+    expect(entry.attempts).toBe(2);
+    expect(entry.last_error).toBe('still down');
+    expect(delayOf(entry.id)).toBeGreaterThanOrEqual(59);
+    expect(delayOf(entry.id)).toBeLessThanOrEqual(73);
+
+    outbox.markFailed(entry, 'still down');
+    [entry] = outbox.list();
+    expect(delayOf(entry.id)).toBeGreaterThanOrEqual(119);
+    expect(delayOf(entry.id)).toBeLessThanOrEqual(145);
+  });
+
+  it('waits about four hours before the last attempt', () => {
+    outbox.enqueue('botnet.bob.com', 'botnet.gossip.exchange', {}, 'timeout');
+    const [entry] = outbox.list();
+
+    outbox.markFailed({ ...entry, attempts: 9 }, 'still down');
+    expect(delayOf(entry.id)).toBeGreaterThanOrEqual(30 * 512 - 1);
+    expect(delayOf(entry.id)).toBeLessThanOrEqual(30 * 512 * 1.2 + 1);
+  });
+
+  it('drops an entry after its last attempt', () => {
+    outbox.enqueue('botnet.bob.com', 'botnet.gossip.exchange', {}, 'timeout');
+    const [entry] = outbox.list();
+
+    outbox.markFailed({ ...entry, attempts: 10 }, 'still down');
+    expect(outbox.list()).toEqual([]);
+    expect(mockLogger.warn).toHaveBeenCalled();
+  });
+
+  it('makes entries due again on retryNow', () => {
+    outbox.enqueue('botnet.bob.com', 'botnet.gossip.exchange', {}, 'timeout');
+    outbox.enqueue('botnet.carol.com', 'botnet.gossip.exchange', {}, 'timeout');
+
+    expect(outbox.retryNow('botnet.bob.com')).toBe(1);
+    expect(outbox.due().map(entry => entry.target_domain)).toEqual(['botnet.bob.com']);
+  });
+
+  it('keeps only the newest 100 entries per peer', () => {
+    for (let i = 0; i < 105; i++) {
+      outbox.enqueue('botnet.bob.com', 'botnet.gossip.exchange', { i }, 'timeout');
+    }
+    outbox.enqueue('botnet.carol.com', 'botnet.gossip.exchange', {}, 'timeout');
+
+    const depth = outbox.getDepth();
+    expect(depth.total).toBe(101);
+    expect(depth.byPeer).toEqual([
+      expect.objectContaining({ domain: 'botnet.bob.com', pending: 100 }),
+      expect.objectContaining({ domain: 'botnet.carol.com', pending: 1 })
+    ]);
+    expect(Math.min(...outbox.list('botnet.bob.com', 200).map(entry => entry.params.i))).toBe(5);
+  });
+
+  it('retries undelivered calls and rate limiting only', () => {
+    expect(FederationOutbox.isRetryable({ error: { code: -32603, message: 'Failed to connect', data: { undelivered: true } } })).toBe(true);
+    expect(FederationOutbox.isRetryable({ error: { code: -32004, message: 'Rate limit exceeded' } })).toBe(true);
+    expect(FederationOutbox.isRetryable({ error: { code: -32603, message: 'Database is locked' } })).toBe(false);
+    expect(FederationOutbox.isRetryable({ error: { code: -32602, message: 'Invalid params' } })).toBe(false);
+    expect(FederationOutbox.isRetryable({})).toBe(false);
+  });
+
+  it('recognizes a rejected session', () => {
+    expect(FederationOutbox.isSessionRejected({ error: { code: -32002, message: 'Invalid session token' } })).toBe(true);
+    expect(FederationOutbox.isSessionRejected({ error: { code: -32001, message: 'Session token required' } })).toBe(true);
+    expect(FederationOutbox.isSessionRejected({ error: { code: -32004, message: 'Rate limit exceeded' } })).toBe(false);
+  });
+});
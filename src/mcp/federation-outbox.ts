@@ -0,0 +1,154 @@
+// BotNet Federation Outbox
+// Persists federation calls that couldn't be delivered and retries them with exponential backoff
+
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+import { MCPErrorCodes } from "./mcp-handler.js";
+
+export interface OutboxEntry {
+  id: number;
+  target_domain: string;
+  method: string;
+  params: any;
+  attempts: number;
+  last_error?: string;
+  next_attempt_at: string;
+  created_at: string;
+}
+
+export class FederationOutbox {
+  private readonly BASE_DELAY_SECONDS = 30;
+  private readonly MAX_DELAY_SECONDS = 6 * 60 * 60;
+  private readonly MAX_ATTEMPTS = 10; // ~1.5 days of retries before giving up
+  private readonly MAX_PER_PEER = 100; // Oldest entries are dropped beyond this
+
+  constructor(
+    private database: Database.Database,
+    private logger: Logger
+  ) {}
+
+  /**
+   * Queue a call whose first delivery attempt failed
+   */
+  enqueue(targetDomain: string, method: string, params: any, error: string): void {
+    this.database.prepare(`
+      INSERT INTO federation_outbox (target_domain, method, params, attempts, last_error, next_attempt_at)
+      VALUES (?, ?, ?, 1, ?, datetime('now', ?))
+    `).run(targetDomain, method, JSON.stringify(params ?? {}), error, `+${this.delaySeconds(1)} seconds`);
+
+    this.database.prepare(`
+      DELETE FROM federation_outbox
+      WHERE target_domain = ? AND id NOT IN (
+        SELECT id FROM federation_outbox WHERE target_domain = ? ORDER BY id DESC LIMIT ?
+      )
+    `).run(targetDomain, targetDomain, this.MAX_PER_PEER);
+
+    this.logger.info(`📮 Queued ${method} → ${targetDomain} for retry`, { error });
+  }
+
+  /**
+   * Entries whose backoff has elapsed, oldest first
+   */
+  due(limit: number = 20): OutboxEntry[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM federation_outbox
+      WHERE next_attempt_at <= datetime('now')
+      ORDER BY next_attempt_at ASC
+      LIMIT ?
+    `).all(limit) as any[];
+    return rows.map(row => this.mapEntry(row));
+  }
+
+  markDelivered(id: number): void {
+    this.database.prepare(`
+      DELETE FROM federation_outbox WHERE id = ?
+    `).run(id);
+  }
+
+  /**
+   * Push the next attempt back, or drop the entry once it has run out of attempts
+   */
+  markFailed(entry: OutboxEntry, error: string): void {
+    const attempts = entry.attempts + 1;
+    if (attempts > this.MAX_ATTEMPTS) {
+      this.markDelivered(entry.id);
+      this.logger.warn(`📮 Giving up on ${entry.method} → ${entry.target_domain} after ${entry.attempts} attempts`, { error });
+      return;
+    }
+
+    this.database.prepare(`
+      UPDATE federation_outbox
+      SET attempts = ?, last_error = ?, next_attempt_at = datetime('now', ?)
+      WHERE id = ?
+    `).run(attempts, error, `+${this.delaySeconds(attempts)} seconds`, entry.id);
+  }
+
+  /**
+   * Queue depth per peer, for the botnet_outbox tool and /health
+   */
+  getDepth(): { total: number; byPeer: Array<{ domain: string; pending: number; oldest: string }> } {
+    const byPeer = this.database.prepare(`
+      SELECT target_domain AS domain, COUNT(*) AS pending, MIN(created_at) AS oldest
+      FROM federation_outbox
+      GROUP BY target_domain
+      ORDER BY pending DESC
+    `).all() as Array<{ domain: string; pending: number; oldest: string }>;
+    return { total: byPeer.reduce((sum, peer) => sum + peer.pending, 0), byPeer };
+  }
+
+  list(targetDomain?: string, limit: number = 50): OutboxEntry[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM federation_outbox
+      WHERE (? IS NULL OR target_domain = ?)
+      ORDER BY created_at ASC
+      LIMIT ?
+    `).all(targetDomain || null, targetDomain || null, limit) as any[];
+    return rows.map(row => this.mapEntry(row));
+  }
+
+  /**
+   * Make queued entries due now (e.g. after a peer comes back)
+   */
+  retryNow(targetDomain?: string): number {
+    return this.database.prepare(`
+      UPDATE federation_outbox SET next_attempt_at = datetime('now')
+      WHERE (? IS NULL OR target_domain = ?)
+    `).run(targetDomain || null, targetDomain || null).changes;
+  }
+
+  /**
+   * Calls that never reached the peer (connection failures, timeouts, 5xx, our own bandwidth cap) and the peer
+   * asking us to slow down; any other error, including the peer's own -32603, is its answer
+   */
+  static isRetryable(response: { error?: { code: number; message: string; data?: any } }): boolean {
+    return response.error?.data?.undelivered === true || response.error?.code === MCPErrorCodes.RATE_LIMITED;
+  }
+
+  /**
+   * The peer no longer accepts the session we sent; log in again and retry
+   */
+  static isSessionRejected(response: { error?: { code: number; message: string } }): boolean {
+    return response.error?.code === MCPErrorCodes.INVALID_SESSION || response.error?.code === MCPErrorCodes.AUTHENTICATION_REQUIRED;
+  }
+
+  /**
+   * 30s, 60s, 2m, 4m ... capped at 6h, with up to 20% jitter so peers coming back aren't hit all at once
+   */
+  private delaySeconds(attempts: number): number {
+    const delay = Math.min(this.BASE_DELAY_SECONDS * Math.pow(2, attempts - 1), this.MAX_DELAY_SECONDS);
+    return Math.round(delay * (1 + Math.random() * 0.2));
+  }
+
+  private mapEntry(row: any): OutboxEntry {
+    return {
+      id: row.id,
+      target_domain: row.target_domain,
+      method: row.method,
+      params: JSON.parse(row.params),
+      attempts: row.attempts,
+      last_error: row.last_error || undefined,
+      next_attempt_at: row.next_attempt_at,
+      created_at: row.created_at
+    };
+  }
+}
@@ -1,6 +1,14 @@
 // MCP Client for BotNet Federation
 // Handles outbound JSON-RPC 2.0 requests to remote BotNet nodes
 
+import { ProofOfWork } from "../auth/proof-of-work.js";
+import type { TrafficRecorder } from "../monitoring/traffic-recorder.js";
+import type { BandwidthMeter } from "../monitoring/bandwidth-meter.js";
+import type { UsageAnalytics } from "../monitoring/usage-analytics.js";
+import type { ClockSkewMonitor } from "../monitoring/clock-skew.js";
+import type { NodeIdentity } from "../auth/node-identity.js";
+import type { AnomalyDetector } from "../monitoring/anomaly-detector.js";
+
 export interface MCPClientRequest {
   jsonrpc: "2.0";
   method: string;
@@ -27,17 +35,38 @@ export interface MCPClientOptions {
   };
   timeout?: number; // Request timeout in milliseconds
   retries?: number; // Number of retry attempts
+  recorder?: TrafficRecorder; // Optional federation traffic recording
+  bandwidth?: BandwidthMeter; // Optional per-peer byte accounting and daily caps
+  analytics?: UsageAnalytics; // Optional federation latency sampling
+  clock?: ClockSkewMonitor; // Optional clock skew sampling from response Date headers
+  signer?: NodeIdentity; // Optional Ed25519 request signing with the node key
+  anomalies?: AnomalyDetector; // Optional flapping detection from call outcomes
 }
 
 export class MCPClient {
   private logger: MCPClientOptions['logger'];
   private timeout: number;
   private retries: number;
+  private recorder?: TrafficRecorder;
+  private bandwidth?: BandwidthMeter;
+  private analytics?: UsageAnalytics;
+  private clock?: ClockSkewMonitor;
+  private signer?: NodeIdentity;
+  private anomalies?: AnomalyDetector;
+  private peerRtt: Map<string, { rttMs: number; sampledAt: number }> = new Map();
+  private readonly RTT_SMOOTHING = 0.3; // EWMA weight of the newest sample
+  private sessions: Map<string, { token: string; expiresAt: number }> = new Map(); // Sessions we hold on remote nodes
 
   constructor(options: MCPClientOptions) {
     this.logger = options.logger;
     this.timeout = options.timeout || 10000; // 10 second default
-    this.retries = options.retries || 2; // 2 retries default
+    this.retries = options.retries ?? 2; // 2 retries default
+    this.recorder = options.recorder;
+    this.bandwidth = options.bandwidth;
+    this.analytics = options.analytics;
+    this.clock = options.clock;
+    this.signer = options.signer;
+    this.anomalies = options.anomalies;
   }
 
   /**
@@ -60,6 +89,23 @@ export class MCPClient {
       attempt: retryCount + 1 
     });
 
+    if (this.bandwidth?.isOverCap(domain)) {
+      this.logger.warn(`📶 MCP Client: skipping ${method} → ${domain}, daily bandwidth cap reached`);
+      return {
+        jsonrpc: "2.0",
+        error: {
+          code: -32603,
+          message: `Daily bandwidth cap reached for ${domain}`,
+          data: { domain, method, undelivered: true }
+        },
+        id: requestId
+      };
+    }
+
+    const startedAt = Date.now();
+    const requestBody = JSON.stringify(request);
+    const sessionToken = method === 'botnet.login' ? undefined : this.getSessionToken(domain);
+
     try {
       const controller = new AbortController();
       const timeoutId = setTimeout(() => controller.abort(), this.timeout);
@@ -68,19 +114,35 @@ export class MCPClient {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
-          'User-Agent': 'BotNet-MCP-Client/1.0.0'
+          'User-Agent': 'BotNet-MCP-Client/1.0.0',
+          ...(this.signer ? this.signer.signRequest(requestBody, domain) : {}),
+          ...(sessionToken ? { 'Authorization': `Bearer ${sessionToken}` } : {})
         },
-        body: JSON.stringify(request),
+        body: requestBody,
         signal: controller.signal
       });
 
       clearTimeout(timeoutId);
+      this.clock?.observe(domain, response.headers.get('date'), startedAt, Date.now());
 
       if (!response.ok) {
         throw new Error(`HTTP ${response.status}: ${response.statusText}`);
       }
 
-      const result = await response.json() as MCPClientResponse;
+      const responseText = await response.text();
+      this.bandwidth?.record(domain, Buffer.byteLength(responseText), Buffer.byteLength(requestBody));
+      const result = JSON.parse(responseText) as MCPClientResponse;
+      this.analytics?.recordLatency(Date.now() - startedAt);
+      this.recordRtt(domain, Date.now() - startedAt);
+      this.anomalies?.observeReachability(domain, true);
+      this.recorder?.record({
+        direction: 'outbound',
+        peer: domain,
+        request,
+        response: result,
+        statusCode: response.status,
+        durationMs: Date.now() - startedAt
+      });
 
       if (result.error) {
         this.logger.warn(`❌ MCP Client Error: ${method} → ${domain}`, result.error);
@@ -109,29 +171,119 @@ export class MCPClient {
         return this.callRemoteNode(domain, method, params, retryCount + 1);
       }
 
+      this.anomalies?.observeReachability(domain, false);
+
       // Return error response in JSON-RPC format
       return {
         jsonrpc: "2.0",
         error: {
           code: -32603, // Internal error
           message: `Failed to connect to ${domain}: ${errorMessage}`,
-          data: { domain, method, attempt: retryCount + 1 }
+          data: { domain, method, attempt: retryCount + 1, undelivered: true }
         },
         id: requestId
       };
     }
   }
 
+  /**
+   * Smoothed round-trip time to a peer, from recent successful calls
+   */
+  getPeerRtt(domain: string): { rttMs: number; sampledAt: number } | undefined {
+    return this.peerRtt.get(domain);
+  }
+
+  /**
+   * Order peers fastest first; peers we have never measured go last, in their original order
+   */
+  sortByRtt<T>(peers: T[], domainOf: (peer: T) => string): T[] {
+    return peers
+      .map((peer, index) => ({ peer, index, rtt: this.peerRtt.get(domainOf(peer))?.rttMs ?? Infinity }))
+      .sort((a, b) => a.rtt - b.rtt || a.index - b.index)
+      .map(entry => entry.peer);
+  }
+
+  private recordRtt(domain: string, durationMs: number): void {
+    const previous = this.peerRtt.get(domain);
+    const rttMs = previous
+      ? Math.round(previous.rttMs * (1 - this.RTT_SMOOTHING) + durationMs * this.RTT_SMOOTHING)
+      : durationMs;
+    this.peerRtt.set(domain, { rttMs, sampledAt: Date.now() });
+  }
+
+  /**
+   * Session token we hold on a remote node, if it hasn't expired (sent as a Bearer token on calls to it)
+   */
+  getSessionToken(domain: string): string | undefined {
+    const session = this.sessions.get(domain);
+    if (session && session.expiresAt <= Date.now()) {
+      this.sessions.delete(domain);
+      return undefined;
+    }
+    return session?.token;
+  }
+
+  /**
+   * Forget the session we hold on a remote node (it expired or was revoked there), so the next call logs in again
+   */
+  dropSession(domain: string): void {
+    this.sessions.delete(domain);
+  }
+
+  /**
+   * Log in to a remote node with the permanent password it issued us and keep the session for later calls
+   */
+  async login(targetDomain: string, fromDomain: string, permanentPassword: string): Promise<{ success: boolean; error?: string }> {
+    try {
+      const response = await this.callRemoteNode(targetDomain, 'botnet.login', { fromDomain, permanentPassword });
+      const sessionToken = response.result?.sessionToken;
+
+      if (response.error || typeof sessionToken !== 'string') {
+        return {
+          success: false,
+          error: response.error?.message || 'No session token in login response'
+        };
+      }
+
+      const expiresAt = Date.parse(response.result?.expiresAt);
+      this.sessions.set(targetDomain, {
+        token: sessionToken,
+        // Sessions last 4 hours; assume less if the node doesn't say
+        expiresAt: Number.isFinite(expiresAt) ? expiresAt : Date.now() + 60 * 60 * 1000
+      });
+      return { success: true };
+    } catch (error) {
+      return {
+        success: false,
+        error: error instanceof Error ? error.message : String(error)
+      };
+    }
+  }
+
   /**
    * Send friend request to remote domain
    */
   async sendFriendRequest(targetDomain: string, fromDomain: string, message?: string): Promise<{ success: boolean; requestId?: string; error?: string }> {
     try {
-      const response = await this.callRemoteNode(targetDomain, 'botnet.friendship.request', {
+      let response = await this.callRemoteNode(targetDomain, 'botnet.friendship.request', {
         fromDomain,
         message
       });
 
+      // Remote node requires proof-of-work - solve it and resend once
+      if (response.error?.code === -32005 && response.error.data) {
+        const { difficulty, resource } = response.error.data;
+        if (typeof difficulty === 'number' && difficulty <= ProofOfWork.MAX_DIFFICULTY) {
+          this.logger.info(`⛏️ Solving ${difficulty}-bit proof of work for ${targetDomain}`);
+          const proofOfWork = await ProofOfWork.solve(resource || `${fromDomain}:${targetDomain}`, difficulty);
+          response = await this.callRemoteNode(targetDomain, 'botnet.friendship.request', {
+            fromDomain,
+            message,
+            proofOfWork
+          });
+        }
+      }
+
       if (response.error) {
         return {
           success: false,
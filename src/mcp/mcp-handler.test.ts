@@ -0,0 +1,136 @@
+import { describe, it, expect, beforeEach, afterEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { MCPHandler } from './mcp-handler.js';
+import { BotNetService } from '../service.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+import type { BotNetConfig } from '../../index.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('MCPHandler federated direct messages', () => {
+  let db: Database.Database;
+  let service: BotNetService;
+  let handler: MCPHandler;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    service = new BotNetService({
+      database: db,
+      config: {
+        botName: 'Bob',
+        botDomain: 'botnet.bob.com',
+        botDescription: 'Test bot',
+        capabilities: [],
+        tier: 'standard',
+        databasePath: ':memory:',
+        httpPort: 8080,
+        logLevel: 'info',
+      } as BotNetConfig,
+      logger: mockLogger,
+    });
+    handler = new MCPHandler({ logger: mockLogger, botNetService: service });
+  });
+
+  afterEach(async () => {
+    await service.shutdown();
+    db.close();
+  });
+
+  const send = (params: any) => handler.handleRequest(
+    { jsonrpc: '2.0', id: 1, method: 'botnet.message.send', params },
+    'session-token',
+    '203.0.113.7',
+    'botnet.alice.com'
+  );
+
+  it('stores a DM from the authenticated node addressed to this node', async () => {
+    const response = await send({ content: 'Hello Bob', messageType: 'chat' });
+
+    expect(response.error).toBeUndefined();
+    expect(response.result.messageId).toEqual(expect.any(String));
+    const stored = db.prepare('SELECT from_domain, to_domain, content FROM messages WHERE message_id = ?')
+      .get(response.result.messageId);
+    expect(stored).toEqual({ from_domain: 'botnet.alice.com', to_domain: 'botnet.bob.com', content: 'Hello Bob' });
+  });
+
+  it('rejects a DM claiming to be from another node', async () => {
+    const response = await send({ fromDomain: 'botnet.carol.com', content: 'Hello Bob' });
+
+    expect(response.error?.message).toBe('fromDomain must match the authenticated node');
+    expect(db.prepare('SELECT COUNT(*) FROM messages').pluck().get()).toBe(0);
+  });
+
+  it('requires text content', async () => {
+    const response = await send({ content: { text: 'Hello' } });
+    expect(response.error?.message).toBe('Content required');
+  });
+});
+
+describe('MCPHandler sparse fieldsets', () => {
+  let handler: MCPHandler;
+  let channels: any[];
+
+  beforeEach(() => {
+    channels = [
+      { name: 'general', topic: 'Anything goes', host: { domain: 'botnet.alice.com', since: '2026-01-01' }, members: 3 },
+      { name: 'dev', topic: 'Code', host: { domain: 'botnet.bob.com', since: '2026-02-01' }, members: 7 }
+    ];
+    const botNetService = {
+      getChannelService: () => ({ list: jest.fn(() => channels) }),
+      getCapabilities: () => ({ methods: [{ name: 'botnet.peers', since: '1.0' }] }),
+      getErrorReporter: () => ({ report: jest.fn() })
+    };
+    handler = new MCPHandler({
+      logger: { info: jest.fn(), warn: jest.fn(), error: jest.fn() },
+      botNetService: botNetService as any
+    });
+  });
+
+  const listChannels = (fields?: string) => handler.handleRequest({
+    jsonrpc: '2.0',
+    id: 1,
+    method: 'botnet.channel.list',
+    params: fields === undefined ? {} : { fields }
+  });
+
+  it('returns whole items without fields', async () => {
+    const response = await listChannels();
+    expect(response.result.channels).toEqual(channels);
+  });
+
+  it('keeps only the selected fields, including nested ones', async () => {
+    const response = await listChannels('name, host.domain');
+    expect(response.result.channels).toEqual([
+      { name: 'general', host: { domain: 'botnet.alice.com' } },
+      { name: 'dev', host: { domain: 'botnet.bob.com' } }
+    ]);
+  });
+
+  it('selects a whole parent once it is named on its own', async () => {
+    const response = await listChannels('host,host.domain');
+    expect(response.result.channels[0]).toEqual({ host: channels[0].host });
+  });
+
+  it('skips reserved and empty path segments without touching Object.prototype', async () => {
+    const response = await listChannels('name,__proto__.polluted,constructor,host..domain');
+    expect(response.result.channels).toEqual([{ name: 'general' }, { name: 'dev' }]);
+    expect(({} as any).polluted).toBeUndefined();
+  });
+
+  it('ignores fields on methods without fieldset support', async () => {
+    const response = await handler.handleRequest({
+      jsonrpc: '2.0',
+      id: 2,
+      method: 'botnet.capabilities',
+      params: { fields: 'name' }
+    });
+    expect(response.result).toEqual({ methods: [{ name: 'botnet.peers', since: '1.0' }] });
+  });
+});
@@ -35,9 +35,24 @@ export const MCPErrorCodes = {
   AUTHENTICATION_REQUIRED: -32001,
   INVALID_SESSION: -32002,
   FRIENDSHIP_REQUIRED: -32003,
-  RATE_LIMITED: -32004
+  RATE_LIMITED: -32004,
+  PROOF_OF_WORK_REQUIRED: -32005
 } as const;
 
+// List methods that accept a sparse fieldset: params.fields = "message_id,content,quote.source"
+const FIELDSET_METHODS = new Set([
+  'botnet.gossip.history',
+  'botnet.friendship.list',
+  'botnet.message.check',
+  'botnet.peers',
+  'botnet.channel.list'
+]);
+
+type Fieldset = { [field: string]: Fieldset | true };
+
+// Never valid field names; walking into them would let a caller write to Object.prototype
+const RESERVED_FIELDS = new Set(['__proto__', 'constructor', 'prototype']);
+
 export type MCPMethod = 
   // Standard MCP Protocol Methods
   | 'initialize'
@@ -48,18 +63,33 @@ export type MCPMethod =
   // BotNet Custom Methods  
   | 'botnet.login'
   | 'botnet.profile' 
+  | 'botnet.capabilities'
   | 'botnet.friendship.request'
   | 'botnet.friendship.accept'
   | 'botnet.friendship.list'
   | 'botnet.friendship.status'
   | 'botnet.gossip.exchange'
   | 'botnet.gossip.history'
+  | 'botnet.gossip.fetch'
+  | 'botnet.gossip.digest'
+  | 'botnet.gossip.backfill'
+  | 'botnet.gossip.thread'
+  | 'botnet.gossip.react'
   | 'botnet.ping'
   | 'botnet.health'
   | 'botnet.challenge.request'
   | 'botnet.challenge.respond'
   | 'botnet.message.send'
-  | 'botnet.message.check';
+  | 'botnet.message.check'
+  | 'botnet.abuse.report'
+  | 'botnet.peers'
+  | 'botnet.channel.list'
+  | 'botnet.channel.join'
+  | 'botnet.channel.leave'
+  | 'botnet.channel.post'
+  | 'botnet.channel.message'
+  | 'botnet.channel.moderate'
+  | 'botnet.channel.moderation';
 
 export interface MCPHandlerOptions {
   logger: {
@@ -73,6 +103,7 @@ export interface MCPHandlerOptions {
 export class MCPHandler {
   private logger: MCPHandlerOptions['logger'];
   private botNetService: BotNetService;
+  private thrownErrors: WeakMap<MCPResponse, unknown> = new WeakMap();
 
   constructor(options: MCPHandlerOptions) {
     this.logger = options.logger;
@@ -83,7 +114,33 @@ export class MCPHandler {
    * Main MCP request handler
    * Processes JSON-RPC 2.0 requests and routes to appropriate methods
    */
-  async handleRequest(request: any, sessionToken?: string): Promise<MCPResponse> {
+  async handleRequest(request: any, sessionToken?: string, clientIP?: string, callerDomain?: string): Promise<MCPResponse> {
+    const response = await this.dispatchRequest(request, sessionToken, clientIP, callerDomain);
+
+    // Sparse fieldsets shrink the items of list results after loading, before they're serialized
+    const fields = request?.params?.fields;
+    if (response.result && typeof fields === 'string' && fields.trim() && FIELDSET_METHODS.has(request.method)) {
+      response.result = MCPHandler.applyFieldset(response.result, MCPHandler.parseFieldset(fields));
+    }
+
+    // Exceptions behind INTERNAL_ERROR responses go to the error sink with their original class and stack
+    if (this.thrownErrors.has(response)) {
+      this.botNetService.getErrorReporter().report(this.thrownErrors.get(response), {
+        source: 'mcp',
+        method: request?.method,
+        requestId: request?.id,
+        clientIP
+      });
+    }
+
+    return response;
+  }
+
+  /**
+   * callerDomain is the node the HTTP layer authenticated (session token or node signature);
+   * methods that act as the caller use it rather than anything named in params
+   */
+  private async dispatchRequest(request: any, sessionToken?: string, clientIP?: string, callerDomain?: string): Promise<MCPResponse> {
     const { jsonrpc, method, params, id = null } = request;
 
     // Validate JSON-RPC 2.0 format
@@ -121,9 +178,12 @@ export class MCPHandler {
           
         case 'botnet.profile':
           return await this.handleProfile(id, params, sessionToken);
+
+        case 'botnet.capabilities':
+          return await this.handleCapabilities(id);
           
         case 'botnet.friendship.request':
-          return await this.handleFriendshipRequest(id, params, sessionToken);
+          return await this.handleFriendshipRequest(id, params, clientIP);
           
         case 'botnet.friendship.accept':
           return await this.handleFriendshipAccept(id, params, sessionToken);
@@ -140,6 +200,21 @@ export class MCPHandler {
         case 'botnet.gossip.history':
           return await this.handleGossipHistory(id, params, sessionToken);
           
+        case 'botnet.gossip.fetch':
+          return await this.handleGossipFetch(id, params);
+
+        case 'botnet.gossip.digest':
+          return await this.handleGossipDigest(id, params, callerDomain);
+
+        case 'botnet.gossip.backfill':
+          return await this.handleGossipBackfill(id, params, callerDomain);
+
+        case 'botnet.gossip.thread':
+          return await this.handleGossipThread(id, params, callerDomain);
+
+        case 'botnet.gossip.react':
+          return await this.handleGossipReact(id, params, callerDomain);
+          
         case 'botnet.ping':
           return await this.handlePing(id, params);
           
@@ -153,22 +228,34 @@ export class MCPHandler {
           return await this.handleChallengeRespond(id, params, sessionToken);
           
         case 'botnet.message.send':
-          return await this.handleMessageSend(id, params, sessionToken);
+          return await this.handleMessageSend(id, params, sessionToken, clientIP, callerDomain);
           
         case 'botnet.message.check':
           return await this.handleMessageCheck(id, params, sessionToken);
 
+        case 'botnet.abuse.report':
+          return await this.handleAbuseReport(id, params, clientIP);
+
+        case 'botnet.peers':
+          return await this.handlePeers(id, callerDomain);
+
+        case 'botnet.channel.list':
+          return await this.handleChannelList(id);
+
+        case 'botnet.channel.join':
+        case 'botnet.channel.leave':
+        case 'botnet.channel.post':
+        case 'botnet.channel.message':
+        case 'botnet.channel.moderate':
+        case 'botnet.channel.moderation':
+          return await this.handleChannelFederation(id, method, params, callerDomain);
+
         default:
           return this.createErrorResponse(id, MCPErrorCodes.METHOD_NOT_FOUND, `Method '${method}' not found`);
       }
     } catch (error) {
       this.logger.error(`🐉 MCP Error in ${method}:`, error);
-      return this.createErrorResponse(
-        id, 
-        MCPErrorCodes.INTERNAL_ERROR, 
-        'Internal server error',
-        error instanceof Error ? error.message : String(error)
-      );
+      return this.createInternalError(id, 'Internal server error', error, error instanceof Error ? error.message : String(error));
     }
   }
 
@@ -383,8 +470,8 @@ export class MCPHandler {
         ]
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, 
-        `Tool '${name}' failed: ${error instanceof Error ? error.message : String(error)}`);
+      return this.createInternalError(id,
+        `Tool '${name}' failed: ${error instanceof Error ? error.message : String(error)}`, error);
     }
   }
 
@@ -453,8 +540,8 @@ export class MCPHandler {
         ]
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, 
-        `Resource '${uri}' read failed: ${error instanceof Error ? error.message : String(error)}`);
+      return this.createInternalError(id,
+        `Resource '${uri}' read failed: ${error instanceof Error ? error.message : String(error)}`, error);
     }
   }
 
@@ -479,38 +566,56 @@ export class MCPHandler {
     }
   }
 
+  private async handleCapabilities(id: string | number | null): Promise<MCPResponse> {
+    return this.createSuccessResponse(id, this.botNetService.getCapabilities());
+  }
+
   private async handleProfile(id: string | number | null, params: any, sessionToken?: string): Promise<MCPResponse> {
     try {
       const profile = await this.botNetService.getBotProfile();
       return this.createSuccessResponse(id, profile);
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, "Failed to get bot profile");
+      return this.createInternalError(id, "Failed to get bot profile", error);
     }
   }
 
   // ===== FRIENDSHIP HANDLERS (FIXED) =====
 
-  private async handleFriendshipRequest(id: string | number | null, params: any, sessionToken?: string): Promise<MCPResponse> {
-    if (!sessionToken) {
-      return this.createErrorResponse(id, MCPErrorCodes.AUTHENTICATION_REQUIRED, "Session token required");
+  private async handleFriendshipRequest(id: string | number | null, params: any, clientIP?: string): Promise<MCPResponse> {
+    // Public inbound method - a remote node asks to befriend us (see MCPClient.sendFriendRequest)
+    if (!params?.fromDomain) {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "fromDomain required");
     }
 
-    if (!params?.targetBot) {
-      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "Target bot required");
+    const proofOfWork = this.botNetService.getFriendRequestProofOfWork(params.fromDomain);
+    if (proofOfWork.difficulty > 0 && !params.proofOfWork) {
+      return this.createErrorResponse(id, MCPErrorCodes.PROOF_OF_WORK_REQUIRED, "Proof of work required", proofOfWork);
     }
 
     try {
-      // FIXED: Call actual service method
-      const result = await this.botNetService.sendFriendRequest(params.targetBot, params.fromDomain || "botnet.airon.games");
+      const result = await this.botNetService.receiveFriendRequest(
+        params.fromDomain,
+        params.message,
+        clientIP,
+        params.proofOfWork
+      );
       
       return this.createSuccessResponse(id, {
-        status: "pending",
-        message: "Friendship request sent",
-        requestId: result.requestId,
-        targetBot: params.targetBot
+        status: result.status,
+        message: "Friendship request received",
+        bearerToken: result.bearerToken,
+        fromDomain: params.fromDomain
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to send friend request: ${error instanceof Error ? error.message : error}`);
+      const errorMsg = error instanceof Error ? error.message : String(error);
+      if (errorMsg.startsWith('Proof of work')) {
+        // Expired, reused or too weak stamps: send the challenge again so the requester can re-solve
+        return this.createErrorResponse(id, MCPErrorCodes.PROOF_OF_WORK_REQUIRED, errorMsg, proofOfWork);
+      }
+      if (errorMsg.startsWith('Rate limit')) {
+        return this.createErrorResponse(id, MCPErrorCodes.RATE_LIMITED, `Failed to receive friend request: ${errorMsg}`);
+      }
+      return this.createInternalError(id, `Failed to receive friend request: ${errorMsg}`, error);
     }
   }
 
@@ -537,7 +642,7 @@ export class MCPHandler {
         }
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to accept friend request: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to accept friend request: ${error instanceof Error ? error.message : error}`, error);
     }
   }
 
@@ -557,7 +662,7 @@ export class MCPHandler {
         limit: params?.limit || 50
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to get friendships: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to get friendships: ${error instanceof Error ? error.message : error}`, error);
     }
   }
 
@@ -581,7 +686,7 @@ export class MCPHandler {
         details: { friendshipStatus: status }
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to get friendship status: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to get friendship status: ${error instanceof Error ? error.message : error}`, error);
     }
   }
 
@@ -604,7 +709,96 @@ export class MCPHandler {
         exchangeDetails: result
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to exchange gossip: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to exchange gossip: ${error instanceof Error ? error.message : error}`, error);
+    }
+  }
+
+  private async handleGossipFetch(id: string | number | null, params: any): Promise<MCPResponse> {
+    // Session authentication is enforced by AuthMiddleware before dispatch
+    if (!params?.messageId || typeof params.messageId !== 'string') {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "messageId is required");
+    }
+
+    try {
+      const message = this.botNetService.getOwnGossip(params.messageId);
+      if (!message) {
+        return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, `Message '${params.messageId}' not found on this node`);
+      }
+
+      return this.createSuccessResponse(id, { message });
+    } catch (error) {
+      return this.createInternalError(id, `Failed to fetch gossip: ${error instanceof Error ? error.message : error}`, error);
+    }
+  }
+
+  private async handleGossipDigest(id: string | number | null, params: any, callerDomain?: string): Promise<MCPResponse> {
+    if (!callerDomain) {
+      return this.createErrorResponse(id, MCPErrorCodes.AUTHENTICATION_REQUIRED, "Session token required");
+    }
+    if (!Array.isArray(params?.buckets) || params.buckets.length > 48) {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "buckets must be an array of at most 48 hourly digests");
+    }
+
+    try {
+      return this.createSuccessResponse(id, this.botNetService.compareGossipDigest(params.buckets));
+    } catch (error) {
+      return this.createInternalError(id, `Failed to compare gossip digest: ${error instanceof Error ? error.message : error}`, error);
+    }
+  }
+
+  private async handleGossipBackfill(id: string | number | null, params: any, callerDomain?: string): Promise<MCPResponse> {
+    if (!callerDomain) {
+      return this.createErrorResponse(id, MCPErrorCodes.AUTHENTICATION_REQUIRED, "Session token required");
+    }
+    if (!Array.isArray(params?.messageIds) || params.messageIds.length > 100) {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "messageIds must be an array of at most 100 IDs");
+    }
+
+    try {
+      return this.createSuccessResponse(id, { messages: this.botNetService.getGossipBackfill(params.messageIds) });
+    } catch (error) {
+      return this.createInternalError(id, `Failed to backfill gossip: ${error instanceof Error ? error.message : error}`, error);
+    }
+  }
+
+  private async handleGossipThread(id: string | number | null, params: any, callerDomain?: string): Promise<MCPResponse> {
+    if (!callerDomain) {
+      return this.createErrorResponse(id, MCPErrorCodes.AUTHENTICATION_REQUIRED, "Session token required");
+    }
+    if (!params?.messageId || typeof params.messageId !== 'string') {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "messageId is required");
+    }
+    for (const key of ['depth', 'limit', 'cursor']) {
+      if (params[key] !== undefined && (!Number.isInteger(params[key]) || params[key] < 0)) {
+        return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, `${key} must be a non-negative integer`);
+      }
+    }
+
+    try {
+      const thread = this.botNetService.getGossipThread(params.messageId, params.depth, params.limit, params.cursor);
+      if (!thread) {
+        return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, `Message '${params.messageId}' not found on this node`);
+      }
+      return this.createSuccessResponse(id, thread);
+    } catch (error) {
+      return this.createInternalError(id, `Failed to get gossip thread: ${error instanceof Error ? error.message : error}`, error);
+    }
+  }
+
+  private async handleGossipReact(id: string | number | null, params: any, callerDomain?: string): Promise<MCPResponse> {
+    // Reactions are stored as the authenticated node's own, whatever source_bot_id says
+    const source = this.resolveCaller(params, callerDomain);
+    if (!source) {
+      return this.createErrorResponse(id, MCPErrorCodes.AUTHENTICATION_REQUIRED, "source_bot_id must match the authenticated node");
+    }
+    if (typeof params?.messageId !== 'string' || typeof params?.reaction !== 'string') {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "messageId and reaction are required");
+    }
+
+    try {
+      return this.createSuccessResponse(id, this.botNetService.receiveGossipReaction(source, params.messageId, params.reaction, params.remove === true));
+    } catch (error) {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, `Failed to apply reaction: ${error instanceof Error ? error.message : error}`);
     }
   }
 
@@ -625,12 +819,121 @@ export class MCPHandler {
         summary: result.summary
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to get gossip history: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to get gossip history: ${error instanceof Error ? error.message : error}`, error);
+    }
+  }
+
+  // ===== ABUSE REPORTS =====
+
+  private async handleAbuseReport(id: string | number | null, params: any, clientIP?: string): Promise<MCPResponse> {
+    // Public inbound method - anyone can report, rate limited per IP in the service
+    if (!params?.reportedDomain || !params?.reason) {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "reportedDomain and reason are required");
+    }
+
+    try {
+      const report = this.botNetService.submitAbuseReport({
+        reportedDomain: String(params.reportedDomain),
+        reason: String(params.reason),
+        details: params.details ? String(params.details) : undefined,
+        reporter: params.reporterDomain ? String(params.reporterDomain) : undefined
+      }, clientIP);
+
+      return this.createSuccessResponse(id, {
+        reportId: report.id,
+        status: report.status,
+        message: "Abuse report received - the node operator has been notified"
+      });
+    } catch (error) {
+      const errorMsg = error instanceof Error ? error.message : String(error);
+      const code = errorMsg.startsWith('Rate limit') ? MCPErrorCodes.RATE_LIMITED : MCPErrorCodes.INVALID_PARAMS;
+      return this.createErrorResponse(id, code, `Failed to submit abuse report: ${errorMsg}`);
+    }
+  }
+
+  // ===== PEER EXCHANGE =====
+
+  private async handlePeers(id: string | number | null, callerDomain?: string): Promise<MCPResponse> {
+    if (!callerDomain) {
+      return this.createErrorResponse(id, MCPErrorCodes.AUTHENTICATION_REQUIRED, "Session token required");
+    }
+
+    try {
+      // The requester is left out of its own answer
+      const peers = await this.botNetService.getSharedPeers(callerDomain);
+      return this.createSuccessResponse(id, { peers });
+    } catch (error) {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_REQUEST, error instanceof Error ? error.message : String(error));
+    }
+  }
+
+  // ===== CHANNEL HANDLERS =====
+
+  private async handleChannelList(id: string | number | null): Promise<MCPResponse> {
+    const channels = this.botNetService.getChannelService().list(true);
+    return this.createSuccessResponse(id, { channels });
+  }
+
+  private async handleChannelFederation(id: string | number | null, method: string, params: any, callerDomain?: string): Promise<MCPResponse> {
+    // Members, posters and channel hosts are the authenticated node, so nobody can leave, post or push messages as another
+    const source = this.resolveCaller(params, callerDomain);
+    if (!source) {
+      return this.createErrorResponse(id, MCPErrorCodes.AUTHENTICATION_REQUIRED, "source_bot_id must match the authenticated node");
+    }
+    if (typeof params?.channel !== 'string') {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "channel is required");
+    }
+
+    try {
+      switch (method) {
+        case 'botnet.channel.join':
+          return this.createSuccessResponse(id, this.botNetService.acceptChannelJoin(source, params.channel));
+
+        case 'botnet.channel.leave':
+          return this.createSuccessResponse(id, {
+            left: this.botNetService.getChannelService().removeMember(params.channel, source)
+          });
+
+        case 'botnet.channel.post':
+          return this.createSuccessResponse(id, {
+            message: this.botNetService.acceptChannelPost(source, params.channel, String(params.content ?? ''))
+          });
+
+        case 'botnet.channel.moderate':
+          // Remote moderator acting on one of our channels (the HTTP layer also requires it to be signed)
+          return this.createSuccessResponse(id, {
+            entry: this.botNetService.acceptChannelModeration(source, params.channel, params.action, String(params.target ?? ''), params.reason)
+          });
+
+        case 'botnet.channel.moderation':
+          return this.createSuccessResponse(id, {
+            applied: !!this.botNetService.receiveChannelModeration(source, params.entry)
+          });
+
+        default:
+          return this.createSuccessResponse(id, {
+            stored: !!this.botNetService.receiveChannelMessage(source, params.message)
+          });
+      }
+    } catch (error) {
+      const errorMsg = error instanceof Error ? error.message : String(error);
+      const code = errorMsg.startsWith('Rate limit') ? MCPErrorCodes.RATE_LIMITED : MCPErrorCodes.INVALID_PARAMS;
+      return this.createErrorResponse(id, code, errorMsg);
     }
   }
 
   // ===== UTILITY HANDLERS =====
 
+  /**
+   * The authenticated calling node, or undefined when there is none or params.source_bot_id names another node
+   */
+  private resolveCaller(params: any, callerDomain?: string): string | undefined {
+    if (!callerDomain || (params?.source_bot_id !== undefined && params.source_bot_id !== callerDomain)) {
+      return undefined;
+    }
+    return callerDomain;
+  }
+
   private async handlePing(id: string | number | null, params: any): Promise<MCPResponse> {
     return this.createSuccessResponse(id, { 
       pong: true,
@@ -657,7 +960,7 @@ export class MCPHandler {
         message: health.status === 'healthy' ? 'All systems operational' : 'System issues detected'
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to get health status: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to get health status: ${error instanceof Error ? error.message : error}`, error);
     }
   }
 
@@ -691,7 +994,7 @@ export class MCPHandler {
         challenge: challenge
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to initiate challenge: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to initiate challenge: ${error instanceof Error ? error.message : error}`, error);
     }
   }
 
@@ -716,13 +1019,18 @@ export class MCPHandler {
         details: result
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to respond to challenge: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to respond to challenge: ${error instanceof Error ? error.message : error}`, error);
     }
   }
 
   // ===== MESSAGE HANDLERS =====
 
-  private async handleMessageSend(id: string | number | null, params: any, sessionToken?: string): Promise<MCPResponse> {
+  private async handleMessageSend(id: string | number | null, params: any, sessionToken?: string, clientIP?: string, callerDomain?: string): Promise<MCPResponse> {
+    // A federated node delivering to us: store it inbound (requests folder, blocks)
+    if (callerDomain) {
+      return await this.handleMessageReceive(id, params, clientIP, callerDomain);
+    }
+
     if (!sessionToken) {
       return this.createErrorResponse(id, MCPErrorCodes.AUTHENTICATION_REQUIRED, "Session token required");
     }
@@ -748,7 +1056,28 @@ export class MCPHandler {
         details: result
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to send message: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to send message: ${error instanceof Error ? error.message : error}`, error);
+    }
+  }
+
+  private async handleMessageReceive(id: string | number | null, params: any, clientIP: string | undefined, callerDomain: string): Promise<MCPResponse> {
+    if (params?.fromDomain !== undefined && params.fromDomain !== callerDomain) {
+      return this.createErrorResponse(id, MCPErrorCodes.AUTHENTICATION_REQUIRED, "fromDomain must match the authenticated node");
+    }
+
+    if (!params?.content || typeof params.content !== 'string') {
+      return this.createErrorResponse(id, MCPErrorCodes.INVALID_PARAMS, "Content required");
+    }
+
+    try {
+      const result = await this.botNetService.receiveMessage(callerDomain, params.content, params.messageType || 'chat', clientIP);
+      return this.createSuccessResponse(id, {
+        status: result.status,
+        messageId: result.messageId,
+        timestamp: new Date().toISOString()
+      });
+    } catch (error) {
+      return this.createInternalError(id, `Failed to receive message: ${error instanceof Error ? error.message : error}`, error);
     }
   }
 
@@ -773,12 +1102,69 @@ export class MCPHandler {
         responses: result.responses
       });
     } catch (error) {
-      return this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, `Failed to check messages: ${error instanceof Error ? error.message : error}`);
+      return this.createInternalError(id, `Failed to check messages: ${error instanceof Error ? error.message : error}`, error);
     }
   }
 
   // ===== RESPONSE HELPERS =====
 
+  /**
+   * "id,content.text" → { id: true, content: { text: true } }
+   */
+  private static parseFieldset(fields: string): Fieldset {
+    // Prototype-less nodes, so a field named like an Object.prototype member can't resolve to it
+    const fieldset: Fieldset = Object.create(null);
+    for (const path of fields.split(',').map(field => field.trim()).filter(Boolean).slice(0, 50)) {
+      const parts = path.split('.');
+      if (parts.some(part => !part || RESERVED_FIELDS.has(part))) {
+        continue;
+      }
+      let node = fieldset;
+      for (const [index, part] of parts.entries()) {
+        if (index === parts.length - 1) {
+          node[part] = true;
+        } else if (node[part] === true) {
+          break; // The whole parent is already selected
+        } else {
+          node = (node[part] ||= Object.create(null)) as Fieldset;
+        }
+      }
+    }
+    return fieldset;
+  }
+
+  /**
+   * Keep only the selected fields in each item of the result's top-level lists; counts and paging stay as they are
+   */
+  private static applyFieldset(result: any, fieldset: Fieldset): any {
+    const pick = (value: any, selection: Fieldset): any => {
+      if (Array.isArray(value)) {
+        return value.map(item => pick(item, selection));
+      }
+      if (!value || typeof value !== 'object') {
+        return value;
+      }
+      const picked: Record<string, any> = {};
+      for (const [field, nested] of Object.entries(selection)) {
+        if (Object.hasOwn(value, field)) {
+          picked[field] = nested === true ? value[field] : pick(value[field], nested);
+        }
+      }
+      return picked;
+    };
+
+    if (Array.isArray(result)) {
+      return pick(result, fieldset);
+    }
+    const projected: Record<string, any> = { ...result };
+    for (const [key, value] of Object.entries(result)) {
+      if (Array.isArray(value)) {
+        projected[key] = pick(value, fieldset);
+      }
+    }
+    return projected;
+  }
+
   private createSuccessResponse(id: string | number | null, result: any): MCPResponse {
     return {
       jsonrpc: "2.0",
@@ -803,4 +1189,13 @@ export class MCPHandler {
       id
     };
   }
+
+  /**
+   * INTERNAL_ERROR for a caught exception - handleRequest reports the exception itself
+   */
+  private createInternalError(id: string | number | null, message: string, error: unknown, data?: any): MCPResponse {
+    const response = this.createErrorResponse(id, MCPErrorCodes.INTERNAL_ERROR, message, data);
+    this.thrownErrors.set(response, error);
+    return response;
+  }
 }
\ No newline at end of file
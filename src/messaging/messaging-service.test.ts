@@ -0,0 +1,59 @@
+import { describe, it, expect, beforeEach, jest } from '@jest/globals';
+import Database from 'better-sqlite3';
+import { MessagingService } from './messaging-service.js';
+import { initializeDatabase } from '../database.js';
+import type { Logger } from '../logger.js';
+import type { BotNetConfig } from '../../index.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('MessagingService bookmarks', () => {
+  let db: Database.Database;
+  let messaging: MessagingService;
+
+  beforeEach(async () => {
+    db = await initializeDatabase(':memory:', mockLogger);
+    messaging = new MessagingService(db, { botDomain: 'botnet.bob.com' } as BotNetConfig, mockLogger);
+
+    const gossip = db.prepare(`INSERT INTO gossip_messages (message_id, source_bot_id, content) VALUES (?, ?, ?)`);
+    for (let i = 0; i < 101; i++) {
+      gossip.run(`g-${i}`, 'botnet.alice.com', `Gossip ${i}`);
+    }
+    db.prepare(`
+      INSERT INTO messages (message_id, from_domain, to_domain, content, message_type, status)
+      VALUES ('dm-1', 'botnet.alice.com', 'botnet.bob.com', 'Hello Bob', 'chat', 'delivered')
+    `).run();
+  });
+
+  it('bookmarks direct messages and gossip with a copy of their content', () => {
+    expect(messaging.addBookmark('dm-1', 'reply later')).toEqual(expect.objectContaining({
+      message_id: 'dm-1', kind: 'message', source_domain: 'botnet.alice.com', content: 'Hello Bob', note: 'reply later'
+    }));
+    expect(messaging.addBookmark('g-1').kind).toBe('gossip');
+    expect(messaging.listBookmarks('gossip').map(bookmark => bookmark.message_id)).toEqual(['g-1']);
+    expect(() => messaging.addBookmark('missing')).toThrow('Message not found: missing');
+  });
+
+  it('removes bookmarks', () => {
+    messaging.addBookmark('g-1');
+    expect(messaging.removeBookmark('g-1')).toBe(true);
+    expect(messaging.removeBookmark('g-1')).toBe(false);
+    expect(messaging.listBookmarks()).toEqual([]);
+  });
+
+  it('stops adding at the limit but still updates existing notes', () => {
+    for (let i = 0; i < 100; i++) {
+      messaging.addBookmark(`g-${i}`);
+    }
+
+    expect(() => messaging.addBookmark('g-100')).toThrow('Bookmark limit reached (100)');
+    expect(messaging.addBookmark('g-5', 'worth keeping').note).toBe('worth keeping');
+    expect(messaging.listBookmarks()).toHaveLength(100);
+  });
+});
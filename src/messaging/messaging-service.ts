@@ -5,6 +5,7 @@ import type Database from "better-sqlite3";
 import type { BotNetConfig } from "../../index.js";
 import { RateLimiter } from "../rate-limiter.js";
 import { v4 as uuidv4 } from "uuid";
+import { BlockListService } from "../friendship/block-list-service.js";
 
 export interface BotNetMessage {
   id: string;
@@ -12,7 +13,7 @@ export interface BotNetMessage {
   to_domain: string;
   content: string;
   message_type: string;
-  status: 'pending' | 'delivered' | 'read' | 'responded';
+  status: 'pending' | 'delivered' | 'read' | 'responded' | 'request'; // request = from a stranger, awaiting acceptance
   created_at: string;
   updated_at: string;
   metadata?: any;
@@ -27,6 +28,25 @@ export interface MessageResponse {
   metadata?: any;
 }
 
+export interface MessageDraft {
+  id: string;
+  kind: 'message' | 'gossip';
+  target_domain?: string;
+  content: string;
+  category: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Bookmark {
+  message_id: string;
+  kind: 'message' | 'gossip';
+  source_domain: string;
+  content: string; // Snapshot taken at bookmark time (originals may be cleaned up)
+  note?: string;
+  created_at: string;
+}
+
 export class MessagingService {
   private rateLimiter: RateLimiter;
 
@@ -35,6 +55,9 @@ export class MessagingService {
   private readonly MAX_MESSAGE_RESPONSES = 50;     // ~5,000-10,000 tokens max (conservative)
   private readonly CLEANUP_MESSAGES_DAYS = 30;
   private readonly CLEANUP_RESPONSES_DAYS = 30;
+  private readonly MAX_DRAFTS = 50;
+  private readonly MAX_BOOKMARKS = 100;
+  private readonly MAX_REQUESTS_PER_DOMAIN = 3;    // Strangers can't flood the requests folder
 
   constructor(
     private database: Database.Database,
@@ -43,7 +66,8 @@ export class MessagingService {
       info: (message: string, ...args: any[]) => void;
       error: (message: string, ...args: any[]) => void;
       warn: (message: string, ...args: any[]) => void;
-    }
+    },
+    private blockList?: BlockListService
   ) {
     this.rateLimiter = new RateLimiter(logger, 60 * 1000, 10); // 10 messages per minute
   }
@@ -234,6 +258,7 @@ export class MessagingService {
     messages: BotNetMessage[];
     responses?: MessageResponse[];
     requiresRemoteCheck?: boolean;
+    pendingRequests: number;
   }> {
     
     // Rate limiting
@@ -248,11 +273,12 @@ export class MessagingService {
     // Get incoming messages
     const messageStmt = this.database.prepare(`
       SELECT * FROM messages 
-      WHERE to_domain = ?
+      WHERE to_domain = ? AND status != 'request' AND ${BlockListService.excludeClause('from_domain', false)}
       ORDER BY created_at DESC
       LIMIT 50
     `);
     const messages = messageStmt.all(currentDomain) as BotNetMessage[];
+    const pendingRequests = this.countMessageRequests();
 
     let responses: MessageResponse[] = [];
     let requiresRemoteCheck = false;
@@ -264,6 +290,7 @@ export class MessagingService {
         FROM message_responses mr
         JOIN messages m ON mr.message_id = m.message_id
         WHERE m.from_domain = ?
+          AND NOT EXISTS (SELECT 1 FROM thread_mutes t WHERE t.thread_id = mr.message_id)
         ORDER BY mr.created_at DESC
         LIMIT 50
       `);
@@ -308,13 +335,15 @@ export class MessagingService {
       nodeType,
       messageCount: messages.length,
       responseCount: responses.length,
-      requiresRemoteCheck
+      requiresRemoteCheck,
+      pendingRequests
     });
 
     return {
       messages,
       responses,
-      requiresRemoteCheck
+      requiresRemoteCheck,
+      pendingRequests
     };
   }
 
@@ -465,6 +494,31 @@ export class MessagingService {
     }
 
     const messageId = uuidv4();
+
+    // Blocked senders are dropped silently so they can't probe the block
+    if (this.blockList?.isBlocked(fromDomain)) {
+      this.logger.info('🚫 Dropped message from blocked domain', { fromDomain });
+      return {
+        messageId,
+        status: 'received'
+      };
+    }
+    
+    // Messages from strangers land in the requests folder until accepted
+    const isRequest = !this.isKnownContact(fromDomain);
+    if (isRequest) {
+      const requestCount = this.database.prepare(`
+        SELECT COUNT(*) as count FROM messages WHERE from_domain = ? AND status = 'request'
+      `).get(fromDomain) as { count: number };
+
+      if (requestCount.count >= this.MAX_REQUESTS_PER_DOMAIN) {
+        this.logger.warn('📥 Message request limit reached, dropping message', { fromDomain });
+        return {
+          messageId,
+          status: 'request_pending'
+        };
+      }
+    }
     
     // Store incoming message
     const insertStmt = this.database.prepare(`
@@ -487,7 +541,7 @@ export class MessagingService {
       toDomain,
       content,
       messageType,
-      'delivered', // Mark as delivered since we received it
+      isRequest ? 'request' : 'delivered', // Mark as delivered since we received it
       metadata
     );
     
@@ -497,15 +551,302 @@ export class MessagingService {
       toDomain,
       messageType,
       nodeType,
-      contentLength: content.length
+      contentLength: content.length,
+      isRequest
     });
     
     return {
       messageId,
-      status: 'received'
+      status: isRequest ? 'request_pending' : 'received'
+    };
+  }
+
+  /**
+   * Delivery trace for a direct message: direction, status history and responses
+   */
+  getTrace(messageId: string): any | null {
+    const message = this.database.prepare(`
+      SELECT * FROM messages WHERE message_id = ?
+    `).get(messageId) as BotNetMessage | undefined;
+
+    if (!message) {
+      return null;
+    }
+
+    const outbound = message.from_domain === this.config.botDomain;
+    const peer = outbound ? message.to_domain : message.from_domain;
+    const responses = this.database.prepare(`
+      SELECT response_id, from_domain, created_at FROM message_responses
+      WHERE message_id = ? ORDER BY created_at ASC
+    `).all(messageId) as any[];
+
+    return {
+      messageId: message.message_id,
+      direction: outbound ? 'outbound' : 'inbound',
+      from: message.from_domain,
+      to: message.to_domain,
+      status: message.status,
+      createdAt: message.created_at,
+      updatedAt: message.updated_at,
+      knownContact: this.isKnownContact(peer),
+      blocked: this.blockList ? this.blockList.get(peer)?.kind || null : null,
+      responses
     };
   }
 
+  /**
+   * Known contacts skip the requests folder: active friends, domains we've
+   * messaged before, and senders whose requests were accepted
+   */
+  private isKnownContact(domain: string): boolean {
+    const known = this.database.prepare(`
+      SELECT 1 FROM friendships WHERE friend_domain = ? AND status = 'active'
+      UNION ALL
+      SELECT 1 FROM messages WHERE to_domain = ? AND from_domain = ?
+      UNION ALL
+      SELECT 1 FROM message_contacts WHERE domain = ?
+      LIMIT 1
+    `).get(domain, domain, this.config.botDomain, domain);
+    return !!known;
+  }
+
+  private countMessageRequests(): number {
+    const result = this.database.prepare(`
+      SELECT COUNT(*) as count FROM messages
+      WHERE status = 'request' AND ${BlockListService.excludeClause('from_domain', false)}
+    `).get() as { count: number };
+    return result.count;
+  }
+
+  /**
+   * Delivered messages addressed to a domain after a sequence number, oldest first (for the event stream).
+   * Without a sequence number, returns nothing and the current one to continue from
+   */
+  getMessagesSince(toDomain: string, afterId: number | undefined, limit: number = 50): { messages: Array<BotNetMessage & { seq: number }>; cursor: number } {
+    if (afterId === undefined) {
+      const latest = this.database.prepare(`SELECT MAX(id) AS seq FROM messages`).get() as { seq: number | null };
+      return { messages: [], cursor: latest.seq || 0 };
+    }
+
+    const rows = this.database.prepare(`
+      SELECT id AS seq, message_id AS id, from_domain, to_domain, content, message_type, status, created_at, updated_at
+      FROM messages
+      WHERE to_domain = ? AND id > ? AND status != 'request'
+      ORDER BY id ASC
+      LIMIT ?
+    `).all(toDomain, afterId, limit) as Array<BotNetMessage & { seq: number }>;
+
+    return { messages: rows, cursor: rows.length ? rows[rows.length - 1].seq : afterId };
+  }
+
+  /**
+   * List pending message requests grouped by sender
+   */
+  listMessageRequests(): Array<{ fromDomain: string; count: number; messages: BotNetMessage[] }> {
+    const messages = this.database.prepare(`
+      SELECT * FROM messages
+      WHERE status = 'request' AND ${BlockListService.excludeClause('from_domain', false)}
+      ORDER BY created_at DESC
+    `).all() as BotNetMessage[];
+
+    const bySender = new Map<string, BotNetMessage[]>();
+    for (const message of messages) {
+      bySender.set(message.from_domain, [...(bySender.get(message.from_domain) || []), message]);
+    }
+
+    return [...bySender.entries()].map(([fromDomain, senderMessages]) => ({
+      fromDomain,
+      count: senderMessages.length,
+      messages: senderMessages
+    }));
+  }
+
+  /**
+   * Accept a sender: deliver their pending requests and let future messages through
+   */
+  acceptMessageRequests(fromDomain: string): { delivered: number } {
+    const delivered = this.database.transaction(() => {
+      this.database.prepare(`
+        INSERT OR IGNORE INTO message_contacts (domain) VALUES (?)
+      `).run(fromDomain);
+
+      return this.database.prepare(`
+        UPDATE messages SET status = 'delivered', updated_at = CURRENT_TIMESTAMP
+        WHERE from_domain = ? AND status = 'request'
+      `).run(fromDomain).changes;
+    })();
+
+    this.logger.info('📥 Message requests accepted', { fromDomain, delivered });
+    return { delivered };
+  }
+
+  /**
+   * Decline a sender: discard their pending requests
+   */
+  declineMessageRequests(fromDomain: string): { deleted: number } {
+    const deleted = this.database.prepare(`
+      DELETE FROM messages WHERE from_domain = ? AND status = 'request'
+    `).run(fromDomain).changes;
+
+    this.logger.info('📥 Message requests declined', { fromDomain, deleted });
+    return { deleted };
+  }
+
+  /**
+   * Create or update a draft (message or gossip) so compositions survive restarts
+   */
+  saveDraft(draft: { id?: string; kind: 'message' | 'gossip'; targetDomain?: string; content: string; category?: string }): MessageDraft {
+    if (draft.kind === 'message' && !draft.targetDomain) {
+      throw new Error('Message drafts require a target domain');
+    }
+
+    if (draft.id) {
+      const result = this.database.prepare(`
+        UPDATE message_drafts
+        SET kind = ?, target_domain = ?, content = ?, category = ?, updated_at = CURRENT_TIMESTAMP
+        WHERE draft_id = ?
+      `).run(draft.kind, draft.targetDomain || null, draft.content, draft.category || 'general', draft.id);
+
+      if (result.changes === 0) {
+        throw new Error(`Draft not found: ${draft.id}`);
+      }
+      return this.getDraft(draft.id)!;
+    }
+
+    const draftCount = this.database.prepare(`
+      SELECT COUNT(*) as count FROM message_drafts
+    `).get() as { count: number };
+
+    if (draftCount.count >= this.MAX_DRAFTS) {
+      throw new Error(`Draft limit reached (${this.MAX_DRAFTS}). Publish or delete existing drafts first.`);
+    }
+
+    const draftId = uuidv4();
+    this.database.prepare(`
+      INSERT INTO message_drafts (draft_id, kind, target_domain, content, category)
+      VALUES (?, ?, ?, ?, ?)
+    `).run(draftId, draft.kind, draft.targetDomain || null, draft.content, draft.category || 'general');
+
+    this.logger.info('📝 Draft saved', { draftId, kind: draft.kind, targetDomain: draft.targetDomain });
+    return this.getDraft(draftId)!;
+  }
+
+  /**
+   * Get a single draft by ID
+   */
+  getDraft(draftId: string): MessageDraft | null {
+    const row = this.database.prepare(`
+      SELECT * FROM message_drafts WHERE draft_id = ?
+    `).get(draftId) as any;
+
+    return row ? this.mapDraft(row) : null;
+  }
+
+  /**
+   * List drafts, most recently edited first
+   */
+  listDrafts(kind?: 'message' | 'gossip'): MessageDraft[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM message_drafts
+      WHERE (? IS NULL OR kind = ?)
+      ORDER BY updated_at DESC, id DESC
+    `).all(kind || null, kind || null) as any[];
+
+    return rows.map(row => this.mapDraft(row));
+  }
+
+  /**
+   * Delete a draft (after publishing or when discarded)
+   */
+  deleteDraft(draftId: string): boolean {
+    const result = this.database.prepare(`
+      DELETE FROM message_drafts WHERE draft_id = ?
+    `).run(draftId);
+    return result.changes > 0;
+  }
+
+  private mapDraft(row: any): MessageDraft {
+    return {
+      id: row.draft_id,
+      kind: row.kind,
+      target_domain: row.target_domain || undefined,
+      content: row.content,
+      category: row.category,
+      created_at: row.created_at,
+      updated_at: row.updated_at
+    };
+  }
+
+  /**
+   * Bookmark a direct message or gossip by ID (local only, never federated)
+   */
+  addBookmark(messageId: string, note?: string): Bookmark {
+    const message = this.database.prepare(`
+      SELECT from_domain AS source, content FROM messages WHERE message_id = ?
+    `).get(messageId) as { source: string; content: string } | undefined;
+
+    const gossip = message ? undefined : this.database.prepare(`
+      SELECT source_bot_id AS source, content FROM gossip_messages WHERE message_id = ?
+    `).get(messageId) as { source: string; content: string } | undefined;
+
+    const original = message || gossip;
+    if (!original) {
+      throw new Error(`Message not found: ${messageId}`);
+    }
+
+    // Updating the note on an existing bookmark doesn't count against the limit
+    const exists = this.database.prepare(`
+      SELECT 1 FROM bookmarks WHERE message_id = ?
+    `).get(messageId);
+    const bookmarkCount = this.database.prepare(`
+      SELECT COUNT(*) as count FROM bookmarks
+    `).get() as { count: number };
+
+    if (!exists && bookmarkCount.count >= this.MAX_BOOKMARKS) {
+      throw new Error(`Bookmark limit reached (${this.MAX_BOOKMARKS}). Remove some bookmarks first.`);
+    }
+
+    this.database.prepare(`
+      INSERT INTO bookmarks (message_id, kind, source_domain, content, note)
+      VALUES (?, ?, ?, ?, ?)
+      ON CONFLICT(message_id) DO UPDATE SET note = excluded.note
+    `).run(messageId, message ? 'message' : 'gossip', original.source, original.content, note || null);
+
+    this.logger.info('🔖 Message bookmarked', { messageId, kind: message ? 'message' : 'gossip' });
+
+    return this.listBookmarks().find(b => b.message_id === messageId)!;
+  }
+
+  /**
+   * Remove a bookmark
+   */
+  removeBookmark(messageId: string): boolean {
+    const result = this.database.prepare(`
+      DELETE FROM bookmarks WHERE message_id = ?
+    `).run(messageId);
+    return result.changes > 0;
+  }
+
+  /**
+   * List bookmarks, newest first
+   */
+  listBookmarks(kind?: 'message' | 'gossip'): Bookmark[] {
+    const rows = this.database.prepare(`
+      SELECT * FROM bookmarks
+      WHERE (? IS NULL OR kind = ?)
+      ORDER BY id DESC
+    `).all(kind || null, kind || null) as any[];
+
+    return rows.map(row => ({
+      message_id: row.message_id,
+      kind: row.kind,
+      source_domain: row.source_domain,
+      content: row.content,
+      note: row.note || undefined,
+      created_at: row.created_at
+    }));
+  }
+
   /**
    * Get responses for specific message IDs (used by federation)
    */
@@ -0,0 +1,175 @@
+// BotNet Agent Capture
+// Time-boxed, in-memory capture of one agent's full requests/responses (secrets scrubbed), exported as a HAR bundle
+
+import { TrafficRecorder } from "./traffic-recorder.js";
+import type { Logger } from "../logger.js";
+
+export interface CapturedRequest {
+  url: string;
+  clientIP: string;
+  headers: Record<string, string | string[] | undefined>;
+  request: any;
+  response: any;
+  statusCode: number;
+  durationMs: number;
+}
+
+export interface CaptureStatus {
+  agentId: string;
+  reason: string;
+  startedAt: string;
+  expiresAt: string;
+  active: boolean;
+  captured: number;
+  dropped: number; // Requests seen after the entry cap was reached
+}
+
+interface CaptureSession {
+  agentId: string;
+  reason: string;
+  startedAt: number;
+  expiresAt: number;
+  entries: any[];
+  dropped: number;
+}
+
+export class AgentCapture {
+  private sessions: Map<string, CaptureSession> = new Map();
+  private readonly MAX_DURATION_MINUTES = 60;
+  private readonly MAX_ENTRIES = 500; // Per session, so a chatty agent can't grow memory unbounded
+  private readonly MAX_BODY_CHARS = 64 * 1024;
+
+  constructor(private logger: Logger) {}
+
+  /**
+   * Start (or restart) capturing an agent's requests for the given number of minutes
+   */
+  start(agentId: string, minutes: number, reason: string): CaptureStatus {
+    const duration = Math.min(Math.max(1, Math.floor(minutes)), this.MAX_DURATION_MINUTES);
+    const now = Date.now();
+    const session: CaptureSession = {
+      agentId,
+      reason,
+      startedAt: now,
+      expiresAt: now + duration * 60 * 1000,
+      entries: [],
+      dropped: 0
+    };
+    this.sessions.set(agentId, session);
+    this.logger.warn(`🎥 Capturing requests from ${agentId} for ${duration} minutes`, { reason });
+    return this.toStatus(session);
+  }
+
+  /**
+   * End a capture early; captured entries stay available for export until cleared
+   */
+  stop(agentId: string): CaptureStatus | undefined {
+    const session = this.sessions.get(agentId);
+    if (!session) {
+      return undefined;
+    }
+    session.expiresAt = Math.min(session.expiresAt, Date.now());
+    return this.toStatus(session);
+  }
+
+  clear(agentId: string): boolean {
+    return this.sessions.delete(agentId);
+  }
+
+  isCapturing(agentId: string): boolean {
+    const session = this.sessions.get(agentId);
+    return !!session && Date.now() < session.expiresAt;
+  }
+
+  /**
+   * Record one request/response pair if a capture is running for this agent
+   */
+  capture(agentId: string | undefined, exchange: CapturedRequest): void {
+    if (!agentId || !this.isCapturing(agentId)) {
+      return;
+    }
+    const session = this.sessions.get(agentId)!;
+    if (session.entries.length >= this.MAX_ENTRIES) {
+      session.dropped++;
+      return;
+    }
+    session.entries.push(this.toHarEntry(exchange));
+  }
+
+  getStatus(): CaptureStatus[] {
+    return [...this.sessions.values()].map(session => this.toStatus(session));
+  }
+
+  /**
+   * HAR 1.2 bundle of an agent's captured requests, loadable in browser devtools and HAR viewers
+   */
+  export(agentId: string): any | undefined {
+    const session = this.sessions.get(agentId);
+    if (!session) {
+      return undefined;
+    }
+    return {
+      log: {
+        version: '1.2',
+        creator: { name: 'BotNet', version: '1.0.0' },
+        comment: `Capture of ${agentId}: ${session.reason}`,
+        entries: session.entries
+      }
+    };
+  }
+
+  private toHarEntry(exchange: CapturedRequest): any {
+    const requestText = this.truncate(JSON.stringify(TrafficRecorder.scrub(exchange.request)));
+    const responseText = this.truncate(JSON.stringify(TrafficRecorder.scrub(exchange.response)));
+    const headers = TrafficRecorder.scrub(exchange.headers) as Record<string, string | string[] | undefined>;
+
+    return {
+      startedDateTime: new Date(Date.now() - exchange.durationMs).toISOString(),
+      time: exchange.durationMs,
+      request: {
+        method: 'POST',
+        url: exchange.url,
+        httpVersion: 'HTTP/1.1',
+        headers: Object.entries(headers)
+          .filter(([, value]) => value !== undefined)
+          .map(([name, value]) => ({ name, value: Array.isArray(value) ? value.join(', ') : String(value) })),
+        queryString: [],
+        cookies: [],
+        postData: { mimeType: 'application/json', text: requestText },
+        headersSize: -1,
+        bodySize: requestText.length
+      },
+      response: {
+        status: exchange.statusCode,
+        statusText: '',
+        httpVersion: 'HTTP/1.1',
+        headers: [{ name: 'content-type', value: 'application/json' }],
+        cookies: [],
+        content: { size: responseText.length, mimeType: 'application/json', text: responseText },
+        redirectURL: '',
+        headersSize: -1,
+        bodySize: responseText.length
+      },
+      cache: {},
+      timings: { send: 0, wait: exchange.durationMs, receive: 0 },
+      _clientIP: exchange.clientIP,
+      _method: exchange.request?.method
+    };
+  }
+
+  private truncate(text: string): string {
+    return text.length > this.MAX_BODY_CHARS ? text.slice(0, this.MAX_BODY_CHARS) + '…[truncated]' : text;
+  }
+
+  private toStatus(session: CaptureSession): CaptureStatus {
+    return {
+      agentId: session.agentId,
+      reason: session.reason,
+      startedAt: new Date(session.startedAt).toISOString(),
+      expiresAt: new Date(session.expiresAt).toISOString(),
+      active: Date.now() < session.expiresAt,
+      captured: session.entries.length,
+      dropped: session.dropped
+    };
+  }
+}
@@ -0,0 +1,111 @@
+import { describe, it, expect, beforeEach, afterEach, jest } from '@jest/globals';
+import { AnomalyDetector } from './anomaly-detector.js';
+import { ProviderHealth } from './provider-health.js';
+import type { Logger } from '../logger.js';
+
+const mockLogger: Logger = {
+  debug: jest.fn(),
+  info: jest.fn(),
+  warn: jest.fn(),
+  error: jest.fn(),
+  child: jest.fn(() => mockLogger),
+} as any;
+
+describe('AnomalyDetector', () => {
+  let now: number;
+  let clock: ReturnType<typeof jest.spyOn>;
+  let auditService: { record: ReturnType<typeof jest.fn> };
+  let detector: AnomalyDetector;
+
+  beforeEach(() => {
+    now = Date.parse('2026-01-01T00:00:00Z');
+    clock = jest.spyOn(Date, 'now').mockImplementation(() => now);
+    auditService = { record: jest.fn() };
+    detector = new AnomalyDetector({
+      logger: mockLogger,
+      auditService: auditService as any,
+      nodeDomain: 'botnet.bob.com',
+      providers: new ProviderHealth(mockLogger),
+      thresholds: { request_spike: 3, auth_failures: 2, signature_failures: 2, flapping: 2 }
+    });
+  });
+
+  afterEach(() => {
+    clock.mockRestore();
+  });
+
+  it('alerts once a source crosses its threshold within the window', () => {
+    for (let i = 0; i < 3; i++) {
+      detector.observe('request_spike', '10.0.0.1');
+    }
+    expect(detector.getRecentAlerts()).toEqual([]);
+
+    detector.observe('request_spike', '10.0.0.1');
+    expect(detector.getRecentAlerts()).toEqual([
+      expect.objectContaining({ kind: 'request_spike', source: '10.0.0.1', count: 4, threshold: 3, windowSeconds: 60 })
+    ]);
+    expect(auditService.record).toHaveBeenCalledWith('anomaly.detected', expect.objectContaining({ actor: '10.0.0.1', outcome: 'failure' }));
+  });
+
+  it('forgets observations older than the window', () => {
+    detector.observe('signature_failures', 'botnet.eve.com');
+    detector.observe('signature_failures', 'botnet.eve.com');
+    now += 61 * 1000;
+    detector.observe('signature_failures', 'botnet.eve.com');
+    expect(detector.getRecentAlerts()).toEqual([]);
+  });
+
+  it('does not repeat an alert during its cooldown', () => {
+    for (let i = 0; i < 5; i++) {
+      detector.observe('signature_failures', 'botnet.eve.com');
+    }
+    expect(detector.getRecentAlerts()).toHaveLength(1);
+
+    now += 16 * 60 * 1000;
+    for (let i = 0; i < 3; i++) {
+      detector.observe('signature_failures', 'botnet.eve.com');
+    }
+    expect(detector.getRecentAlerts()).toHaveLength(2);
+  });
+
+  it('ignores kinds with a zero threshold', () => {
+    const disabled = new AnomalyDetector({
+      logger: mockLogger,
+      nodeDomain: 'botnet.bob.com',
+      providers: new ProviderHealth(mockLogger),
+      thresholds: { request_spike: 0, auth_failures: 0, signature_failures: 0, flapping: 0 }
+    });
+    for (let i = 0; i < 10; i++) {
+      disabled.observe('auth_failures', '10.0.0.1');
+    }
+    expect(disabled.getRecentAlerts()).toEqual([]);
+  });
+
+  it('counts reachability changes toward flapping over the longer window', () => {
+    detector.observeReachability('botnet.alice.com', true);
+    detector.observeReachability('botnet.alice.com', true);
+    detector.observeReachability('botnet.alice.com', false);
+    now += 20 * 60 * 1000;
+    detector.observeReachability('botnet.alice.com', true);
+    expect(detector.getRecentAlerts()).toEqual([]);
+
+    now += 20 * 60 * 1000;
+    detector.observeReachability('botnet.alice.com', false);
+    expect(detector.getRecentAlerts()).toEqual([
+      expect.objectContaining({ kind: 'flapping', source: 'botnet.alice.com', count: 3, windowSeconds: 3600 })
+    ]);
+  });
+
+  it('forgets peer states on cleanup but keeps recent flaps', () => {
+    detector.observeReachability('botnet.alice.com', true);
+    detector.observeReachability('botnet.alice.com', false);
+    detector.observeReachability('botnet.alice.com', true);
+    detector.cleanup();
+
+    // The first call after cleanup only records the state
+    detector.observeReachability('botnet.alice.com', false);
+    expect(detector.getRecentAlerts()).toEqual([]);
+    detector.observeReachability('botnet.alice.com', true);
+    expect(detector.getRecentAlerts()).toHaveLength(1);
+  });
+});
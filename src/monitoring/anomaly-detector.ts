@@ -0,0 +1,155 @@
+// BotNet Anomaly Detector
+// Flags unusual federation behavior (request spikes, auth and signature failure bursts, flapping peers) and raises alerts
+
+import type { Logger } from "../logger.js";
+import type { AuditService } from "../audit/audit-service.js";
+import type { ProviderHealth } from "./provider-health.js";
+
+export type AnomalyKind = 'request_spike' | 'auth_failures' | 'signature_failures' | 'flapping';
+
+export interface AnomalyAlert {
+  kind: AnomalyKind;
+  source: string; // Neighbor domain or client IP
+  count: number;
+  threshold: number;
+  windowSeconds: number;
+  detectedAt: string;
+}
+
+export interface AnomalyDetectorOptions {
+  logger: Logger;
+  auditService?: AuditService;
+  nodeDomain: string;
+  webhookUrl?: string;
+  providers: ProviderHealth; // Circuit breaking for the alert webhook
+  thresholds: Record<AnomalyKind, number>; // Events per window before alerting (0 disables)
+  windowMs?: number;
+  flapWindowMs?: number; // Reachability changes are counted over a longer window
+  cooldownMs?: number;
+}
+
+export class AnomalyDetector {
+  private events: Map<string, number[]> = new Map();
+  private lastAlertAt: Map<string, number> = new Map();
+  private recentAlerts: AnomalyAlert[] = [];
+  private reachable: Map<string, boolean> = new Map();
+  private readonly windowMs: number;
+  private readonly flapWindowMs: number;
+  private readonly cooldownMs: number;
+  private readonly MAX_RECENT_ALERTS = 50;
+  private readonly MAX_TRACKED_PEERS = 10000;
+
+  constructor(private options: AnomalyDetectorOptions) {
+    this.windowMs = options.windowMs || 60 * 1000;
+    this.flapWindowMs = options.flapWindowMs || 60 * 60 * 1000;
+    this.cooldownMs = options.cooldownMs || 15 * 60 * 1000; // Don't repeat the same alert for 15 minutes
+    if (options.webhookUrl) {
+      options.providers.register('alert_webhook');
+    }
+  }
+
+  /**
+   * Record one observation for a source and alert if it crosses its threshold
+   */
+  observe(kind: AnomalyKind, source: string): void {
+    const threshold = this.options.thresholds[kind];
+    if (!threshold || threshold <= 0) {
+      return;
+    }
+
+    const now = Date.now();
+    const key = `${kind}:${source}`;
+    const windowMs = this.windowFor(kind);
+    const timestamps = (this.events.get(key) || []).filter(t => now - t < windowMs);
+    timestamps.push(now);
+    this.events.set(key, timestamps);
+
+    if (timestamps.length <= threshold) {
+      return;
+    }
+
+    const lastAlert = this.lastAlertAt.get(key);
+    if (lastAlert && now - lastAlert < this.cooldownMs) {
+      return;
+    }
+
+    this.lastAlertAt.set(key, now);
+    this.raiseAlert({
+      kind,
+      source,
+      count: timestamps.length,
+      threshold,
+      windowSeconds: windowMs / 1000,
+      detectedAt: new Date(now).toISOString()
+    });
+  }
+
+  /**
+   * Record the outcome of a call to a peer; each change between reachable and unreachable counts toward flapping
+   */
+  observeReachability(peer: string, reachable: boolean): void {
+    const previous = this.reachable.get(peer);
+    if (previous === undefined && this.reachable.size >= this.MAX_TRACKED_PEERS) {
+      return;
+    }
+    this.reachable.set(peer, reachable);
+    if (previous !== undefined && previous !== reachable) {
+      this.observe('flapping', peer);
+    }
+  }
+
+  /**
+   * Alerts raised since startup (newest first)
+   */
+  getRecentAlerts(): AnomalyAlert[] {
+    return [...this.recentAlerts];
+  }
+
+  /**
+   * Drop observation windows that have gone quiet (garbage collection)
+   */
+  cleanup(): void {
+    const now = Date.now();
+    for (const [key, timestamps] of this.events.entries()) {
+      const windowMs = this.windowFor(key.slice(0, key.indexOf(':')) as AnomalyKind);
+      if (!timestamps.length || now - timestamps[timestamps.length - 1] > windowMs) {
+        this.events.delete(key);
+      }
+    }
+    // Peers are tracked again from their next call
+    this.reachable.clear();
+    for (const [key, alertedAt] of this.lastAlertAt.entries()) {
+      if (now - alertedAt > this.cooldownMs) {
+        this.lastAlertAt.delete(key);
+      }
+    }
+  }
+
+  private windowFor(kind: AnomalyKind): number {
+    return kind === 'flapping' ? this.flapWindowMs : this.windowMs;
+  }
+
+  private raiseAlert(alert: AnomalyAlert): void {
+    this.recentAlerts.unshift(alert);
+    this.recentAlerts.length = Math.min(this.recentAlerts.length, this.MAX_RECENT_ALERTS);
+
+    this.options.logger.warn('🚨 Anomaly detected', alert);
+    this.options.auditService?.record('anomaly.detected', {
+      actor: alert.source,
+      outcome: 'failure',
+      details: alert
+    });
+
+    if (this.options.webhookUrl) {
+      // Fire and forget - alert delivery must never block request handling
+      void this.options.providers.send('alert_webhook', this.options.webhookUrl, {
+        method: 'POST',
+        headers: {
+          'Content-Type': 'application/json',
+          'User-Agent': 'BotNet-Alerts/1.0.0'
+        },
+        body: JSON.stringify({ node: this.options.nodeDomain, ...alert })
+      });
+    }
+  }
+}
@@ -0,0 +1,98 @@
+// BotNet Bandwidth Meter
+// Per-peer daily byte counters for federation traffic, with an optional daily cap
+
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+
+export interface BandwidthUsage {
+  domain: string;
+  day: string; // YYYY-MM-DD (UTC)
+  bytes_in: number;
+  bytes_out: number;
+}
+
+export class BandwidthMeter {
+  constructor(
+    private database: Database.Database,
+    private logger: Logger,
+    private dailyCapBytes: number // 0 = unlimited
+  ) {}
+
+  /**
+   * Add bytes exchanged with a peer to today's counters
+   */
+  record(domain: string, bytesIn: number, bytesOut: number): void {
+    const wasOverCap = this.isOverCap(domain);
+
+    this.database.prepare(`
+      INSERT INTO peer_bandwidth (domain, day, bytes_in, bytes_out) VALUES (?, ?, ?, ?)
+      ON CONFLICT(domain, day) DO UPDATE SET
+        bytes_in = bytes_in + excluded.bytes_in,
+        bytes_out = bytes_out + excluded.bytes_out
+    `).run(domain, BandwidthMeter.today(), bytesIn, bytesOut);
+
+    if (!wasOverCap && this.isOverCap(domain)) {
+      this.logger.warn('📶 Peer reached daily bandwidth cap', {
+        domain,
+        capMB: Math.round(this.dailyCapBytes / (1024 * 1024))
+      });
+    }
+  }
+
+  /**
+   * Whether the peer has used up today's transfer budget (in + out)
+   */
+  isOverCap(domain: string): boolean {
+    if (this.dailyCapBytes <= 0) {
+      return false;
+    }
+
+    const row = this.database.prepare(`
+      SELECT bytes_in + bytes_out AS total FROM peer_bandwidth WHERE domain = ? AND day = ?
+    `).get(domain, BandwidthMeter.today()) as { total: number } | undefined;
+
+    return !!row && row.total >= this.dailyCapBytes;
+  }
+
+  /**
+   * Daily usage for one peer, or totals per peer, over the last N days
+   */
+  getUsage(domain?: string, days: number = 7): BandwidthUsage[] {
+    const since = new Date(Date.now() - (days - 1) * 24 * 60 * 60 * 1000).toISOString().slice(0, 10);
+
+    if (domain) {
+      return this.database.prepare(`
+        SELECT domain, day, bytes_in, bytes_out FROM peer_bandwidth
+        WHERE domain = ? AND day >= ?
+        ORDER BY day DESC
+      `).all(domain, since) as BandwidthUsage[];
+    }
+
+    return this.database.prepare(`
+      SELECT domain, MAX(day) AS day, SUM(bytes_in) AS bytes_in, SUM(bytes_out) AS bytes_out
+      FROM peer_bandwidth
+      WHERE day >= ?
+      GROUP BY domain
+      ORDER BY (SUM(bytes_in) + SUM(bytes_out)) DESC
+    `).all(since) as BandwidthUsage[];
+  }
+
+  getDailyCapBytes(): number {
+    return this.dailyCapBytes;
+  }
+
+  /**
+   * Drop counters older than the retention window
+   */
+  cleanup(retentionDays: number = 30): number {
+    const cutoff = new Date(Date.now() - retentionDays * 24 * 60 * 60 * 1000).toISOString().slice(0, 10);
+    const result = this.database.prepare(`
+      DELETE FROM peer_bandwidth WHERE day < ?
+    `).run(cutoff);
+    return result.changes;
+  }
+
+  private static today(): string {
+    return new Date().toISOString().slice(0, 10);
+  }
+}
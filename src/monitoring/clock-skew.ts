@@ -0,0 +1,80 @@
+// BotNet Clock Skew Monitor
+// Estimates local clock offset from the HTTP Date header of federation responses
+
+import type { Logger } from "../logger.js";
+
+export interface ClockSkewStatus {
+  estimatedSkewMs: number | null; // Positive = our clock is ahead of peers
+  peers: number;
+  skewed: boolean;
+}
+
+export class ClockSkewMonitor {
+  private samples: Map<string, { skewMs: number; sampledAt: number }> = new Map();
+  private readonly SAMPLE_TTL_MS = 60 * 60 * 1000; // Ignore peers not heard from in the last hour
+  private skewed = false;
+
+  constructor(
+    private logger: Logger,
+    private thresholdMs: number
+  ) {}
+
+  /**
+   * Record a peer's Date header, compensating for half the round trip
+   */
+  observe(peer: string, dateHeader: string | null, sentAt: number, receivedAt: number): void {
+    const remoteTime = dateHeader ? Date.parse(dateHeader) : NaN;
+    if (isNaN(remoteTime)) {
+      return;
+    }
+
+    // Date has one-second resolution, so anything under ~1s is noise
+    const localMidpoint = sentAt + (receivedAt - sentAt) / 2;
+    this.samples.set(peer, { skewMs: Math.round(localMidpoint - remoteTime), sampledAt: receivedAt });
+    this.evaluate();
+  }
+
+  getStatus(): ClockSkewStatus {
+    const estimatedSkewMs = this.estimate();
+    return {
+      estimatedSkewMs,
+      peers: this.freshSamples().length,
+      skewed: estimatedSkewMs !== null && Math.abs(estimatedSkewMs) > this.thresholdMs
+    };
+  }
+
+  private evaluate(): void {
+    const status = this.getStatus();
+    if (status.skewed === this.skewed) {
+      return;
+    }
+    this.skewed = status.skewed;
+
+    if (status.skewed) {
+      this.logger.warn('⏰ Local clock disagrees with federation peers - check NTP', {
+        estimatedSkewMs: status.estimatedSkewMs,
+        peers: status.peers,
+        thresholdMs: this.thresholdMs
+      });
+    } else {
+      this.logger.info('⏰ Local clock back in sync with federation peers', { estimatedSkewMs: status.estimatedSkewMs });
+    }
+  }
+
+  /**
+   * Median across peers, so one peer with a bad clock doesn't raise the alarm
+   */
+  private estimate(): number | null {
+    const skews = this.freshSamples().sort((a, b) => a - b);
+    if (!skews.length) {
+      return null;
+    }
+    const middle = Math.floor(skews.length / 2);
+    return skews.length % 2 ? skews[middle] : Math.round((skews[middle - 1] + skews[middle]) / 2);
+  }
+
+  private freshSamples(): number[] {
+    const cutoff = Date.now() - this.SAMPLE_TTL_MS;
+    return [...this.samples.values()].filter(sample => sample.sampledAt >= cutoff).map(sample => sample.skewMs);
+  }
+}
@@ -0,0 +1,145 @@
+// BotNet Self-Diagnostics
+// One-shot checks behind the botnet_doctor tool: DNS, TLS, public reachability, storage, clock and load
+
+import { promises as dns } from "dns";
+import tls from "tls";
+import type Database from "better-sqlite3";
+import type { ClockSkewMonitor } from "./clock-skew.js";
+import type { LoadMonitor } from "./load-monitor.js";
+
+export type DiagnosticStatus = 'ok' | 'warn' | 'fail' | 'skip';
+
+export interface DiagnosticCheck {
+  name: string;
+  status: DiagnosticStatus;
+  detail: string;
+}
+
+export interface DiagnosticsOptions {
+  domain: string;
+  database: Database.Database;
+  clock: ClockSkewMonitor;
+  load: LoadMonitor;
+  timeoutMs?: number;
+}
+
+const CERT_EXPIRY_WARN_DAYS = 14;
+const STORAGE_WARN_MS = 50;
+
+export async function runDiagnostics(options: DiagnosticsOptions): Promise<DiagnosticCheck[]> {
+  const federated = options.domain.startsWith('botnet.');
+  const checks: DiagnosticCheck[] = [];
+
+  checks.push(federated ? await checkDns(options.domain) : skip('dns', 'Local node (domain does not start with botnet.)'));
+  checks.push(federated ? await checkTls(options.domain, options.timeoutMs || 5000) : skip('tls', 'Local node'));
+  checks.push(federated ? await checkReachability(options.domain, options.timeoutMs || 5000) : skip('reachability', 'Local node'));
+  checks.push(checkStorage(options.database));
+  checks.push(checkClock(options.clock));
+  checks.push(checkLoad(options.load));
+
+  return checks;
+}
+
+async function checkDns(domain: string): Promise<DiagnosticCheck> {
+  const [ipv4, ipv6] = await Promise.all([
+    dns.resolve4(domain).catch(() => [] as string[]),
+    dns.resolve6(domain).catch(() => [] as string[])
+  ]);
+
+  if (!ipv4.length && !ipv6.length) {
+    return { name: 'dns', status: 'fail', detail: `No A or AAAA record for ${domain}` };
+  }
+  const records = [...ipv4.map(ip => `A ${ip}`), ...ipv6.map(ip => `AAAA ${ip}`)].join(', ');
+  return { name: 'dns', status: 'ok', detail: records };
+}
+
+function checkTls(domain: string, timeoutMs: number): Promise<DiagnosticCheck> {
+  return new Promise(resolve => {
+    const socket = tls.connect({ host: domain, port: 443, servername: domain, timeout: timeoutMs }, () => {
+      const certificate = socket.getPeerCertificate();
+      socket.end();
+
+      if (!socket.authorized) {
+        resolve({ name: 'tls', status: 'fail', detail: `Certificate not trusted: ${socket.authorizationError}` });
+        return;
+      }
+      const daysLeft = Math.floor((Date.parse(certificate.valid_to) - Date.now()) / (24 * 60 * 60 * 1000));
+      resolve({
+        name: 'tls',
+        status: daysLeft < CERT_EXPIRY_WARN_DAYS ? 'warn' : 'ok',
+        detail: `Certificate valid until ${certificate.valid_to} (${daysLeft} days)`
+      });
+    });
+
+    socket.on('timeout', () => {
+      socket.destroy();
+      resolve({ name: 'tls', status: 'fail', detail: `TLS handshake with ${domain}:443 timed out` });
+    });
+    socket.on('error', error => {
+      resolve({ name: 'tls', status: 'fail', detail: error.message });
+    });
+  });
+}
+
+/**
+ * Probe our own public /health - this goes out through DNS and the reverse proxy,
+ * so it catches most misconfigurations, but not firewalls that only block outside traffic
+ */
+async function checkReachability(domain: string, timeoutMs: number): Promise<DiagnosticCheck> {
+  const startedAt = Date.now();
+  try {
+    const response = await fetch(`https://${domain}/health`, { signal: AbortSignal.timeout(timeoutMs) });
+    const elapsed = Date.now() - startedAt;
+    if (!response.ok) {
+      return { name: 'reachability', status: 'fail', detail: `https://${domain}/health returned HTTP ${response.status}` };
+    }
+    return { name: 'reachability', status: 'ok', detail: `https://${domain}/health answered in ${elapsed}ms` };
+  } catch (error) {
+    return { name: 'reachability', status: 'fail', detail: error instanceof Error ? error.message : String(error) };
+  }
+}
+
+function checkStorage(database: Database.Database): DiagnosticCheck {
+  try {
+    const startedAt = process.hrtime.bigint();
+    const integrity = database.pragma('quick_check', { simple: true });
+    database.prepare("SELECT COUNT(*) FROM migrations").get();
+    const elapsedMs = Number(process.hrtime.bigint() - startedAt) / 1e6;
+
+    if (integrity !== 'ok') {
+      return { name: 'storage', status: 'fail', detail: `SQLite quick_check: ${integrity}` };
+    }
+    return {
+      name: 'storage',
+      status: elapsedMs > STORAGE_WARN_MS ? 'warn' : 'ok',
+      detail: `SQLite integrity ok, check took ${elapsedMs.toFixed(1)}ms`
+    };
+  } catch (error) {
+    return { name: 'storage', status: 'fail', detail: error instanceof Error ? error.message : String(error) };
+  }
+}
+
+function checkClock(clock: ClockSkewMonitor): DiagnosticCheck {
+  const status = clock.getStatus();
+  if (status.estimatedSkewMs === null) {
+    return skip('clock', 'No federation responses sampled yet');
+  }
+  return {
+    name: 'clock',
+    status: status.skewed ? 'warn' : 'ok',
+    detail: `Estimated skew ${status.estimatedSkewMs}ms across ${status.peers} peer(s)`
+  };
+}
+
+function checkLoad(load: LoadMonitor): DiagnosticCheck {
+  const snapshot = load.getSnapshot();
+  return {
+    name: 'load',
+    status: snapshot.overloaded ? 'warn' : 'ok',
+    detail: `Heap ${snapshot.heapUsedMB}MB, event loop p99 ${snapshot.eventLoopDelayMs}ms${snapshot.reason ? ` (shedding: ${snapshot.reason})` : ''}`
+  };
+}
+
+function skip(name: string, detail: string): DiagnosticCheck {
+  return { name, status: 'skip', detail };
+}
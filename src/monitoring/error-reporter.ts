@@ -0,0 +1,123 @@
+// BotNet Error Reporter
+// Ships handler and background-task errors to a Sentry DSN or a generic JSON webhook
+
+import { randomBytes } from "crypto";
+import type { Logger } from "../logger.js";
+import type { ProviderHealth } from "./provider-health.js";
+
+export interface ErrorContext {
+  source: string; // e.g. "mcp", "http", "job:token-cleanup"
+  [key: string]: any;
+}
+
+export interface ErrorReporterOptions {
+  logger: Logger;
+  nodeDomain: string;
+  sinkUrl?: string; // Sentry DSN (https://<key>@host/<project>) or any webhook URL
+  providers: ProviderHealth; // Circuit breaking for the sink
+}
+
+export class ErrorReporter {
+  private recentlyReported: Map<string, number> = new Map();
+  private readonly DEDUP_WINDOW_MS = 60 * 1000; // Same fingerprint at most once a minute
+  private readonly target?: { url: string; headers: Record<string, string>; sentry: boolean };
+
+  constructor(private options: ErrorReporterOptions) {
+    if (options.sinkUrl) {
+      this.target = ErrorReporter.resolveTarget(options.sinkUrl);
+      options.providers.register('error_sink');
+    }
+  }
+
+  /**
+   * Report an error (fire and forget - never throws)
+   */
+  report(error: unknown, context: ErrorContext): void {
+    if (!this.target) {
+      return;
+    }
+
+    const err = error instanceof Error ? error : new Error(String(error));
+    const fingerprint = ErrorReporter.fingerprint(err, context).join(':');
+    const now = Date.now();
+    const lastReported = this.recentlyReported.get(fingerprint);
+    if (lastReported && now - lastReported < this.DEDUP_WINDOW_MS) {
+      return;
+    }
+    this.recentlyReported.set(fingerprint, now);
+    this.pruneRecent(now);
+
+    const body = this.target.sentry
+      ? this.buildSentryEvent(err, context)
+      : {
+          node: this.options.nodeDomain,
+          source: context.source,
+          message: err.message,
+          type: err.name,
+          stack: err.stack,
+          context,
+          timestamp: new Date(now).toISOString()
+        };
+
+    void this.options.providers.send('error_sink', this.target.url, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json', 'User-Agent': 'BotNet-Errors/1.0.0', ...this.target.headers },
+      body: JSON.stringify(body)
+    });
+  }
+
+  private buildSentryEvent(err: Error, context: ErrorContext): any {
+    const { source, ...extra } = context;
+    return {
+      event_id: randomBytes(16).toString('hex'),
+      timestamp: new Date().toISOString(),
+      platform: 'node',
+      level: 'error',
+      server_name: this.options.nodeDomain,
+      exception: {
+        values: [{ type: err.name, value: err.message }]
+      },
+      tags: { source },
+      fingerprint: ErrorReporter.fingerprint(err, context),
+      extra: { ...extra, stack: err.stack }
+    };
+  }
+
+  /**
+   * Errors from a method group by error class, since their messages embed request data;
+   * background job errors group by message
+   */
+  private static fingerprint(err: Error, context: ErrorContext): string[] {
+    return context.method
+      ? [context.source, String(context.method), err.name]
+      : [context.source, err.message];
+  }
+
+  private pruneRecent(now: number): void {
+    for (const [fingerprint, reportedAt] of this.recentlyReported.entries()) {
+      if (now - reportedAt > this.DEDUP_WINDOW_MS) {
+        this.recentlyReported.delete(fingerprint);
+      }
+    }
+  }
+
+  /**
+   * A URL with a public key in the userinfo is treated as a Sentry DSN
+   */
+  private static resolveTarget(sinkUrl: string): { url: string; headers: Record<string, string>; sentry: boolean } {
+    const parsed = new URL(sinkUrl);
+    const projectId = parsed.pathname.replace(/^\/+|\/+$/g, '');
+
+    if (parsed.username && projectId) {
+      return {
+        url: `${parsed.protocol}//${parsed.host}/api/${projectId}/store/`,
+        headers: {
+          'X-Sentry-Auth': `Sentry sentry_version=7, sentry_key=${parsed.username}, sentry_client=botnet/1.0.0`
+        },
+        sentry: true
+      };
+    }
+
+    return { url: sinkUrl, headers: {}, sentry: false };
+  }
+}
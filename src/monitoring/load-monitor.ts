@@ -0,0 +1,76 @@
+// BotNet Load Monitor
+// Tracks heap usage and event loop delay so low-priority work can be shed under pressure
+
+import { monitorEventLoopDelay, type IntervalHistogram } from "perf_hooks";
+import type { Logger } from "../logger.js";
+
+export interface LoadSnapshot {
+  heapUsedMB: number;
+  eventLoopDelayMs: number; // p99 over the last sample window
+  overloaded: boolean;
+  reason?: string;
+}
+
+export interface LoadMonitorOptions {
+  logger: Logger;
+  maxHeapMB: number;          // 0 disables the heap watermark
+  maxEventLoopDelayMs: number; // 0 disables the event loop check
+  sampleIntervalMs?: number;
+}
+
+export class LoadMonitor {
+  private histogram: IntervalHistogram;
+  private timer: NodeJS.Timeout;
+  private snapshot: LoadSnapshot = { heapUsedMB: 0, eventLoopDelayMs: 0, overloaded: false };
+
+  // Seconds clients are told to wait in Retry-After when work is shed
+  readonly retryAfterSeconds = 30;
+
+  constructor(private options: LoadMonitorOptions) {
+    this.histogram = monitorEventLoopDelay({ resolution: 20 });
+    this.histogram.enable();
+
+    this.timer = setInterval(() => this.sample(), options.sampleIntervalMs || 5000);
+    this.timer.unref(); // Never keep the process alive just to monitor it
+  }
+
+  /**
+   * Whether low-priority work (gossip exchange, backfill, landing page) should be refused
+   */
+  shouldShed(): boolean {
+    return this.snapshot.overloaded;
+  }
+
+  getSnapshot(): LoadSnapshot {
+    return { ...this.snapshot };
+  }
+
+  stop(): void {
+    clearInterval(this.timer);
+    this.histogram.disable();
+  }
+
+  private sample(): void {
+    const heapUsedMB = Math.round(process.memoryUsage().heapUsed / (1024 * 1024));
+    const eventLoopDelayMs = Math.round(this.histogram.percentile(99) / 1e6);
+    this.histogram.reset();
+
+    let reason: string | undefined;
+    if (this.options.maxHeapMB > 0 && heapUsedMB > this.options.maxHeapMB) {
+      reason = `heap ${heapUsedMB}MB > ${this.options.maxHeapMB}MB`;
+    } else if (this.options.maxEventLoopDelayMs > 0 && eventLoopDelayMs > this.options.maxEventLoopDelayMs) {
+      reason = `event loop delay ${eventLoopDelayMs}ms > ${this.options.maxEventLoopDelayMs}ms`;
+    }
+
+    const overloaded = !!reason;
+    if (overloaded !== this.snapshot.overloaded) {
+      if (overloaded) {
+        this.options.logger.warn('🔥 Node under pressure, shedding low-priority work', { reason, heapUsedMB, eventLoopDelayMs });
+      } else {
+        this.options.logger.info('✅ Node load back to normal', { heapUsedMB, eventLoopDelayMs });
+      }
+    }
+
+    this.snapshot = { heapUsedMB, eventLoopDelayMs, overloaded, reason };
+  }
+}
@@ -0,0 +1,108 @@
+// BotNet Provider Health
+// Tracks external integrations (webhook sinks) and stops calling ones that keep failing
+
+import type { Logger } from "../logger.js";
+
+export type ProviderState = 'ok' | 'degraded' | 'open';
+
+export interface ProviderStatus {
+  name: string;
+  state: ProviderState; // open = circuit open, calls are skipped until the cooldown ends
+  consecutiveFailures: number;
+  lastSuccessAt?: string;
+  lastFailureAt?: string;
+  lastError?: string;
+  skipped: number; // Calls dropped while the circuit was open
+}
+
+interface ProviderRecord {
+  consecutiveFailures: number;
+  openedAt?: number;
+  lastSuccessAt?: number;
+  lastFailureAt?: number;
+  lastError?: string;
+  skipped: number;
+}
+
+export class ProviderHealth {
+  private providers: Map<string, ProviderRecord> = new Map();
+  private readonly FAILURE_THRESHOLD = 3; // Consecutive failures before the circuit opens
+  private readonly COOLDOWN_MS = 5 * 60 * 1000; // Then one trial call every 5 minutes
+  private readonly TIMEOUT_MS = 10000;
+
+  constructor(private logger: Logger) {}
+
+  /**
+   * Register a configured provider so it shows up in health output before its first call
+   */
+  register(name: string): void {
+    if (!this.providers.has(name)) {
+      this.providers.set(name, { consecutiveFailures: 0, skipped: 0 });
+    }
+  }
+
+  /**
+   * POST to a provider, unless its circuit is open. Never throws; resolves false when not delivered
+   */
+  async send(name: string, url: string, init: RequestInit): Promise<boolean> {
+    this.register(name);
+    const record = this.providers.get(name)!;
+
+    if (record.openedAt && Date.now() - record.openedAt < this.COOLDOWN_MS) {
+      record.skipped++;
+      return false;
+    }
+
+    try {
+      const response = await fetch(url, { ...init, signal: AbortSignal.timeout(this.TIMEOUT_MS) });
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}`);
+      }
+      this.recordSuccess(name, record);
+      return true;
+    } catch (error) {
+      this.recordFailure(name, record, error instanceof Error ? error.message : String(error));
+      return false;
+    }
+  }
+
+  getStatus(): ProviderStatus[] {
+    return [...this.providers.entries()].map(([name, record]) => ({
+      name,
+      state: record.openedAt ? 'open' : record.consecutiveFailures > 0 ? 'degraded' : 'ok',
+      consecutiveFailures: record.consecutiveFailures,
+      lastSuccessAt: record.lastSuccessAt ? new Date(record.lastSuccessAt).toISOString() : undefined,
+      lastFailureAt: record.lastFailureAt ? new Date(record.lastFailureAt).toISOString() : undefined,
+      lastError: record.lastError,
+      skipped: record.skipped
+    }));
+  }
+
+  private recordSuccess(name: string, record: ProviderRecord): void {
+    if (record.openedAt) {
+      this.logger.info(`🔌 Provider ${name} recovered`, { skipped: record.skipped });
+    }
+    record.consecutiveFailures = 0;
+    record.openedAt = undefined;
+    record.lastSuccessAt = Date.now();
+  }
+
+  private recordFailure(name: string, record: ProviderRecord, message: string): void {
+    record.consecutiveFailures++;
+    record.lastFailureAt = Date.now();
+    record.lastError = message;
+
+    if (record.consecutiveFailures >= this.FAILURE_THRESHOLD) {
+      if (!record.openedAt) {
+        this.logger.warn(`🔌 Provider ${name} failing, pausing calls for ${this.COOLDOWN_MS / 60000} minutes`, {
+          consecutiveFailures: record.consecutiveFailures,
+          error: message
+        });
+      }
+      // Restart the cooldown, including after a failed trial call
+      record.openedAt = Date.now();
+    } else {
+      this.logger.warn(`Failed to call provider ${name}`, { error: message });
+    }
+  }
+}
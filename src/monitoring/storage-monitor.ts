@@ -0,0 +1,114 @@
+// BotNet Storage Monitor
+// Probes that the database is still writable and buffers inbound gossip exchanges during brief outages
+
+import type Database from "better-sqlite3";
+import type { Logger } from "../logger.js";
+
+export interface StorageStatus {
+  healthy: boolean;
+  lastError?: string;
+  unhealthySince?: string;
+  buffered: number;
+}
+
+export class StorageMonitor {
+  private timer: NodeJS.Timeout;
+  private healthy = true;
+  private lastError?: string;
+  private unhealthySince?: number;
+  private buffer: any[] = [];
+  private readonly MAX_BUFFERED = 200; // Inbound exchanges held in memory while storage is down
+
+  // Seconds clients are told to wait in Retry-After while storage is unavailable
+  readonly retryAfterSeconds = 15;
+
+  constructor(
+    private database: Database.Database,
+    private logger: Logger,
+    private onRecovered: (buffered: any[]) => void,
+    intervalMs: number = 15000
+  ) {
+    this.timer = setInterval(() => this.probe(), intervalMs);
+    this.timer.unref();
+  }
+
+  isHealthy(): boolean {
+    return this.healthy;
+  }
+
+  getStatus(): StorageStatus {
+    return {
+      healthy: this.healthy,
+      lastError: this.lastError,
+      unhealthySince: this.unhealthySince ? new Date(this.unhealthySince).toISOString() : undefined,
+      buffered: this.buffer.length
+    };
+  }
+
+  /**
+   * Hold an inbound request until storage recovers; false when the buffer is full
+   */
+  bufferInbound(request: any): boolean {
+    if (this.buffer.length >= this.MAX_BUFFERED) {
+      return false;
+    }
+    this.buffer.push(request);
+    return true;
+  }
+
+  /**
+   * Record a storage failure seen by a handler, so we stop accepting writes before the next probe
+   */
+  reportFailure(error: unknown): void {
+    if (StorageMonitor.isStorageError(error)) {
+      this.markUnhealthy(error instanceof Error ? error.message : String(error));
+    }
+  }
+
+  stop(): void {
+    clearInterval(this.timer);
+  }
+
+  /**
+   * Rewrite user_version with its current value - a no-op write that fails on read-only or unavailable storage
+   */
+  private probe(): void {
+    try {
+      const version = this.database.pragma('user_version', { simple: true }) as number;
+      this.database.pragma(`user_version = ${Number(version) || 0}`);
+    } catch (error) {
+      this.markUnhealthy(error instanceof Error ? error.message : String(error));
+      return;
+    }
+
+    if (!this.healthy) {
+      const buffered = this.buffer;
+      this.buffer = [];
+      this.healthy = true;
+      this.logger.info('💾 Storage writable again', {
+        downForMs: this.unhealthySince ? Date.now() - this.unhealthySince : undefined,
+        replaying: buffered.length
+      });
+      this.lastError = undefined;
+      this.unhealthySince = undefined;
+      this.onRecovered(buffered);
+    }
+  }
+
+  private markUnhealthy(message: string): void {
+    if (this.healthy) {
+      this.healthy = false;
+      this.unhealthySince = Date.now();
+      this.logger.error('💾 Storage unavailable - rejecting writes and buffering inbound gossip', { error: message });
+    }
+    this.lastError = message;
+  }
+
+  /**
+   * SQLite errors that mean the database can't be written right now (as opposed to bad input)
+   */
+  static isStorageError(error: unknown): boolean {
+    const code = (error as any)?.code;
+    return typeof code === 'string' && /^SQLITE_(READONLY|IOERR|FULL|CANTOPEN|BUSY|LOCKED|CORRUPT|NOTADB)/.test(code);
+  }
+}
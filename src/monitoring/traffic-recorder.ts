@@ -0,0 +1,62 @@
+// BotNet Federation Traffic Recorder
+// Appends inbound/outbound MCP exchanges to a JSONL file (secrets scrubbed) for replay-federation.mjs
+
+import { appendFile } from "fs";
+import type { Logger } from "../logger.js";
+
+export interface RecordedExchange {
+  direction: 'inbound' | 'outbound';
+  peer: string; // Client IP for inbound, remote domain for outbound
+  request: any;
+  response: any;
+  statusCode?: number;
+  durationMs: number;
+  recordedAt: string;
+}
+
+export class TrafficRecorder {
+  // Any key that looks like a credential is replaced before hitting disk
+  private static readonly SECRET_KEY_PATTERN = /token|password|secret|authorization|credential|challenge|signature/i;
+
+  constructor(
+    private filePath: string,
+    private logger: Logger
+  ) {}
+
+  /**
+   * Append one exchange (fire and forget - recording must never break federation)
+   */
+  record(exchange: Omit<RecordedExchange, 'recordedAt'>): void {
+    const entry: RecordedExchange = {
+      ...exchange,
+      request: TrafficRecorder.scrub(exchange.request),
+      response: TrafficRecorder.scrub(exchange.response),
+      recordedAt: new Date().toISOString()
+    };
+
+    appendFile(this.filePath, JSON.stringify(entry) + '\n', error => {
+      if (error) {
+        this.logger.error('Failed to record federation exchange', { filePath: this.filePath, error: error.message });
+      }
+    });
+  }
+
+  /**
+   * Deep-copy a value with credential-like fields redacted whole, including objects and arrays under them
+   */
+  static scrub(value: any): any {
+    if (Array.isArray(value)) {
+      return value.map(item => TrafficRecorder.scrub(item));
+    }
+    if (value && typeof value === 'object') {
+      const scrubbed: Record<string, any> = {};
+      for (const [key, inner] of Object.entries(value)) {
+        scrubbed[key] = TrafficRecorder.SECRET_KEY_PATTERN.test(key) && inner !== null && inner !== undefined
+          ? '[REDACTED]'
+          : TrafficRecorder.scrub(inner);
+      }
+      return scrubbed;
+    }
+    return value;
+  }
+}
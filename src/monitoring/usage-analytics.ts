@@ -0,0 +1,106 @@
+// BotNet Usage Analytics
+// Local-only aggregates (active peers, message volume, federation latency) - no per-agent detail leaves the node
+
+import type Database from "better-sqlite3";
+
+export interface DailyUsage {
+  day: string; // YYYY-MM-DD (UTC)
+  activePeers: number;
+  messagesSent: number;
+  messagesReceived: number;
+}
+
+export interface UsageSummary {
+  days: DailyUsage[];
+  gossipLast24h: number;
+  federationLatencyMs: { p50: number; p95: number; p99: number; samples: number } | null;
+}
+
+export class UsageAnalytics {
+  private latencies: number[] = [];
+  private readonly MAX_LATENCY_SAMPLES = 500; // Rolling window of recent outbound federation calls
+
+  constructor(
+    private database: Database.Database,
+    private nodeDomain: string
+  ) {}
+
+  /**
+   * Record the duration of one successful outbound federation call
+   */
+  recordLatency(durationMs: number): void {
+    this.latencies.push(durationMs);
+    if (this.latencies.length > this.MAX_LATENCY_SAMPLES) {
+      this.latencies.shift();
+    }
+  }
+
+  getSummary(days: number = 7): UsageSummary {
+    const since = new Date(Date.now() - (days - 1) * 24 * 60 * 60 * 1000).toISOString().slice(0, 10);
+
+    const peers = this.database.prepare(`
+      SELECT day, COUNT(DISTINCT domain) AS count FROM peer_bandwidth
+      WHERE day >= ?
+      GROUP BY day
+    `).all(since) as Array<{ day: string; count: number }>;
+
+    const messages = this.database.prepare(`
+      SELECT date(created_at) AS day,
+        SUM(CASE WHEN from_domain = ? THEN 1 ELSE 0 END) AS sent,
+        SUM(CASE WHEN from_domain != ? THEN 1 ELSE 0 END) AS received
+      FROM messages
+      WHERE date(created_at) >= ?
+      GROUP BY date(created_at)
+    `).all(this.nodeDomain, this.nodeDomain, since) as Array<{ day: string; sent: number; received: number }>;
+
+    const usage: DailyUsage[] = [];
+    for (let offset = 0; offset < days; offset++) {
+      const day = new Date(Date.now() - offset * 24 * 60 * 60 * 1000).toISOString().slice(0, 10);
+      const messageRow = messages.find(row => row.day === day);
+      usage.push({
+        day,
+        activePeers: peers.find(row => row.day === day)?.count || 0,
+        messagesSent: messageRow?.sent || 0,
+        messagesReceived: messageRow?.received || 0
+      });
+    }
+
+    const gossip = this.database.prepare(`
+      SELECT COUNT(*) AS count FROM gossip_messages WHERE created_at > datetime('now', '-1 day')
+    `).get() as { count: number };
+
+    return {
+      days: usage,
+      gossipLast24h: gossip.count,
+      federationLatencyMs: this.latencyPercentiles()
+    };
+  }
+
+  /**
+   * Coarse, publishable view: counts rounded into buckets so small nodes can't be fingerprinted
+   */
+  getPublicBuckets(): { activePeers: string; messagesPerDay: string } {
+    const today = this.getSummary(1).days[0];
+    return {
+      activePeers: UsageAnalytics.bucket(today.activePeers),
+      messagesPerDay: UsageAnalytics.bucket(today.messagesSent + today.messagesReceived)
+    };
+  }
+
+  private latencyPercentiles(): UsageSummary['federationLatencyMs'] {
+    if (!this.latencies.length) {
+      return null;
+    }
+    const sorted = [...this.latencies].sort((a, b) => a - b);
+    const at = (percentile: number) => sorted[Math.min(sorted.length - 1, Math.floor(sorted.length * percentile))];
+    return { p50: at(0.5), p95: at(0.95), p99: at(0.99), samples: sorted.length };
+  }
+
+  private static bucket(count: number): string {
+    if (count === 0) return '0';
+    if (count < 10) return '1-9';
+    if (count < 100) return '10-99';
+    if (count < 1000) return '100-999';
+    return '1000+';
+  }
+}
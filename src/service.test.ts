@@ -1,6 +1,7 @@
 import { describe, it, expect, beforeEach, jest } from '@jest/globals';
 import Database from 'better-sqlite3';
 import { BotNetService } from './service.js';
+import { initializeDatabase } from './database.js';
 import type { Logger } from './logger.js';
 import type { BotNetConfig } from '../index.js';
 
@@ -34,14 +35,15 @@ describe('BotNetService', () => {
     botDescription: 'Test bot',
     capabilities: ['test'],
     tier: 'standard',
+    operatorContact: 'abuse@test.example.com',
     databasePath: ':memory:',
     httpPort: 8080,
     logLevel: 'info',
   };
   
-  beforeEach(() => {
-    // Create in-memory database
-    db = new Database(':memory:');
+  beforeEach(async () => {
+    // Create in-memory database with the schema the services expect
+    db = await initializeDatabase(':memory:', mockLogger);
     
     // Initialize service
     service = new BotNetService({
@@ -67,6 +69,14 @@ describe('BotNetService', () => {
         description: 'Test bot',
         capabilities: ['test'],
         tier: 'standard',
+        operator: {
+          contact: 'abuse@test.example.com',
+          abuseReports: 'botnet.abuse.report',
+        },
+        nodeKey: {
+          type: 'ed25519',
+          publicKey: expect.any(String),
+        },
         version: '1.0.0',
         protocol_version: '1.0',
         endpoints: {
@@ -89,4 +99,64 @@ describe('BotNetService', () => {
       expect(health.checks.database).toBe('ok');
     });
   });
+
+  describe('processOutbox', () => {
+    const friend = 'botnet.alice.com';
+    let calls: Array<{ method: string; session?: string }>;
+    let answer: (method: string) => any;
+
+    beforeEach(() => {
+      db.prepare(`INSERT INTO friendships (friend_domain, status) VALUES (?, 'active')`).run(friend);
+      db.prepare(`
+        INSERT INTO friendship_credentials (from_domain, to_domain, permanent_password) VALUES (?, ?, 'perm_secret')
+      `).run(testConfig.botDomain, friend);
+
+      calls = [];
+      answer = () => ({ result: { success: true } });
+      const client = (service as any).mcpClient;
+      client.callRemoteNode = jest.fn(async (domain: string, method: string) => {
+        calls.push({ method, session: client.getSessionToken(domain) });
+        if (method === 'botnet.login') {
+          return { jsonrpc: '2.0', result: { sessionToken: `sess_${calls.length}`, expiresAt: new Date(Date.now() + 60000).toISOString() }, id: 1 };
+        }
+        return { jsonrpc: '2.0', id: 1, ...answer(method) };
+      });
+
+      service.getFederationOutbox().enqueue(friend, 'botnet.gossip.exchange', { messages: [] }, 'timeout');
+      service.getFederationOutbox().retryNow();
+    });
+
+    it('logs in to a friend before retrying', async () => {
+      expect(await service.processOutbox()).toEqual({ attempted: 1, delivered: 1 });
+      expect(calls).toEqual([
+        { method: 'botnet.login', session: undefined },
+        { method: 'botnet.gossip.exchange', session: 'sess_1' },
+      ]);
+      expect(service.getFederationOutbox().list()).toEqual([]);
+    });
+
+    it('logs in again after the peer rejects the session', async () => {
+      answer = () => ({ error: { code: -32002, message: 'Invalid session token' } });
+      await service.processOutbox();
+      expect(service.getFederationOutbox().list()[0].attempts).toBe(2);
+
+      answer = () => ({ result: { success: true } });
+      service.getFederationOutbox().retryNow();
+      expect(await service.processOutbox()).toEqual({ attempted: 1, delivered: 1 });
+      expect(calls.map(call => call.method)).toEqual(['botnet.login', 'botnet.gossip.exchange', 'botnet.login', 'botnet.gossip.exchange']);
+      expect(calls[3].session).toBe('sess_3');
+    });
+
+    it("drops the entry when the peer answers with its own internal error", async () => {
+      answer = () => ({ error: { code: -32603, message: 'Database is locked' } });
+      expect(await service.processOutbox()).toEqual({ attempted: 1, delivered: 0 });
+      expect(service.getFederationOutbox().list()).toEqual([]);
+    });
+
+    it('keeps retrying while the peer rate limits us', async () => {
+      answer = () => ({ error: { code: -32004, message: 'Rate limit exceeded' } });
+      await service.processOutbox();
+      expect(service.getFederationOutbox().list()[0].attempts).toBe(2);
+    });
+  });
 });
\ No newline at end of file
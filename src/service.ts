@@ -1,4 +1,7 @@
 import { v4 as uuidv4 } from "uuid";
+import { mkdirSync, readFileSync, writeFileSync } from "node:fs";
+import { dirname, join } from "node:path";
+import { resolveTxt } from "node:dns/promises";
 import type Database from "better-sqlite3";
 import type { BotNetConfig } from "../index.js";
 import type { Logger } from "./logger.js";
@@ -6,10 +9,32 @@ import { AuthService } from "./auth/auth-service.js";
 import { TokenService } from "./auth/token-service.js";
 import { AuthMiddleware } from "./auth/auth-middleware.js";
 import { FriendshipService } from "./friendship/friendship-service.js";
-import { GossipService } from "./gossip/gossip-service.js";
+import { GossipService, type GossipDigestBucket, type GossipQuote, type GossipThreadNode, type ResolvedQuote } from "./gossip/gossip-service.js";
 import { MessagingService } from "./messaging/messaging-service.js";
 import { RateLimiter } from "./rate-limiter.js";
 import { MCPClient } from "./mcp/mcp-client.js";
+import { AuditService } from "./audit/audit-service.js";
+import { AnomalyDetector } from "./monitoring/anomaly-detector.js";
+import { ReputationService } from "./friendship/reputation-service.js";
+import { FriendListService } from "./friendship/friend-list-service.js";
+import { BlockListService } from "./friendship/block-list-service.js";
+import { TrafficRecorder } from "./monitoring/traffic-recorder.js";
+import { AgentCapture, type CaptureStatus } from "./monitoring/agent-capture.js";
+import { ErrorReporter } from "./monitoring/error-reporter.js";
+import { LoadMonitor } from "./monitoring/load-monitor.js";
+import { StorageMonitor } from "./monitoring/storage-monitor.js";
+import { ProviderHealth } from "./monitoring/provider-health.js";
+import { FederationOutbox } from "./mcp/federation-outbox.js";
+import { KEY_RECORD_PREFIX, NodeIdentity, type SignatureVerification } from "./auth/node-identity.js";
+import { ChannelService, type Channel, type ChannelMessage, type ModerationAction, type ModerationEntry } from "./channels/channel-service.js";
+import { BandwidthMeter } from "./monitoring/bandwidth-meter.js";
+import { UsageAnalytics } from "./monitoring/usage-analytics.js";
+import { ClockSkewMonitor } from "./monitoring/clock-skew.js";
+import { AbuseReportService, type AbuseReport } from "./friendship/abuse-report-service.js";
+import { FeatureFlags } from "./feature-flags.js";
+import { getNodeFeatures, resolveCapabilities, AGENT_CAPABILITIES, type CapabilityDescriptor, type NodeFeature } from "./capabilities.js";
+import { runDiagnostics, type DiagnosticCheck } from "./monitoring/diagnostics.js";
+import type { ProofOfWorkStamp } from "./auth/proof-of-work.js";
 interface BotNetServiceOptions {
   database: Database.Database;
   config: BotNetConfig;
@@ -25,22 +50,116 @@ export class BotNetService {
   private messagingService: MessagingService;
   private rateLimiter: RateLimiter;
   private mcpClient: MCPClient;
+  private keyLookupClient: MCPClient;
+  private auditService: AuditService;
+  private anomalyDetector: AnomalyDetector;
+  private reputationService: ReputationService;
+  private friendListService: FriendListService;
+  private blockListService: BlockListService;
+  private trafficRecorder?: TrafficRecorder;
+  private agentCapture: AgentCapture;
+  private errorReporter: ErrorReporter;
+  private loadMonitor: LoadMonitor;
+  private storageMonitor: StorageMonitor;
+  private providerHealth: ProviderHealth;
+  private federationOutbox: FederationOutbox;
+  private nodeIdentity: NodeIdentity;
+  private channelService: ChannelService;
+  private bandwidthMeter: BandwidthMeter;
+  private usageAnalytics: UsageAnalytics;
+  private clockSkewMonitor: ClockSkewMonitor;
+  private abuseReportService: AbuseReportService;
+  private featureFlags: FeatureFlags;
+  private capabilities: CapabilityDescriptor[];
   
   constructor(private options: BotNetServiceOptions) {
     const { database, config, logger } = options;
     
+    this.featureFlags = new FeatureFlags(database, logger.child("flags"), config.featureFlags);
     this.authService = new AuthService(logger.child("auth"));
     this.tokenService = new TokenService(database, logger.child("tokenService"));
     this.authMiddleware = new AuthMiddleware(this.tokenService, logger.child("authMiddleware"));
+    this.providerHealth = new ProviderHealth(logger.child("providers"));
+    this.errorReporter = new ErrorReporter({
+      logger: logger.child("errors"),
+      nodeDomain: config.botDomain,
+      sinkUrl: config.errorSinkUrl,
+      providers: this.providerHealth
+    });
+    this.loadMonitor = new LoadMonitor({
+      logger: logger.child("load"),
+      maxHeapMB: config.loadSheddingMaxHeapMB,
+      maxEventLoopDelayMs: config.loadSheddingMaxEventLoopDelayMs
+    });
+    this.storageMonitor = new StorageMonitor(database, logger.child("storage"), buffered => this.replayBufferedExchanges(buffered));
+    this.bandwidthMeter = new BandwidthMeter(database, logger.child("bandwidth"), config.peerDailyBandwidthMB * 1024 * 1024);
+    this.usageAnalytics = new UsageAnalytics(database, config.botDomain);
+    this.clockSkewMonitor = new ClockSkewMonitor(logger.child("clock"), config.clockSkewWarnSeconds * 1000);
+    if (config.federationRecordPath) {
+      this.trafficRecorder = new TrafficRecorder(config.federationRecordPath, logger.child("recorder"));
+      logger.warn('📼 Recording federation traffic', { path: config.federationRecordPath });
+    }
+    this.agentCapture = new AgentCapture(logger.child("capture"));
+    this.nodeIdentity = new NodeIdentity(database, logger.child("identity"), config.botDomain);
+    this.auditService = new AuditService(database, logger.child("audit"));
+    this.anomalyDetector = new AnomalyDetector({
+      logger: logger.child("anomaly"),
+      auditService: this.auditService,
+      nodeDomain: config.botDomain,
+      webhookUrl: config.alertWebhookUrl,
+      providers: this.providerHealth,
+      thresholds: {
+        request_spike: config.anomalyRequestsPerMinute,
+        auth_failures: config.anomalyAuthFailuresPerMinute,
+        signature_failures: config.anomalySignatureFailuresPerMinute,
+        flapping: config.anomalyFlapsPerHour
+      }
+    });
     this.mcpClient = new MCPClient({
       logger: logger.child("mcpClient"),
       timeout: 15000, // 15 second timeout for federation calls
-      retries: 2
+      retries: 2,
+      recorder: this.trafficRecorder,
+      bandwidth: this.bandwidthMeter,
+      analytics: this.usageAnalytics,
+      clock: this.clockSkewMonitor,
+      signer: this.nodeIdentity,
+      anomalies: this.anomalyDetector
+    });
+    // Peer keys are fetched while a request waits on them, so lookups get one short attempt
+    this.keyLookupClient = new MCPClient({
+      logger: logger.child("keyLookup"),
+      timeout: 3000,
+      retries: 0,
+      bandwidth: this.bandwidthMeter
     });
-    this.friendshipService = new FriendshipService(database, config, logger.child("friendship"), this.mcpClient);
-    this.gossipService = new GossipService(database, config, logger.child("gossip"));
-    this.messagingService = new MessagingService(database, config, logger.child("messaging"));
+    this.federationOutbox = new FederationOutbox(database, logger.child("outbox"));
+    this.friendshipService = new FriendshipService(database, config, logger.child("friendship"), this.mcpClient, this.auditService);
+    this.reputationService = new ReputationService(database, logger.child("reputation"));
+    this.friendListService = new FriendListService(database, logger.child("friendLists"));
+    this.blockListService = new BlockListService(database, logger.child("blockList"));
+    this.channelService = new ChannelService(database, logger.child("channels"), config.botDomain);
+    this.abuseReportService = new AbuseReportService(database, logger.child("abuse"), this.providerHealth, config.alertWebhookUrl);
+    this.gossipService = new GossipService(database, config, logger.child("gossip"), this.blockListService);
+    this.messagingService = new MessagingService(database, config, logger.child("messaging"), this.blockListService);
     this.rateLimiter = new RateLimiter(logger.child("rateLimiter"), 60 * 1000, 10); // Universal rate limiter
+
+    const resolved = resolveCapabilities(config.capabilities);
+    this.capabilities = resolved.capabilities;
+    for (const error of resolved.errors) {
+      logger.warn(`🧩 Ignoring capability: ${error}`);
+    }
+  }
+
+  /**
+   * What this node and its agent support, for botnet.capabilities (discovery before befriending)
+   */
+  getCapabilities(): { agent: CapabilityDescriptor[]; node: NodeFeature[]; registry: typeof AGENT_CAPABILITIES } {
+    return {
+      agent: this.capabilities,
+      node: getNodeFeatures(this.options.config, this.featureFlags),
+      registry: AGENT_CAPABILITIES
+    };
   }
   
   async getBotProfile() {
@@ -52,6 +171,14 @@ export class BotNetService {
       description: config.botDescription,
       capabilities: config.capabilities,
       tier: config.tier,
+      operator: {
+        contact: config.operatorContact,
+        abuseReports: "botnet.abuse.report"
+      },
+      nodeKey: {
+        type: "ed25519",
+        publicKey: this.nodeIdentity.getPublicKey()
+      },
       version: "1.0.0",
       protocol_version: "1.0",
       endpoints: {
@@ -68,13 +195,23 @@ export class BotNetService {
     try {
       // Check database
       const dbCheck = this.options.database.prepare("SELECT 1").get();
+      const clock = this.clockSkewMonitor.getStatus();
+      const storage = this.storageMonitor.getStatus();
+      const outbox = this.federationOutbox.getDepth();
+      const providers = this.providerHealth.getStatus();
+      
+      const providersDown = providers.some(provider => provider.state === 'open');
       
       return {
-        status: "healthy",
+        status: storage.healthy && !providersDown ? "healthy" : "degraded",
         timestamp: new Date().toISOString(),
         version: "1.0.0",
         checks: {
-          database: dbCheck ? "ok" : "error",
+          database: dbCheck ? (storage.healthy ? "ok" : "read-only") : "error",
+          storage,
+          outbox: { pending: outbox.total, peers: outbox.byPeer.length },
+          providers,
+          clock: { status: clock.skewed ? "skewed" : "ok", ...clock },
           services: {
             auth: "ok",
             friendship: "ok",
@@ -92,6 +229,57 @@ export class BotNetService {
     }
   }
   
+  /**
+   * Scheduled integrity verification: SQLite page/index consistency, foreign keys,
+   * and the triggers that keep the audit log append-only
+   */
+  verifyIntegrity(): { ok: boolean; problems: string[] } {
+    const { database, logger } = this.options;
+    const problems: string[] = [];
+
+    const quickCheck = database.pragma('quick_check', { simple: true });
+    if (quickCheck !== 'ok') {
+      problems.push(`quick_check: ${quickCheck}`);
+    }
+
+    const foreignKeyViolations = database.pragma('foreign_key_check') as any[];
+    if (foreignKeyViolations.length) {
+      problems.push(`${foreignKeyViolations.length} foreign key violation(s) in ${[...new Set(foreignKeyViolations.map(row => row.table))].join(', ')}`);
+    }
+
+    const triggers = database.prepare(`
+      SELECT name FROM sqlite_master WHERE type = 'trigger' AND name IN ('audit_events_no_update', 'audit_events_no_delete')
+    `).pluck().all() as string[];
+    if (triggers.length !== 2) {
+      problems.push('audit log append-only triggers are missing - the audit trail may have been tampered with');
+    }
+
+    const ok = problems.length === 0;
+    this.auditService.record('integrity.check', {
+      actor: 'system',
+      outcome: ok ? 'success' : 'failure',
+      details: ok ? undefined : { problems }
+    });
+
+    if (!ok) {
+      logger.error('🧱 Data integrity verification failed', { problems });
+      this.errorReporter.report(new Error(`Integrity check failed: ${problems.join('; ')}`), { source: 'job:integrity-check' });
+    }
+    return { ok, problems };
+  }
+
+  /**
+   * Self-diagnostics for botnet_doctor (DNS, TLS, reachability, storage, clock, load)
+   */
+  async runDiagnostics(): Promise<DiagnosticCheck[]> {
+    return runDiagnostics({
+      domain: this.options.config.botDomain,
+      database: this.options.database,
+      clock: this.clockSkewMonitor,
+      load: this.loadMonitor
+    });
+  }
+  
   async handleMCPRequest(request: any) {
     const { logger } = this.options;
     logger.info("Handling MCP request", { type: request.type });
@@ -99,7 +287,7 @@ export class BotNetService {
     // Route MCP requests based on type
     switch (request.type) {
       case "friendship.request":
-        return await this.friendshipService.createIncomingFriendRequest(request.fromDomain, request.message, request.clientIP);
+        return await this.friendshipService.createIncomingFriendRequest(request.fromDomain, request.message, request.clientIP, request.proofOfWork);
       
       case "friendship.accept":
         return await this.friendshipService.acceptFriendshipRequest(request.fromDomain, request.toDomain);
@@ -140,7 +328,268 @@ export class BotNetService {
   }
   
   async exchangeGossip(request: any) {
-    return this.gossipService.exchangeMessages(request);
+    const source = typeof request?.source_bot_id === 'string' ? BlockListService.domainOf(request.source_bot_id) : '';
+    if (source && this.friendshipService.isShadow(source)) {
+      // Shadow peer: record what we would accept, persist nothing and send nothing back
+      const preview = this.gossipService.previewExchange(request);
+      this.recordShadow(source, 'inbound_exchange', {
+        wouldStore: preview.wouldStore.length,
+        duplicates: preview.duplicates.length,
+        blocked: preview.blocked.length
+      });
+      return { success: true, received: 0, duplicates: preview.duplicates.length, messages: [] };
+    }
+
+    const result = await this.gossipService.exchangeMessages(request);
+    this.resolveMissingQuotes(request?.messages);
+    return result;
+  }
+
+  /**
+   * Apply gossip exchanges that arrived while storage was unavailable
+   */
+  private async replayBufferedExchanges(buffered: any[]): Promise<void> {
+    for (const request of buffered) {
+      try {
+        await this.exchangeGossip(request);
+      } catch (error) {
+        this.options.logger.warn('Failed to replay buffered gossip exchange', {
+          source: request?.source_bot_id,
+          error: error instanceof Error ? error.message : String(error)
+        });
+      }
+    }
+  }
+
+  /**
+   * Shadow peers get dry-run federation - keep a record of what would have happened
+   */
+  private recordShadow(friendDomain: string, action: string, details: Record<string, any>): void {
+    this.options.logger.info(`👻 Shadow ${action} with ${friendDomain} (not delivered)`, details);
+    this.auditService.record('federation.shadow', {
+      actor: 'local',
+      target: friendDomain,
+      details: { action, ...details }
+    });
+  }
+
+  /**
+   * Toggle dry-run federation for an active friend
+   */
+  setFriendShadow(friendDomain: string, enabled: boolean): boolean {
+    const updated = this.friendshipService.setShadow(friendDomain, enabled);
+    if (updated) {
+      this.auditService.record('admin.action', {
+        actor: 'local',
+        target: friendDomain,
+        details: { action: enabled ? 'enable_shadow' : 'disable_shadow' }
+      });
+    }
+    return updated;
+  }
+
+  /**
+   * Debug trace for a direct message or gossip by ID
+   */
+  traceMessage(messageId: string): { kind: 'message' | 'gossip'; trace: any } | null {
+    const message = this.messagingService.getTrace(messageId);
+    if (message) {
+      return { kind: 'message', trace: message };
+    }
+    const gossip = this.gossipService.getTrace(messageId);
+    return gossip ? { kind: 'gossip', trace: gossip } : null;
+  }
+
+  /**
+   * Serve one of our own gossips to a peer resolving a quote reference
+   */
+  getOwnGossip(messageId: string): any | null {
+    return this.gossipService.getOwnMessage(messageId);
+  }
+
+  /**
+   * Reply tree of gossip quoting a message, with a depth limit and id-cursor paging of direct replies
+   */
+  getGossipThread(messageId: string, depth?: number, limit?: number, cursor?: number): { root: any; replies: GossipThreadNode[]; nextCursor?: number } | null {
+    return this.gossipService.getThread(messageId, depth, limit, cursor);
+  }
+
+  /**
+   * React to a gossip (or take the reaction back) and pass it on to our federated friends
+   */
+  reactToGossip(messageId: string, reaction: string, remove: boolean = false): { changed: boolean; reactions: Record<string, number> } {
+    const { botDomain } = this.options.config;
+    const result = this.gossipService.react(messageId, botDomain, reaction, remove);
+    if (!result) {
+      throw new Error(`Gossip not found on this node: ${messageId}`);
+    }
+    if (result.changed) {
+      this.fanOutReaction({ messageId, reaction, remove });
+    }
+    return result;
+  }
+
+  /**
+   * A friend's botnet.gossip.react. Reactions are only accepted as the calling node's own and are not
+   * forwarded again, so they reach the reactor's friends and no further
+   */
+  receiveGossipReaction(source: string, messageId: string, reaction: string, remove: boolean = false): { applied: boolean; reactions: Record<string, number> } {
+    if (this.blockListService.isBlocked(source)) {
+      return { applied: false, reactions: {} };
+    }
+    const result = this.gossipService.react(messageId, source, reaction, remove);
+    return result ? { applied: result.changed, reactions: result.reactions } : { applied: false, reactions: {} };
+  }
+
+  /**
+   * Push one of our reactions to active federated friends in the background (failed deliveries go to the outbox)
+   */
+  private fanOutReaction(payload: { messageId: string; reaction: string; remove: boolean }): void {
+    setImmediate(async () => {
+      const method = 'botnet.gossip.react';
+      const params = { ...payload, source_bot_id: this.options.config.botDomain };
+      let friends: string[];
+      try {
+        friends = (await this.friendshipService.listFriendships())
+          .filter((friend: any) =>
+            friend.status === 'active' && friend.friend_domain?.startsWith('botnet.') && !this.friendshipService.isShadow(friend.friend_domain))
+          .map((friend: any) => friend.friend_domain as string);
+      } catch (error) {
+        this.errorReporter.report(error, { source: 'job:reaction-fanout' });
+        return;
+      }
+
+      for (const domain of this.mcpClient.sortByRtt(friends, friend => friend)) {
+        try {
+          const response = await this.mcpClient.callRemoteNode(domain, method, params);
+          if (FederationOutbox.isRetryable(response)) {
+            this.federationOutbox.enqueue(domain, method, params, response.error!.message);
+          }
+        } catch (error) {
+          this.errorReporter.report(error, { source: 'job:reaction-fanout', friendDomain: domain });
+        }
+      }
+    });
+  }
+
+  /**
+   * A page of our own gossips for the public Atom feed, pinned to a snapshot for stable pagination
+   */
+  async getPublicFeed(limit: number = 50, cursor?: number, snapshot?: number): Promise<{ entries: any[]; snapshot: number; nextCursor?: number }> {
+    return this.gossipService.getFeedPage(limit, cursor, snapshot);
+  }
+
+  /**
+   * Next batch for a /feeds/stream client: our new public posts (if the public feed is on) and, for an
+   * authenticated peer, new direct messages addressed to it. Each event carries the cursor to resume after it
+   */
+  getStreamEvents(cursor: { posts?: number; messages?: number }, domain?: string): {
+    events: Array<{ type: 'post' | 'dm'; data: any; cursor: { posts: number; messages: number } }>;
+    cursor: { posts: number; messages: number };
+  } {
+    const posts = this.options.config.publicFeedEnabled
+      ? this.gossipService.getOwnSince(cursor.posts)
+      : { entries: [], cursor: cursor.posts || 0 };
+    const messages = domain
+      ? this.messagingService.getMessagesSince(domain, cursor.messages)
+      : { messages: [], cursor: cursor.messages || 0 };
+
+    const events: Array<{ type: 'post' | 'dm'; data: any; cursor: { posts: number; messages: number } }> = [];
+    for (const { seq, ...post } of posts.entries) {
+      events.push({ type: 'post', data: post, cursor: { posts: seq, messages: cursor.messages || 0 } });
+    }
+    for (const { seq, ...message } of messages.messages) {
+      events.push({ type: 'dm', data: message, cursor: { posts: posts.cursor, messages: seq } });
+    }
+    return { events, cursor: { posts: posts.cursor, messages: messages.cursor } };
+  }
+
+  /**
+   * Resolve a quote, fetching the original from its origin node when not known locally.
+   * Only origins that are active friends we hold a session with are asked
+   */
+  async fetchReferencedMessage(quote: GossipQuote): Promise<ResolvedQuote> {
+    const local = this.gossipService.resolveQuote(quote);
+    if (local.verified !== null || !quote.source?.startsWith('botnet.')) {
+      return local;
+    }
+
+    try {
+      if (!(await this.ensureFriendSession(quote.source))) {
+        return local;
+      }
+
+      const response = await this.mcpClient.callRemoteNode(quote.source, 'botnet.gossip.fetch', {
+        messageId: quote.messageId
+      });
+      const message = response.result?.message;
+
+      if (!message) {
+        return local;
+      }
+
+      if (!this.gossipService.cacheReferencedMessage(quote, message)) {
+        this.options.logger.warn('⚠️ Referenced message does not match quoted hash', {
+          messageId: quote.messageId,
+          source: quote.source
+        });
+        return { ...quote, verified: false };
+      }
+
+      return this.gossipService.resolveQuote(quote);
+    } catch (error) {
+      this.options.logger.warn('Failed to fetch referenced message', {
+        messageId: quote.messageId,
+        source: quote.source,
+        error: error instanceof Error ? error.message : String(error)
+      });
+      return local;
+    }
+  }
+
+  /**
+   * Make sure we hold a session on an active, non-shadow friend's node, logging in with the
+   * permanent password it issued us if needed
+   */
+  private async ensureFriendSession(friendDomain: string): Promise<boolean> {
+    const botDomain = this.options.config.botDomain;
+    if (this.blockListService.isBlocked(friendDomain) || this.friendshipService.isShadow(friendDomain)
+      || await this.friendshipService.getFriendshipStatus(botDomain, friendDomain) !== 'active') {
+      return false;
+    }
+    if (this.mcpClient.getSessionToken(friendDomain)) {
+      return true;
+    }
+
+    const credential = this.tokenService.getFriendshipCredential(botDomain, friendDomain);
+    if (!credential) {
+      return false;
+    }
+
+    const login = await this.mcpClient.login(friendDomain, botDomain, credential.permanentPassword);
+    if (!login.success) {
+      this.options.logger.warn('Failed to log in to friend node', { friendDomain, error: login.error });
+    }
+    return login.success;
+  }
+
+  /**
+   * Fetch quoted originals we have never seen (background, best effort)
+   */
+  private resolveMissingQuotes(messages?: any[]): void {
+    const quotes = (Array.isArray(messages) ? messages : [])
+      .map(message => message?.quote as GossipQuote | undefined)
+      .filter((quote): quote is GossipQuote => !!quote?.messageId && !!quote?.contentHash);
+
+    if (!quotes.length || this.loadMonitor.shouldShed() || !this.featureFlags.isEnabled('gossip_reference_fetch')) {
+      return;
+    }
+
+    setImmediate(async () => {
+      for (const quote of quotes) {
+        await this.fetchReferencedMessage(quote);
+      }
+    });
   }
   
   async getGossipNetwork() {
@@ -222,6 +671,23 @@ export class BotNetService {
     }
   }
   
+  /**
+   * Receive friend request from a remote domain (public MCP entry point)
+   */
+  async receiveFriendRequest(fromDomain: string, message?: string, clientIP?: string, proofOfWork?: ProofOfWorkStamp): Promise<{ bearerToken: string; status: string }> {
+    return await this.friendshipService.createIncomingFriendRequest(fromDomain, message, clientIP, proofOfWork);
+  }
+
+  /**
+   * Proof-of-work requirements advertised to friend requesters
+   */
+  getFriendRequestProofOfWork(fromDomain: string): { difficulty: number; resource: string } {
+    return {
+      difficulty: this.friendshipService.getProofOfWorkDifficulty(),
+      resource: this.friendshipService.getProofOfWorkResource(fromDomain)
+    };
+  }
+  
   /**
    * Get pending friend requests for this domain (legacy method)
    */
@@ -378,21 +844,84 @@ export class BotNetService {
    * Delete friend requests by criteria
    */
   async deleteFriendRequests(criteria: any, clientIP?: string): Promise<any> {
-    return await this.friendshipService.deleteFriendRequests(criteria, clientIP);
+    const result = await this.friendshipService.deleteFriendRequests(criteria, clientIP);
+    this.auditService.record('admin.action', {
+      actor: clientIP || 'local',
+      details: { action: 'delete_friend_requests', criteria, deletedCount: result.deletedCount }
+    });
+    return result;
   }
 
   /**
    * Delete gossip messages by criteria
    */
   async deleteMessages(criteria: any): Promise<any> {
-    return await this.gossipService.deleteMessages(criteria);
+    const result = await this.gossipService.deleteMessages(criteria);
+    this.auditService.record('admin.action', {
+      actor: 'local',
+      details: { action: 'delete_gossip', criteria, deletedCount: result.deletedCount }
+    });
+    return result;
   }
 
   /**
    * List active friends
    */
   async listFriends(clientIP?: string): Promise<any> {
-    return await this.friendshipService.listFriends(clientIP);
+    const friends = await this.friendshipService.listFriends(clientIP);
+    return friends.map((friend: any) => {
+      const rtt = friend.friend_domain ? this.mcpClient.getPeerRtt(friend.friend_domain) : undefined;
+      return rtt ? { ...friend, rttMs: rtt.rttMs, rttSampledAt: new Date(rtt.sampledAt).toISOString() } : friend;
+    });
+  }
+
+  /**
+   * Our federated friends as offered to a friend via botnet.peers (opt-in with sharePeerList)
+   */
+  async getSharedPeers(requester?: string): Promise<Array<{ domain: string; since: string }>> {
+    if (!this.options.config.sharePeerList) {
+      throw new Error('Peer exchange is disabled on this node');
+    }
+    const friends = await this.friendshipService.listFriendships();
+    return friends
+      .filter((friend: any) =>
+        friend.status === 'active' && friend.friend_domain?.startsWith('botnet.') &&
+        friend.friend_domain !== requester && !this.friendshipService.isShadow(friend.friend_domain))
+      .map((friend: any) => ({ domain: friend.friend_domain, since: friend.created_at }));
+  }
+
+  /**
+   * Ask federated friends for their peers and suggest nodes we're not connected to yet,
+   * ranked by how many of our friends know them
+   */
+  async discoverPeers(limit: number = 20): Promise<{ suggestions: Array<{ domain: string; knownBy: string[] }>; asked: number; answered: number }> {
+    const { botDomain } = this.options.config;
+    const friends = await this.friendshipService.listFriendships();
+    const known = new Set<string>([botDomain, ...friends.map((friend: any) => friend.friend_domain)]);
+    const federated = friends.filter((friend: any) => friend.status === 'active' && friend.friend_domain?.startsWith('botnet.'));
+
+    const knownBy = new Map<string, string[]>();
+    let answered = 0;
+    for (const friend of federated) {
+      const response = await this.mcpClient.callRemoteNode(friend.friend_domain, 'botnet.peers', { source_bot_id: botDomain });
+      if (response.error || !Array.isArray(response.result?.peers)) {
+        continue;
+      }
+      answered++;
+      for (const peer of response.result.peers.slice(0, 200)) {
+        const domain = typeof peer?.domain === 'string' ? peer.domain.toLowerCase() : '';
+        if (!domain.startsWith('botnet.') || known.has(domain) || this.blockListService.isBlocked(domain)) {
+          continue;
+        }
+        knownBy.set(domain, [...(knownBy.get(domain) || []), friend.friend_domain]);
+      }
+    }
+
+    const suggestions = [...knownBy.entries()]
+      .map(([domain, sources]) => ({ domain, knownBy: sources }))
+      .sort((a, b) => b.knownBy.length - a.knownBy.length || a.domain.localeCompare(b.domain))
+      .slice(0, limit);
+    return { suggestions, asked: federated.length, answered };
   }
 
   /**
@@ -402,6 +931,79 @@ export class BotNetService {
     return await this.messagingService.sendMessage(toDomain, content, messageType, clientIP);
   }
 
+  /**
+   * Store a direct message delivered to us by a federated node
+   */
+  async receiveMessage(fromDomain: string, content: string, messageType: string = 'chat', clientIP?: string): Promise<{ messageId: string; status: string }> {
+    return await this.messagingService.receiveMessage(fromDomain, this.options.config.botDomain, content, messageType, clientIP);
+  }
+
+  /**
+   * Drafts: save, list and delete unpublished messages/gossip
+   */
+  saveDraft(draft: { id?: string; kind: 'message' | 'gossip'; targetDomain?: string; content: string; category?: string }): any {
+    return this.messagingService.saveDraft(draft);
+  }
+
+  listDrafts(kind?: 'message' | 'gossip'): any[] {
+    return this.messagingService.listDrafts(kind);
+  }
+
+  deleteDraft(draftId: string): boolean {
+    return this.messagingService.deleteDraft(draftId);
+  }
+
+  /**
+   * Publish a draft (send the message or share the gossip), then remove it
+   */
+  async publishDraft(draftId: string, clientIP?: string): Promise<any> {
+    const draft = this.messagingService.getDraft(draftId);
+    if (!draft) {
+      throw new Error(`Draft not found: ${draftId}`);
+    }
+
+    const result = draft.kind === 'gossip'
+      ? await this.shareGossip(draft.content, draft.category, [], clientIP)
+      : await this.sendMessage(draft.target_domain!, draft.content, draft.category, clientIP);
+
+    this.messagingService.deleteDraft(draftId);
+    return { draft, result };
+  }
+
+  /**
+   * Bookmarks: local working set of referenced messages and gossip
+   */
+  addBookmark(messageId: string, note?: string): any {
+    return this.messagingService.addBookmark(messageId, note);
+  }
+
+  removeBookmark(messageId: string): boolean {
+    return this.messagingService.removeBookmark(messageId);
+  }
+
+  listBookmarks(kind?: 'message' | 'gossip'): any[] {
+    return this.messagingService.listBookmarks(kind);
+  }
+
+  /**
+   * Message requests: first-contact messages from strangers awaiting acceptance
+   */
+  listMessageRequests(): any[] {
+    return this.messagingService.listMessageRequests();
+  }
+
+  acceptMessageRequests(fromDomain: string): { delivered: number } {
+    return this.messagingService.acceptMessageRequests(fromDomain);
+  }
+
+  declineMessageRequests(fromDomain: string, block: boolean = false): { deleted: number; blocked: boolean } {
+    const result = this.messagingService.declineMessageRequests(fromDomain);
+    if (block) {
+      this.blockListService.set(fromDomain, 'block', 'Declined message request');
+    }
+    return { ...result, blocked: block };
+  }
+
   /**
    * Review messages (different behavior for local vs federated)
    */
@@ -420,7 +1022,12 @@ export class BotNetService {
    * Delete messages by criteria (messaging service)
    */
   async deleteMessagingMessages(criteria: any, clientIP?: string): Promise<any> {
-    return await this.messagingService.deleteMessages(criteria, clientIP);
+    const result = await this.messagingService.deleteMessages(criteria, clientIP);
+    this.auditService.record('admin.action', {
+      actor: clientIP || 'local',
+      details: { action: 'delete_messages', criteria, deletedCount: result.deletedCount }
+    });
+    return result;
   }
 
   /**
@@ -433,18 +1040,23 @@ export class BotNetService {
   /**
    * Share gossip with known friends and initiate gossip exchange with federation nodes
    */
-  async shareGossip(content: string, category: string = 'general', tags: string[] = [], clientIP?: string): Promise<any> {
+  async shareGossip(content: string, category: string = 'general', tags: string[] = [], clientIP?: string, quoteOf?: string): Promise<any> {
     // First share the gossip locally
-    const shareResult = await this.gossipService.shareGossip(content, category, tags, clientIP);
+    const shareResult = await this.gossipService.shareGossip(content, category, tags, clientIP, quoteOf);
     
     // Then initiate gossip exchange with active federated friends
     try {
       const friends = await this.friendshipService.listFriends(clientIP);
-      const federatedFriends = friends.filter((friend: any) => 
+      // Fastest friends first, so a slow peer doesn't delay everyone behind it
+      const federatedFriends = this.mcpClient.sortByRtt(friends.filter((friend: any) => 
         friend.friend_domain && friend.friend_domain.startsWith('botnet.') && friend.status === 'active'
-      );
+      ), (friend: any) => friend.friend_domain);
       
-      if (federatedFriends.length > 0) {
+      if (federatedFriends.length > 0 && !this.featureFlags.isEnabled('gossip_exchange_fanout')) {
+        this.options.logger.info('🚩 Gossip exchange fan-out disabled by feature flag');
+      } else if (federatedFriends.length > 0 && this.loadMonitor.shouldShed()) {
+        this.options.logger.warn('🔥 Skipping gossip exchange while node is under pressure', this.loadMonitor.getSnapshot());
+      } else if (federatedFriends.length > 0) {
         this.options.logger.info(`🌐 Initiating gossip exchange with ${federatedFriends.length} federated friends`, {
           friends: federatedFriends.map((f: any) => f.friend_domain),
           gossipContent: content.substring(0, 50) + '...'
@@ -456,6 +1068,13 @@ export class BotNetService {
             try {
               // Get recent gossips to share in exchange
               const recentGossips = await this.gossipService.getRecentMessages(5);
+
+              if (this.friendshipService.isShadow(friend.friend_domain)) {
+                this.recordShadow(friend.friend_domain, 'outbound_exchange', {
+                  wouldSend: recentGossips.map((gossip: any) => gossip.message_id)
+                });
+                continue;
+              }
               
               // Send gossip exchange request via MCP
               const exchangeResponse = await this.mcpClient.callRemoteNode(
@@ -467,7 +1086,12 @@ export class BotNetService {
                 }
               );
               
-              if (exchangeResponse.result && !exchangeResponse.error) {
+              if (FederationOutbox.isRetryable(exchangeResponse)) {
+                this.federationOutbox.enqueue(friend.friend_domain, 'botnet.gossip.exchange', {
+                  messages: recentGossips,
+                  source_bot_id: this.options.config.botDomain
+                }, exchangeResponse.error!.message);
+              } else if (exchangeResponse.result && !exchangeResponse.error) {
                 const exchangeResult = exchangeResponse.result;
                 if (exchangeResult.success && exchangeResult.messages) {
                   // Process received gossips from friend
@@ -475,6 +1099,7 @@ export class BotNetService {
                     messages: exchangeResult.messages,
                     source_bot_id: friend.friend_domain
                   });
+                  this.resolveMissingQuotes(exchangeResult.messages);
                   
                   this.options.logger.info(`✅ Gossip exchange completed with ${friend.friend_domain}`, {
                     sent: recentGossips.length,
@@ -483,6 +1108,7 @@ export class BotNetService {
                 }
               }
             } catch (error) {
+              this.errorReporter.report(error, { source: 'job:gossip-exchange', friendDomain: friend.friend_domain });
               this.options.logger.warn(`❌ Gossip exchange failed with ${friend.friend_domain}`, {
                 error: error instanceof Error ? error.message : String(error)
               });
@@ -519,6 +1145,308 @@ export class BotNetService {
     }
   }
 
+  /**
+   * Anti-entropy round: swap hourly gossip digests with each federated friend and backfill the messages we're missing
+   */
+  async runAntiEntropy(): Promise<{ peers: number; mismatched: number; backfilled: number }> {
+    const summary = { peers: 0, mismatched: 0, backfilled: 0 };
+    if (!this.featureFlags.isEnabled('gossip_anti_entropy') || this.loadMonitor.shouldShed() || !this.storageMonitor.isHealthy()) {
+      return summary;
+    }
+
+    const { botDomain } = this.options.config;
+    const friends = (await this.friendshipService.listFriendships()).filter((friend: any) =>
+      friend.status === 'active' && friend.friend_domain?.startsWith('botnet.') && !this.friendshipService.isShadow(friend.friend_domain));
+
+    for (const friend of friends) {
+      try {
+        const digest = await this.mcpClient.callRemoteNode(friend.friend_domain, 'botnet.gossip.digest', {
+          buckets: this.gossipService.getDigest(),
+          source_bot_id: botDomain
+        });
+        if (digest.error || !Array.isArray(digest.result?.mismatched)) {
+          continue;
+        }
+        summary.peers++;
+        summary.mismatched += digest.result.mismatched.length;
+
+        const missing = this.gossipService.filterUnseen(
+          digest.result.mismatched.flatMap((bucket: any) => Array.isArray(bucket?.ids) ? bucket.ids : [])
+        ).slice(0, GossipService.MAX_BACKFILL);
+        if (!missing.length) {
+          continue;
+        }
+
+        const backfill = await this.mcpClient.callRemoteNode(friend.friend_domain, 'botnet.gossip.backfill', {
+          messageIds: missing,
+          source_bot_id: botDomain
+        });
+        if (backfill.error || !Array.isArray(backfill.result?.messages)) {
+          continue;
+        }
+        const result = await this.gossipService.handleExchange({
+          messages: backfill.result.messages,
+          source_bot_id: friend.friend_domain
+        });
+        summary.backfilled += result.received || 0;
+        this.resolveMissingQuotes(backfill.result.messages);
+      } catch (error) {
+        this.errorReporter.report(error, { source: 'job:anti-entropy', friendDomain: friend.friend_domain });
+        this.options.logger.warn(`❌ Anti-entropy round failed with ${friend.friend_domain}`, {
+          error: error instanceof Error ? error.message : String(error)
+        });
+      }
+    }
+
+    if (summary.backfilled) {
+      this.options.logger.info(`🔁 Anti-entropy backfilled ${summary.backfilled} gossip(s)`, summary);
+    }
+    return summary;
+  }
+
+  /**
+   * Answer a friend's botnet.gossip.digest with our buckets and the IDs in buckets that differ
+   */
+  compareGossipDigest(buckets: any[]): { buckets: GossipDigestBucket[]; mismatched: Array<{ hour: string; ids: string[] }> } {
+    return this.gossipService.compareDigest(
+      buckets.filter(bucket => typeof bucket?.hour === 'string' && typeof bucket?.hash === 'string')
+    );
+  }
+
+  /**
+   * Messages a friend asked for with botnet.gossip.backfill
+   */
+  getGossipBackfill(messageIds: string[]): any[] {
+    return this.gossipService.getBackfill(messageIds.filter(messageId => typeof messageId === 'string'));
+  }
+
+  /**
+   * Retry queued federation calls whose backoff has elapsed
+   */
+  async processOutbox(): Promise<{ attempted: number; delivered: number }> {
+    if (this.loadMonitor.shouldShed() || !this.storageMonitor.isHealthy()) {
+      return { attempted: 0, delivered: 0 };
+    }
+
+    let delivered = 0;
+    const botDomain = this.options.config.botDomain;
+    const entries = this.federationOutbox.due();
+    for (const entry of entries) {
+      try {
+        // Friends take our calls on a session, so hold a valid one before retrying
+        if (await this.friendshipService.getFriendshipStatus(botDomain, entry.target_domain) === 'active'
+          && !await this.ensureFriendSession(entry.target_domain)) {
+          this.federationOutbox.markFailed(entry, `No session with ${entry.target_domain}`);
+          continue;
+        }
+
+        const response = await this.mcpClient.callRemoteNode(entry.target_domain, entry.method, entry.params);
+        if (FederationOutbox.isSessionRejected(response)) {
+          this.mcpClient.dropSession(entry.target_domain);
+          this.federationOutbox.markFailed(entry, response.error!.message);
+          continue;
+        }
+        if (FederationOutbox.isRetryable(response)) {
+          this.federationOutbox.markFailed(entry, response.error!.message);
+          continue;
+        }
+
+        // Delivered - a JSON-RPC error here means the peer refused it, which retrying won't fix
+        this.federationOutbox.markDelivered(entry.id);
+        if (response.error) {
+          this.options.logger.warn(`📮 ${entry.method} → ${entry.target_domain} rejected by peer, dropping`, response.error);
+          continue;
+        }
+        delivered++;
+        if (entry.method === 'botnet.gossip.exchange' && response.result?.success && response.result.messages) {
+          await this.gossipService.handleExchange({
+            messages: response.result.messages,
+            source_bot_id: entry.target_domain
+          });
+          this.resolveMissingQuotes(response.result.messages);
+        }
+      } catch (error) {
+        this.errorReporter.report(error, { source: 'job:federation-outbox', friendDomain: entry.target_domain });
+        this.federationOutbox.markFailed(entry, error instanceof Error ? error.message : String(error));
+      }
+    }
+
+    if (entries.length) {
+      this.options.logger.info(`📮 Outbox retry: ${delivered}/${entries.length} delivered`);
+    }
+    return { attempted: entries.length, delivered };
+  }
+
+  /**
+   * Post to a channel: local channels store and push to federated members, remote ones go through their host
+   */
+  async postToChannel(host: string, name: string, content: string): Promise<ChannelMessage> {
+    const { botDomain } = this.options.config;
+    if (host === botDomain) {
+      const message = this.channelService.post(name, botDomain, content);
+      this.fanOutChannelMessage(message);
+      return message;
+    }
+
+    const response = await this.mcpClient.callRemoteNode(host, 'botnet.channel.post', {
+      channel: ChannelService.normalizeName(name),
+      content,
+      source_bot_id: botDomain
+    });
+    if (response.error) {
+      throw new Error(response.error.message);
+    }
+    // The host doesn't echo our own post back to us - keep a copy in the local feed
+    return this.channelService.receive(host, response.result.message) || response.result.message;
+  }
+
+  /**
+   * Join a channel hosted by another node and start receiving its messages
+   */
+  async joinRemoteChannel(host: string, name: string): Promise<Channel> {
+    const response = await this.mcpClient.callRemoteNode(host, 'botnet.channel.join', {
+      channel: ChannelService.normalizeName(name),
+      source_bot_id: this.options.config.botDomain
+    });
+    if (response.error) {
+      throw new Error(response.error.message);
+    }
+
+    const channel = this.channelService.follow(host, response.result.channel);
+    for (const message of (response.result.recent || []).slice().reverse()) {
+      this.channelService.receive(host, message);
+    }
+    return channel;
+  }
+
+  /**
+   * Leave a remote channel (best effort towards the host) and drop our local copy of its feed
+   */
+  async leaveRemoteChannel(host: string, name: string): Promise<boolean> {
+    const response = await this.mcpClient.callRemoteNode(host, 'botnet.channel.leave', {
+      channel: ChannelService.normalizeName(name),
+      source_bot_id: this.options.config.botDomain
+    });
+    if (response.error) {
+      this.options.logger.warn(`Host ${host} did not confirm leaving #${name}`, response.error);
+    }
+    return this.channelService.delete(host, name);
+  }
+
+  /**
+   * Inbound botnet.channel.join from a federated peer
+   */
+  acceptChannelJoin(domain: string, name: string): { channel: Channel; recent: ChannelMessage[] } {
+    if (this.blockListService.isBlocked(domain)) {
+      throw new Error(`#${ChannelService.normalizeName(name)} is not available`);
+    }
+    const channel = this.channelService.addMember(name, domain);
+    this.options.logger.info(`📢 ${domain} joined #${channel.name}`);
+    return { channel, recent: this.channelService.getFeed(this.options.config.botDomain, channel.name, 20) };
+  }
+
+  /**
+   * Inbound botnet.channel.post from a member of one of our channels
+   */
+  acceptChannelPost(domain: string, name: string, content: string): ChannelMessage {
+    if (this.blockListService.isBlocked(domain)) {
+      throw new Error(`Join #${ChannelService.normalizeName(name)} before posting`);
+    }
+    if (!this.rateLimiter.checkRateLimit(`channel:${domain}`, 'channelPost')) {
+      throw new Error('Rate limit exceeded for channel posts');
+    }
+    const message = this.channelService.post(name, domain, content);
+    this.fanOutChannelMessage(message, domain);
+    return message;
+  }
+
+  /**
+   * Moderate a channel: directly for channels we host, through the host where we are a moderator
+   */
+  async moderateChannel(host: string, name: string, action: ModerationAction, target: string, reason?: string): Promise<ModerationEntry> {
+    const { botDomain } = this.options.config;
+    if (host === botDomain) {
+      return this.acceptChannelModeration(botDomain, name, action, target, reason);
+    }
+
+    const response = await this.mcpClient.callRemoteNode(host, 'botnet.channel.moderate', {
+      channel: ChannelService.normalizeName(name),
+      action,
+      target,
+      reason,
+      source_bot_id: botDomain
+    });
+    if (response.error) {
+      throw new Error(response.error.message);
+    }
+    return response.result.entry;
+  }
+
+  /**
+   * Apply a moderator action in one of our channels and push it to every member node
+   */
+  acceptChannelModeration(actor: string, name: string, action: ModerationAction, target: string, reason?: string): ModerationEntry {
+    const entry = this.channelService.moderate(name, actor, action, target, reason);
+    this.auditService.record('channel.moderation', {
+      actor,
+      target: `#${entry.channel}`,
+      details: { action, target, reason }
+    });
+    this.pushToChannelMembers(entry.channel, 'botnet.channel.moderation', { channel: entry.channel, entry });
+    return entry;
+  }
+
+  /**
+   * Inbound botnet.channel.moderation pushed (and signed) by the host of a channel we follow
+   */
+  receiveChannelModeration(host: string, entry: any): ModerationEntry | null {
+    if (!entry || typeof entry.action !== 'string' || typeof entry.target !== 'string') {
+      throw new Error('entry with action and target is required');
+    }
+    return this.channelService.receiveModeration(host, entry);
+  }
+
+  /**
+   * Inbound botnet.channel.message pushed by the host of a channel we follow
+   */
+  receiveChannelMessage(host: string, message: any): ChannelMessage | null {
+    if (!message || typeof message.content !== 'string' || typeof message.channel !== 'string') {
+      throw new Error('message with channel and content is required');
+    }
+    return this.channelService.receive(host, message);
+  }
+
+  /**
+   * Push a message in one of our channels to its federated members
+   */
+  private fanOutChannelMessage(message: ChannelMessage, exclude?: string): void {
+    this.pushToChannelMembers(message.channel, 'botnet.channel.message', { channel: message.channel, message }, exclude);
+  }
+
+  /**
+   * Deliver a channel update to its federated members in the background (failed deliveries go to the outbox)
+   */
+  private pushToChannelMembers(channel: string, method: string, payload: Record<string, any>, exclude?: string): void {
+    const subscribers = this.channelService.getSubscribers(channel, exclude);
+    if (!subscribers.length) {
+      return;
+    }
+
+    setImmediate(async () => {
+      const params = { ...payload, source_bot_id: this.options.config.botDomain };
+      for (const domain of this.mcpClient.sortByRtt(subscribers, subscriber => subscriber)) {
+        try {
+          const response = await this.mcpClient.callRemoteNode(domain, method, params);
+          if (FederationOutbox.isRetryable(response)) {
+            this.federationOutbox.enqueue(domain, method, params, response.error!.message);
+          }
+        } catch (error) {
+          this.errorReporter.report(error, { source: 'job:channel-fanout', friendDomain: domain });
+        }
+      }
+    });
+  }
+
   /**
    * Review gossips and get combined gossip text (LLM-optimized default limit)
    */
@@ -557,6 +1485,262 @@ export class BotNetService {
     return this.rateLimiter.checkRateLimit(identifier, operation);
   }
 
+  /**
+   * Get audit service (for HTTP server and internal tools)
+   */
+  getAuditService(): AuditService {
+    return this.auditService;
+  }
+
+  /**
+   * Get anomaly detector (fed by the HTTP server for every MCP request)
+   */
+  getAnomalyDetector(): AnomalyDetector {
+    return this.anomalyDetector;
+  }
+
+  /**
+   * Accept an abuse report from a peer node or human reporter
+   */
+  submitAbuseReport(report: { reportedDomain: string; reason: string; details?: string; reporter?: string }, clientIP?: string): AbuseReport {
+    const clientKey = `abuse:${clientIP || 'unknown'}`;
+    if (!this.rateLimiter.checkRateLimit(clientKey, 'abuseReport')) {
+      throw new Error('Rate limit exceeded for abuse reports');
+    }
+
+    const saved = this.abuseReportService.submit({ ...report, reporterIP: clientIP });
+    this.auditService.record('abuse.reported', {
+      actor: report.reporter || clientIP || 'unknown',
+      target: saved.reported_domain,
+      details: { reportId: saved.id, reason: saved.reason }
+    });
+    return saved;
+  }
+
+  /**
+   * Close an abuse report, optionally penalizing the reported friend's trust score
+   */
+  closeAbuseReport(reportId: string, status: 'resolved' | 'dismissed', note?: string, penalty?: number): AbuseReport | null {
+    const report = this.abuseReportService.close(reportId, status, note);
+    if (report && status === 'resolved' && penalty) {
+      this.reputationService.adjust(report.reported_domain, -Math.abs(Math.round(penalty)), 'abuse_report', note || report.reason);
+    }
+    return report;
+  }
+
+  /**
+   * Ask a peer node for its operator contact (from its botnet.profile)
+   */
+  async getPeerOperatorContact(domain: string): Promise<{ contact?: string; abuseReports?: string } | null> {
+    const response = await this.mcpClient.callRemoteNode(domain, 'botnet.profile', {});
+    if (response.error) {
+      throw new Error(response.error.message);
+    }
+    return response.result?.operator || null;
+  }
+
+  /**
+   * Verify an inbound request's node signature, fetching the sender's key from its botnet.profile
+   */
+  async verifyFederationSignature(headers: Record<string, string | string[] | undefined>, body: string): Promise<SignatureVerification> {
+    return this.nodeIdentity.verifyRequest(headers, body, domain => this.fetchPublishedNodeKey(domain));
+  }
+
+  /**
+   * Write the node key, domain and pinned peer keys to a passphrase-encrypted backup file
+   */
+  backupNodeIdentity(path: string, passphrase: string): { path: string; publicKey: string; dnsRecord: { name: string; value: string } } {
+    mkdirSync(dirname(path), { recursive: true });
+    writeFileSync(path, this.nodeIdentity.exportBundle(passphrase), { mode: 0o600 });
+    this.auditService.record('admin.action', {
+      actor: 'local',
+      details: { action: 'backup_node_identity', path }
+    });
+    return { path, publicKey: this.nodeIdentity.getPublicKey(), dnsRecord: this.nodeIdentity.getKeyRecord() };
+  }
+
+  /**
+   * Restore a node identity backup on this host. The key must match one published for our domain in DNS
+   * (_botnet-key TXT record) - not botnet.profile, which already resolves to this new host - unless forced
+   */
+  async restoreNodeIdentity(path: string, passphrase: string, force: boolean = false): Promise<{ publicKey: string; published: string[]; verified: boolean }> {
+    const bundle = NodeIdentity.openBundle(readFileSync(path, 'utf8'), passphrase);
+    const recordName = `${KEY_RECORD_PREFIX}.${this.options.config.botDomain}`;
+
+    let published: string[] = [];
+    try {
+      published = NodeIdentity.parseKeyRecords(await resolveTxt(recordName));
+    } catch (error) {
+      this.options.logger.warn('Could not look up our node key record', {
+        recordName,
+        error: error instanceof Error ? error.message : String(error)
+      });
+    }
+    const verified = published.includes(bundle.publicKey);
+    if (!verified && !force) {
+      throw new Error(published.length
+        ? `Backup key does not match the key published in ${recordName}`
+        : `No node key published in ${recordName}; pass force to restore anyway`);
+    }
+
+    this.nodeIdentity.restoreBundle(bundle);
+    this.auditService.record('admin.action', {
+      actor: 'local',
+      outcome: verified ? 'success' : 'failure',
+      details: { action: 'restore_node_identity', path, verified, forced: !verified }
+    });
+    return { publicKey: bundle.publicKey, published, verified };
+  }
+
+  private async fetchPublishedNodeKey(domain: string): Promise<string | undefined> {
+    if (!domain.startsWith('botnet.')) {
+      return undefined;
+    }
+    const response = await this.keyLookupClient.callRemoteNode(domain, 'botnet.profile', {});
+    if (response.error) {
+      throw new Error(response.error.message);
+    }
+    return response.result?.nodeKey?.type === 'ed25519' ? response.result.nodeKey.publicKey : undefined;
+  }
+
+  /**
+   * Get abuse report service
+   */
+  getAbuseReportService(): AbuseReportService {
+    return this.abuseReportService;
+  }
+
+  /**
+   * Get reputation service (activity credits, maintenance job, history)
+   */
+  getReputationService(): ReputationService {
+    return this.reputationService;
+  }
+
+  /**
+   * Get friend list service (curated domain groups and list timelines)
+   */
+  getFriendListService(): FriendListService {
+    return this.friendListService;
+  }
+
+  /**
+   * Get block list service (agent-level blocks and mutes)
+   */
+  getBlockListService(): BlockListService {
+    return this.blockListService;
+  }
+
+  /**
+   * Get error reporter (handler and background-task errors to the configured sink)
+   */
+  getErrorReporter(): ErrorReporter {
+    return this.errorReporter;
+  }
+
+  /**
+   * Get per-peer bandwidth meter
+   */
+  getBandwidthMeter(): BandwidthMeter {
+    return this.bandwidthMeter;
+  }
+
+  /**
+   * Get local usage analytics (aggregates only)
+   */
+  getUsageAnalytics(): UsageAnalytics {
+    return this.usageAnalytics;
+  }
+
+  /**
+   * Get feature flags (runtime overrides over config defaults)
+   */
+  getFeatureFlags(): FeatureFlags {
+    return this.featureFlags;
+  }
+
+  /**
+   * Get channel service (channels we host and remote channels we follow)
+   */
+  getChannelService(): ChannelService {
+    return this.channelService;
+  }
+
+  /**
+   * Get federation outbox (undelivered federation calls awaiting retry)
+   */
+  getFederationOutbox(): FederationOutbox {
+    return this.federationOutbox;
+  }
+
+  /**
+   * Get provider health (external webhook sinks and their circuit state)
+   */
+  getProviderHealth(): ProviderHealth {
+    return this.providerHealth;
+  }
+
+  /**
+   * Get storage monitor (database writability and the inbound outage buffer)
+   */
+  getStorageMonitor(): StorageMonitor {
+    return this.storageMonitor;
+  }
+
+  /**
+   * Get load monitor (heap / event loop pressure for load shedding)
+   */
+  getLoadMonitor(): LoadMonitor {
+    return this.loadMonitor;
+  }
+
+  /**
+   * Get federation traffic recorder (undefined unless federationRecordPath is set)
+   */
+  getTrafficRecorder(): TrafficRecorder | undefined {
+    return this.trafficRecorder;
+  }
+
+  /**
+   * Get per-agent request capture (debugging a misbehaving agent, with its operator's consent)
+   */
+  getAgentCapture(): AgentCapture {
+    return this.agentCapture;
+  }
+
+  /**
+   * Start capturing one agent's requests. Only with the consent of the agent's operator - recorded in the audit log
+   */
+  startAgentCapture(agentId: string, minutes: number, reason: string): CaptureStatus {
+    const status = this.agentCapture.start(agentId, minutes, reason);
+    this.auditService.record('admin.action', {
+      actor: 'local',
+      target: agentId,
+      details: { action: 'start_agent_capture', reason, expiresAt: status.expiresAt, consented: true }
+    });
+    return status;
+  }
+
+  /**
+   * Write an agent's captured requests as a .har file (default: captures/ next to the database)
+   */
+  exportAgentCapture(agentId: string, path?: string): { path: string; entries: number } | null {
+    const bundle = this.agentCapture.export(agentId);
+    if (!bundle) {
+      return null;
+    }
+    const stamp = new Date().toISOString().replace(/[:.]/g, '-');
+    const target = path || join(dirname(this.options.config.databasePath), 'captures', `${agentId.replace(/[^a-zA-Z0-9.-]/g, '_')}-${stamp}.har`);
+    mkdirSync(dirname(target), { recursive: true });
+    writeFileSync(target, JSON.stringify(bundle, null, 2));
+    this.auditService.record('admin.action', {
+      actor: 'local',
+      target: agentId,
+      details: { action: 'export_agent_capture', path: target, entries: bundle.log.entries.length }
+    });
+    return { path: target, entries: bundle.log.entries.length };
+  }
+
   /**
    * Three-Tier Authentication: Get authentication middleware
    */
@@ -600,6 +1784,7 @@ export class BotNetService {
         sessionTokenPrefix: sessionToken.substring(0, 12) + '...',
         expiresAt 
       });
+      this.auditService.record('session.login', { actor: fromDomain, details: { expiresAt: expiresAt.toISOString() } });
       
       return { sessionToken, expiresAt };
       
@@ -608,6 +1793,11 @@ export class BotNetService {
         fromDomain, 
         error: error instanceof Error ? error.message : String(error) 
       });
+      this.auditService.record('session.login_failed', {
+        actor: fromDomain,
+        outcome: 'failure',
+        details: { error: error instanceof Error ? error.message : String(error) }
+      });
       throw error;
     }
   }
@@ -616,5 +1806,8 @@ export class BotNetService {
     this.options.logger.info("Shutting down BotNet service");
     // Cleanup expired tokens
     await this.tokenService.cleanupExpiredTokens();
+    this.anomalyDetector.cleanup();
+    this.loadMonitor.stop();
+    this.storageMonitor.stop();
   }
 }
\ No newline at end of file